@@ -0,0 +1,38 @@
+// Comando mockidp sobe o provedor OpenID Connect de teste para exercitar o
+// login único localmente:
+//
+//	go run ./cmd/mockidp -addr :9000
+//
+// e, no backend, OIDC_ISSUER=http://localhost:9000 com qualquer
+// OIDC_CLIENT_ID.
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"adamanagement/backend/internal/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "endereço de escuta")
+	issuer := flag.String("issuer", "http://localhost:9000", "URL pública do provedor (issuer)")
+	flag.Parse()
+
+	provider, err := oidctest.New()
+	if err != nil {
+		slog.Error("erro fatal", "error", err)
+		os.Exit(1)
+	}
+	provider.Issuer = *issuer
+
+	slog.Info("provedor OIDC de teste no ar", "issuer", *issuer)
+	srv := &http.Server{Addr: *addr, Handler: provider, ReadHeaderTimeout: 5 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		slog.Error("erro fatal", "error", err)
+		os.Exit(1)
+	}
+}
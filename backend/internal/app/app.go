@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -50,10 +51,45 @@ func Run() error {
 		&models.Discipline{},
 		&models.StudyPlan{},
 		&models.PlanRound{},
+		&models.OutboxAttachment{},
+		&models.OutboxEmail{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Notification{},
+		&models.AdvisorAssignment{},
+		&models.RefreshToken{},
+		&models.AuditLog{},
+		&models.PasswordResetCode{},
+		&models.LoginThrottle{},
+		&models.RecoveryCode{},
+		&models.TwoFactorChallenge{},
+		&models.Setting{},
+		&models.OIDCLoginState{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.APIKey{},
+		&models.UserInvitation{},
+		&models.TriageRule{},
+		&models.ReportView{},
+		&models.ReportSchedule{},
+		&models.ReportScheduleRun{},
 	); err != nil {
 		return fmt.Errorf("migração do banco: %w", err)
 	}
 
+	searchSvc := services.NewSearchService(db)
+	if err := searchSvc.Backfill(); err != nil {
+		return fmt.Errorf("normalização dos nomes para busca: %w", err)
+	}
+	if err := searchSvc.EnsureTrigramIndexes(); err != nil {
+		slog.Warn("busca sem índices trigram: pg_trgm indisponível", "error", err)
+	}
+
+	roleSvc := services.NewRoleService(db)
+	if err := roleSvc.EnsureDefaults(); err != nil {
+		return fmt.Errorf("seed dos papéis: %w", err)
+	}
+
 	authSvc := services.NewAuthService(db, cfg.JWTSecret)
 	created, err := authSvc.EnsureAdmin(cfg.AdminName, cfg.AdminEmail, cfg.AdminPassword)
 	if err != nil {
@@ -72,33 +108,74 @@ func Run() error {
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length", "X-Total-Count"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Unread-Count", "Retry-After", "Content-Disposition", "X-Next-Cursor"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	r.GET("/health", healthHandler(db))
-	routes.Register(r, buildHandlers(db, authSvc, cfg.JWTSecret), cfg.JWTSecret)
+	sessionSvc := services.NewSessionService(db, cfg.JWTSecret)
+	loginGuard := services.NewLoginGuard(db)
+	routes.Register(r, buildHandlers(db, cfg, authSvc, sessionSvc, loginGuard, roleSvc), cfg.JWTSecret, routes.Guards{
+		Sessions:    sessionSvc,
+		Attempts:    loginGuard,
+		Scopes:      services.NewScopeService(db, roleSvc),
+		Permissions: roleSvc,
+		APIKeys:     services.NewAPIKeyService(db),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	return serve(r, cfg.Port)
+	startWorkers(ctx, db, cfg, roleSvc)
+	return serve(ctx, r, cfg.Port)
 }
 
-func buildHandlers(db *gorm.DB, authSvc *services.AuthService, jwtSecret string) routes.Handlers {
-	studentAuthSvc := services.NewStudentAuthService(db, jwtSecret)
+func buildHandlers(db *gorm.DB, cfg *config.Config, authSvc *services.AuthService, sessionSvc *services.SessionService,
+	loginGuard *services.LoginGuard, roleSvc *services.RoleService) routes.Handlers {
+	authSvc.SetPasswordLogin(cfg.PasswordLogin)
+	studentAuthSvc := services.NewStudentAuthService(db, cfg.JWTSecret)
+	studentAuthSvc.SetPasswordLogin(cfg.PasswordLogin)
+	oidcSvc := services.NewOIDCService(db, cfg.JWTSecret, services.OIDCConfig{
+		Issuer:            cfg.OIDCIssuer,
+		ClientID:          cfg.OIDCClientID,
+		ClientSecret:      cfg.OIDCClientSecret,
+		RedirectURL:       cfg.OIDCRedirectURL,
+		RegistrationClaim: cfg.OIDCRegistrationClaim,
+		Scopes:            cfg.OIDCScopes,
+	})
 	roundSvc := services.NewPlanRoundService(db)
+	notificationSvc := services.NewNotificationService(db)
 
 	return routes.Handlers{
-		Auth:        controllers.NewAuthHandler(authSvc, studentAuthSvc),
-		StudentAuth: controllers.NewStudentAuthHandler(studentAuthSvc),
-		Users:       controllers.NewUserHandler(services.NewUserService(db)),
-		Import:      controllers.NewImportHandler(services.NewImportService(db)),
-		Reports:     controllers.NewReportHandler(services.NewReportService(db)),
-		Indicators:  controllers.NewIndicatorsHandler(services.NewIndicatorsService(db)),
-		Students:    controllers.NewStudentHandler(services.NewStudentService(db)),
-		Actions:     controllers.NewActionHandler(services.NewActionService(db)),
-		Disciplines: controllers.NewDisciplineHandler(services.NewDisciplineService(db)),
-		Plans:       controllers.NewStudyPlanHandler(services.NewStudyPlanService(db, roundSvc)),
-		Rounds:      controllers.NewPlanRoundHandler(roundSvc),
+		Auth:            controllers.NewAuthHandler(authSvc, studentAuthSvc, notificationSvc),
+		Sessions:        controllers.NewSessionHandler(sessionSvc),
+		StudentAuth:     controllers.NewStudentAuthHandler(studentAuthSvc),
+		Users:           controllers.NewUserHandler(services.NewUserService(db), services.NewScopeService(db, roleSvc)),
+		Import:          controllers.NewImportHandler(services.NewImportService(db)),
+		Reports:         controllers.NewReportHandler(services.NewReportService(db)),
+		Indicators:      controllers.NewIndicatorsHandler(services.NewIndicatorsService(db)),
+		Students:        controllers.NewStudentHandler(services.NewStudentService(db)),
+		Actions:         controllers.NewActionHandler(services.NewActionService(db)),
+		Disciplines:     controllers.NewDisciplineHandler(services.NewDisciplineService(db)),
+		Plans:           controllers.NewStudyPlanHandler(services.NewStudyPlanService(db, roundSvc)),
+		Rounds:          controllers.NewPlanRoundHandler(roundSvc),
+		Webhooks:        controllers.NewWebhookHandler(services.NewWebhookService(db)),
+		Notifications:   controllers.NewNotificationHandler(notificationSvc, sessionSvc),
+		Advisors:        controllers.NewAdvisorHandler(services.NewAdvisorService(db, roleSvc)),
+		Audit:           controllers.NewAuditHandler(services.NewAuditService(db)),
+		LoginLocks:      controllers.NewLoginLockHandler(loginGuard),
+		TwoFactor:       controllers.NewTwoFactorHandler(services.NewTwoFactorService(db)),
+		OIDC:            controllers.NewOIDCHandler(oidcSvc, cfg.PasswordLogin),
+		Roles:           controllers.NewRoleHandler(roleSvc),
+		APIKeys:         controllers.NewAPIKeyHandler(services.NewAPIKeyService(db)),
+		Invitations:     controllers.NewInvitationHandler(services.NewInvitationService(db, cfg.AppURL)),
+		TriageRules:     controllers.NewTriageRuleHandler(services.NewTriageRuleService(db)),
+		Risk:            controllers.NewRiskHandler(services.NewRiskService(db)),
+		Courses:         controllers.NewCourseHandler(services.NewCourseService(db)),
+		ReportViews:     controllers.NewReportViewHandler(services.NewReportViewService(db)),
+		ReportSchedules: controllers.NewReportScheduleHandler(services.NewReportScheduleService(db, roleSvc)),
+		Search:          controllers.NewSearchHandler(services.NewSearchService(db)),
 	}
 }
 
@@ -120,13 +197,16 @@ func healthHandler(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// serve inicia o servidor HTTP com desligamento gracioso: em SIGINT ou
-// SIGTERM, as conexões em andamento têm até shutdownTimeout para concluir.
-func serve(handler http.Handler, port string) error {
-	srv := &http.Server{Addr: ":" + port, Handler: handler}
-
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+// serve inicia o servidor HTTP com desligamento gracioso: quando ctx é
+// cancelado (SIGINT ou SIGTERM), as conexões em andamento têm até
+// shutdownTimeout para concluir. ctx é também o contexto-base das
+// requisições, encerrando de imediato as conexões longas (streams SSE).
+func serve(ctx context.Context, handler http.Handler, port string) error {
+	srv := &http.Server{
+		Addr:        ":" + port,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/config"
+	"adamanagement/backend/internal/mail"
+	"adamanagement/backend/internal/services"
+)
+
+// Intervalos das tarefas em segundo plano.
+const (
+	outboxInterval    = 30 * time.Second
+	remindersInterval = 10 * time.Minute
+	purgeInterval     = time.Hour
+	scheduleInterval  = time.Minute
+)
+
+// startWorkers dispara as tarefas periódicas do processo. Todas param
+// quando ctx é cancelado (desligamento do servidor).
+func startWorkers(ctx context.Context, db *gorm.DB, cfg *config.Config, roleSvc *services.RoleService) {
+	var sender mail.Sender = mail.LogSender{}
+	if cfg.SMTPHost != "" {
+		sender = mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	} else {
+		slog.Warn("SMTP_HOST não definida: e-mails serão apenas registrados no log")
+	}
+
+	outbox := services.NewOutboxDispatcher(db, sender)
+	go runEvery(ctx, "outbox de e-mails", outboxInterval, func(ctx context.Context) error {
+		_, err := outbox.Dispatch(ctx)
+		return err
+	})
+
+	webhooks := services.NewWebhookDispatcher(db)
+	go runEvery(ctx, "entregas de webhook", outboxInterval, func(ctx context.Context) error {
+		_, err := webhooks.Dispatch(ctx)
+		return err
+	})
+
+	rounds := services.NewPlanRoundService(db)
+	go runEvery(ctx, "lembretes de prazo de rodada", remindersInterval, func(context.Context) error {
+		_, err := rounds.EnqueueClosingReminders(time.Now())
+		return err
+	})
+
+	schedules := services.NewReportScheduleService(db, roleSvc)
+	go runEvery(ctx, "entregas agendadas de relatórios", scheduleInterval, func(ctx context.Context) error {
+		_, err := schedules.RunDue(ctx, time.Now())
+		return err
+	})
+
+	guard := services.NewLoginGuard(db)
+	go runEvery(ctx, "limpeza de contadores, states e desafios de login", purgeInterval, func(context.Context) error {
+		now := time.Now()
+		if _, err := guard.Purge(now); err != nil {
+			return err
+		}
+		if _, err := services.PurgeTwoFactorChallenges(db, now); err != nil {
+			return err
+		}
+		_, err := services.PurgeOIDCStates(db, now)
+		return err
+	})
+}
+
+// runEvery executa fn a cada intervalo até ctx ser cancelado. Erros são
+// registrados e a tarefa segue no próximo ciclo.
+func runEvery(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx); err != nil {
+			slog.Error("falha em tarefa periódica", "task", name, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -19,6 +21,28 @@ type Config struct {
 	Port           string
 	AppEnv         string
 	AllowedOrigins []string
+	// AppURL é o endereço do frontend usado nos links enviados por e-mail
+	// (ativação de convite). Padrão: a primeira origem de AllowedOrigins.
+	AppURL string
+
+	// SMTP de saída das notificações por e-mail. Sem SMTPHost, as
+	// mensagens da outbox são apenas registradas no log.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Login único (OpenID Connect) da universidade. Sem OIDCIssuer o SSO
+	// fica desligado. PasswordLogin mantém o login local por senha como
+	// alternativa; desligá-lo exige o SSO configurado.
+	OIDCIssuer            string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string
+	OIDCRegistrationClaim string
+	OIDCScopes            []string
+	PasswordLogin         bool
 }
 
 var defaultOrigins = []string{
@@ -37,7 +61,10 @@ func Load() (*Config, error) {
 	for _, key := range []string{
 		"DATABASE_URL", "JWT_SECRET", "PORT",
 		"ADMIN_EMAIL", "ADMIN_PASSWORD", "ADMIN_NAME",
-		"APP_ENV", "ALLOWED_ORIGINS",
+		"APP_ENV", "ALLOWED_ORIGINS", "APP_URL",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM",
+		"OIDC_ISSUER", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_REDIRECT_URL",
+		"OIDC_REGISTRATION_CLAIM", "OIDC_SCOPES", "PASSWORD_LOGIN",
 	} {
 		_ = v.BindEnv(key)
 	}
@@ -58,6 +85,16 @@ func Load() (*Config, error) {
 		AdminName:     v.GetString("ADMIN_NAME"),
 		Port:          v.GetString("PORT"),
 		AppEnv:        v.GetString("APP_ENV"),
+		SMTPHost:      v.GetString("SMTP_HOST"),
+		SMTPUsername:  v.GetString("SMTP_USERNAME"),
+		SMTPPassword:  v.GetString("SMTP_PASSWORD"),
+		SMTPFrom:      v.GetString("SMTP_FROM"),
+
+		OIDCIssuer:            strings.TrimSuffix(v.GetString("OIDC_ISSUER"), "/"),
+		OIDCClientID:          v.GetString("OIDC_CLIENT_ID"),
+		OIDCClientSecret:      v.GetString("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:       v.GetString("OIDC_REDIRECT_URL"),
+		OIDCRegistrationClaim: v.GetString("OIDC_REGISTRATION_CLAIM"),
 	}
 
 	if cfg.Port == "" {
@@ -67,6 +104,40 @@ func Load() (*Config, error) {
 		cfg.AppEnv = "development"
 	}
 
+	cfg.SMTPPort = 587
+	if raw := v.GetString("SMTP_PORT"); raw != "" {
+		port, err := strconv.Atoi(raw)
+		if err != nil || port <= 0 {
+			return nil, fmt.Errorf("SMTP_PORT inválida: %q", raw)
+		}
+		cfg.SMTPPort = port
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom == "" {
+		return nil, errors.New("SMTP_FROM é obrigatória quando SMTP_HOST está definida")
+	}
+
+	cfg.PasswordLogin = true
+	if raw := v.GetString("PASSWORD_LOGIN"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("PASSWORD_LOGIN inválida: %q", raw)
+		}
+		cfg.PasswordLogin = enabled
+	}
+	if cfg.OIDCRegistrationClaim == "" {
+		cfg.OIDCRegistrationClaim = "registration"
+	}
+	cfg.OIDCScopes = strings.Fields(v.GetString("OIDC_SCOPES"))
+	if len(cfg.OIDCScopes) == 0 {
+		cfg.OIDCScopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.OIDCIssuer != "" && (cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "") {
+		return nil, errors.New("OIDC_CLIENT_ID e OIDC_REDIRECT_URL são obrigatórias quando OIDC_ISSUER está definida")
+	}
+	if !cfg.PasswordLogin && cfg.OIDCIssuer == "" {
+		return nil, errors.New("PASSWORD_LOGIN=false exige o SSO configurado (OIDC_ISSUER)")
+	}
+
 	if raw := v.GetString("ALLOWED_ORIGINS"); raw != "" {
 		for _, origin := range strings.Split(raw, ",") {
 			if o := strings.TrimSpace(origin); o != "" {
@@ -76,6 +147,10 @@ func Load() (*Config, error) {
 	} else {
 		cfg.AllowedOrigins = defaultOrigins
 	}
+	cfg.AppURL = strings.TrimSuffix(v.GetString("APP_URL"), "/")
+	if cfg.AppURL == "" {
+		cfg.AppURL = cfg.AllowedOrigins[0]
+	}
 
 	var missing []string
 	for key, value := range map[string]string{
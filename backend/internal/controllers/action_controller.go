@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
 	"adamanagement/backend/internal/services"
 )
 
@@ -72,7 +73,7 @@ func (h *ActionHandler) Update(c *gin.Context) {
 		ActionDate:   in.ActionDate,
 		Description:  in.Description,
 		ResponseDate: in.ResponseDate,
-	})
+	}, middlewares.CourseScope(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -86,7 +87,7 @@ func (h *ActionHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.Delete(id); err != nil {
+	if err := h.svc.Delete(id, middlewares.CourseScope(c)); err != nil {
 		respondError(c, err)
 		return
 	}
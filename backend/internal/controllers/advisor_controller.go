@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+type AdvisorHandler struct {
+	svc *services.AdvisorService
+}
+
+func NewAdvisorHandler(svc *services.AdvisorService) *AdvisorHandler {
+	return &AdvisorHandler{svc: svc}
+}
+
+// History devolve as atribuições de orientador do aluno em todos os
+// semestres, incluindo as encerradas.
+func (h *AdvisorHandler) History(c *gin.Context) {
+	items, err := h.svc.History(c.Param("registration"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAdvisorAssignments(items))
+}
+
+type advisorAssignInput struct {
+	SemesterID uint `json:"semester_id" binding:"required"`
+	AdvisorID  uint `json:"advisor_id" binding:"required"`
+}
+
+func (h *AdvisorHandler) Assign(c *gin.Context) {
+	var in advisorAssignInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	userID, _ := middlewares.UserID(c)
+	assignment, err := h.svc.Assign(c.Param("registration"), in.SemesterID, in.AdvisorID, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAdvisorAssignment(*assignment))
+}
+
+func (h *AdvisorHandler) Unassign(c *gin.Context) {
+	semesterID, err := queryUintRequired(c, "semester_id")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := h.svc.Unassign(c.Param("registration"), semesterID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Orientador removido"})
+}
+
+type advisorBulkInput struct {
+	AdvisorID      uint     `json:"advisor_id" binding:"required"`
+	SemesterID     uint     `json:"semester_id"`
+	CourseID       uint     `json:"course_id"`
+	RoundID        uint     `json:"round_id"`
+	Statuses       []string `json:"statuses"`
+	OnlyUnassigned bool     `json:"only_unassigned"`
+}
+
+// Bulk atribui o orientador a um curso inteiro ou ao grupo de uma rodada.
+func (h *AdvisorHandler) Bulk(c *gin.Context) {
+	var in advisorBulkInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	userID, _ := middlewares.UserID(c)
+	created, err := h.svc.BulkAssign(services.BulkAssignInput{
+		AdvisorID:      in.AdvisorID,
+		SemesterID:     in.SemesterID,
+		CourseID:       in.CourseID,
+		RoundID:        in.RoundID,
+		Statuses:       in.Statuses,
+		OnlyUnassigned: in.OnlyUnassigned,
+		Scope:          middlewares.CourseScope(c),
+	}, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assigned": created})
+}
+
+// Caseload consolida, por orientador, os alunos atribuídos no semestre.
+func (h *AdvisorHandler) Caseload(c *gin.Context) {
+	semesterID, err := queryUintRequired(c, "semester_id")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	entries, err := h.svc.Caseload(semesterID, middlewares.CourseScope(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
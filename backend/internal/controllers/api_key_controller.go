@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/models"
+	"adamanagement/backend/internal/services"
+)
+
+// APIKeyHandler expõe a emissão e a revogação das chaves de integração.
+type APIKeyHandler struct {
+	svc *services.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *services.APIKeyService) *APIKeyHandler { return &APIKeyHandler{svc: svc} }
+
+// Scopes devolve o catálogo de escopos atribuíveis a uma chave.
+func (h *APIKeyHandler) Scopes(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIKeyScopes)
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.svc.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIKeys(keys))
+}
+
+type apiKeyInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Create emite a chave e devolve o seu valor em claro — única vez em que
+// ele é exibido.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var in apiKeyInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	key, raw, err := h.svc.Create(services.APIKeyInput{
+		Name:      in.Name,
+		Scopes:    in.Scopes,
+		ExpiresAt: in.ExpiresAt,
+	}, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"api_key": dto.NewAPIKey(*key), "key": raw})
+}
+
+// Revoke invalida a chave; ela continua na listagem com revoked_at.
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	key, err := h.svc.Revoke(id, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIKey(*key))
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/services"
+)
+
+type AuditHandler struct {
+	svc *services.AuditService
+}
+
+func NewAuditHandler(svc *services.AuditService) *AuditHandler { return &AuditHandler{svc: svc} }
+
+// List devolve a trilha de auditoria (?action= filtra por prefixo,
+// ?target= pelo alvo exato, ex.: student:2022001).
+func (h *AuditHandler) List(c *gin.Context) {
+	limit, offset, err := pagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, total, err := h.svc.List(services.AuditFilter{
+		Action: c.Query("action"),
+		Target: c.Query("target"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setTotalHeader(c, total)
+	c.JSON(http.StatusOK, entries)
+}
@@ -12,12 +12,13 @@ import (
 )
 
 type AuthHandler struct {
-	svc        *services.AuthService
-	studentSvc *services.StudentAuthService
+	svc           *services.AuthService
+	studentSvc    *services.StudentAuthService
+	notifications *services.NotificationService
 }
 
-func NewAuthHandler(svc *services.AuthService, studentSvc *services.StudentAuthService) *AuthHandler {
-	return &AuthHandler{svc: svc, studentSvc: studentSvc}
+func NewAuthHandler(svc *services.AuthService, studentSvc *services.StudentAuthService, notifications *services.NotificationService) *AuthHandler {
+	return &AuthHandler{svc: svc, studentSvc: studentSvc, notifications: notifications}
 }
 
 type loginInput struct {
@@ -25,38 +26,88 @@ type loginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
-type registerInput struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role"`
-}
-
 func (h *AuthHandler) Login(c *gin.Context) {
 	var in loginInput
 	if !bindJSON(c, &in) {
 		return
 	}
 
-	token, user, err := h.svc.Login(in.Email, in.Password)
+	result, err := h.svc.Login(in.Email, in.Password, actorFrom(c))
 	if err != nil {
 		respondError(c, err)
 		return
 	}
+	respondLogin(c, result)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-			"role":  user.Role,
-		},
-	})
+// respondLogin devolve a sessão aberta ou, com verificação em duas etapas
+// pendente, o desafio do segundo passo (sem tokens).
+func respondLogin(c *gin.Context, result *services.LoginResult) {
+	if result.Tokens == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"enrollment_required": result.EnrollmentRequired,
+			"challenge":           result.Challenge,
+		})
+		return
+	}
+
+	resp := tokenResponse(result.Tokens)
+	resp["user"] = gin.H{
+		"id":    result.User.ID,
+		"name":  result.User.Name,
+		"email": result.User.Email,
+		"role":  result.User.Role,
+	}
+	if result.RecoveryCodes != nil {
+		resp["recovery_codes"] = result.RecoveryCodes
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type twoFactorLoginInput struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code"`
+}
+
+// EnrollTwoFactor gera o segredo do administrador obrigado a configurar a
+// verificação no login (enrollment_required).
+func (h *AuthHandler) EnrollTwoFactor(c *gin.Context) {
+	var in twoFactorLoginInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	setup, err := h.svc.EnrollTwoFactor(in.Challenge)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// VerifyTwoFactor é o segundo passo do login: desafio + código do
+// aplicativo (ou de recuperação).
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var in twoFactorLoginInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	if in.Code == "" {
+		respondError(c, services.Invalid("code é obrigatório"))
+		return
+	}
+	result, err := h.svc.VerifyTwoFactor(in.Challenge, in.Code, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondLogin(c, result)
 }
 
 // Me ramifica por papel: token de aluno devolve a identidade do aluno +
-// enquadramento; token de staff devolve o usuário.
+// enquadramento; token de staff devolve o usuário com as permissões do
+// papel e os cursos do escopo. Ambos trazem o contador de notificações
+// não lidas.
 func (h *AuthHandler) Me(c *gin.Context) {
 	if middlewares.Role(c) == models.RoleStudent {
 		studentID, ok := middlewares.StudentID(c)
@@ -69,7 +120,14 @@ func (h *AuthHandler) Me(c *gin.Context) {
 			respondError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, dto.NewStudentMe(*student, status))
+		unread, err := h.notifications.UnreadCount(services.StudentRecipient(studentID))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		me := dto.NewStudentMe(*student, status)
+		me.UnreadNotifications = unread
+		c.JSON(http.StatusOK, me)
 		return
 	}
 
@@ -84,24 +142,24 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		respondError(c, err)
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"role":  user.Role,
-	})
-}
-
-func (h *AuthHandler) Register(c *gin.Context) {
-	var in registerInput
-	if !bindJSON(c, &in) {
+	unread, err := h.notifications.UnreadCount(services.UserRecipient(id))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-
-	if _, err := h.svc.CreateUser(in.Name, in.Email, in.Password, in.Role); err != nil {
+	permissions, err := h.svc.Permissions(user.Role)
+	if err != nil {
 		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": "Usuário criado com sucesso"})
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                   user.ID,
+		"name":                 user.Name,
+		"email":                user.Email,
+		"role":                 user.Role,
+		"permissions":          permissions,
+		"courses":              dto.NewCourses(user.Courses),
+		"unread_notifications": unread,
+	})
 }
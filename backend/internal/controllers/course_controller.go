@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/services"
+)
+
+// CourseHandler expõe a configuração dos cursos.
+type CourseHandler struct {
+	svc *services.CourseService
+}
+
+func NewCourseHandler(svc *services.CourseService) *CourseHandler {
+	return &CourseHandler{svc: svc}
+}
+
+type maxDurationInput struct {
+	MaxDurationSemesters *int `json:"max_duration_semesters" binding:"required"`
+}
+
+// SetMaxDuration grava o prazo máximo de integralização do curso.
+func (h *CourseHandler) SetMaxDuration(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var in maxDurationInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	course, err := h.svc.SetMaxDuration(id, *in.MaxDurationSemesters, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewCourse(*course))
+}
@@ -18,11 +18,18 @@ func NewDisciplineHandler(svc *services.DisciplineService) *DisciplineHandler {
 }
 
 func (h *DisciplineHandler) List(c *gin.Context) {
-	disciplines, err := h.svc.List()
+	page, err := listPage(c)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
+	disciplines, info, err := h.svc.List(c.Query("sort"), page)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewDisciplines(disciplines))
 }
 
@@ -7,6 +7,8 @@
 package dto
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"adamanagement/backend/internal/models"
@@ -30,14 +32,15 @@ func NewSemesters(ms []models.Semester) []Semester {
 }
 
 type Course struct {
-	ID          uint   `json:"ID"`
-	Code        int    `json:"code"`
-	Name        string `json:"name"`
-	Coordinator string `json:"coordinator"`
+	ID                   uint   `json:"ID"`
+	Code                 int    `json:"code"`
+	Name                 string `json:"name"`
+	Coordinator          string `json:"coordinator"`
+	MaxDurationSemesters int    `json:"max_duration_semesters"`
 }
 
 func NewCourse(m models.Course) Course {
-	return Course{ID: m.ID, Code: m.Code, Name: m.Name, Coordinator: m.Coordinator}
+	return Course{ID: m.ID, Code: m.Code, Name: m.Name, Coordinator: m.Coordinator, MaxDurationSemesters: m.MaxDurationSemesters}
 }
 
 func NewCourses(ms []models.Course) []Course {
@@ -67,14 +70,23 @@ func NewDisciplines(ms []models.Discipline) []Discipline {
 }
 
 type User struct {
-	ID    uint   `json:"ID"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID      uint     `json:"ID"`
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Role    string   `json:"role"`
+	Courses []Course `json:"courses,omitempty"`
+	// InvitationPending marca o convidado que ainda não ativou a conta.
+	InvitationPending bool       `json:"invitation_pending"`
+	DeactivatedAt     *time.Time `json:"deactivated_at"`
 }
 
+// NewUser inclui os cursos sob coordenação quando foram carregados.
 func NewUser(m models.User) User {
-	return User{ID: m.ID, Name: m.Name, Email: m.Email, Role: m.Role}
+	u := User{ID: m.ID, Name: m.Name, Email: m.Email, Role: m.Role, InvitationPending: m.InvitationPending, DeactivatedAt: m.DeactivatedAt}
+	if len(m.Courses) > 0 {
+		u.Courses = NewCourses(m.Courses)
+	}
+	return u
 }
 
 func NewUsers(ms []models.User) []User {
@@ -92,6 +104,7 @@ type Student struct {
 	EntryYear    int     `json:"entry_year"`
 	EntryPeriod  string  `json:"entry_period"`
 	QuotaType    string  `json:"quota_type"`
+	Email        string  `json:"email,omitempty"`
 	Course       *Course `json:"course,omitempty"`
 }
 
@@ -103,6 +116,7 @@ func NewStudent(m models.Student) Student {
 		EntryYear:    m.EntryYear,
 		EntryPeriod:  m.EntryPeriod,
 		QuotaType:    m.QuotaType,
+		Email:        m.Email,
 	}
 	if m.Course.ID != 0 {
 		course := NewCourse(m.Course)
@@ -120,33 +134,39 @@ func NewStudents(ms []models.Student) []Student {
 }
 
 type AcademicRecord struct {
-	ID                uint      `json:"ID"`
-	StudentID         uint      `json:"student_id"`
-	SemesterID        uint      `json:"semester_id"`
-	Status            string    `json:"status"`
-	StatusDetail      string    `json:"status_detail"`
-	IntegralizedHours int       `json:"integralized_hours"`
-	TotalHours        int       `json:"total_hours"`
-	PendingObligatory int       `json:"pending_obligatory"`
-	SemestersNoHours  int       `json:"semesters_no_hours"`
-	Locks             int       `json:"locks"`
-	Student           *Student  `json:"student,omitempty"`
-	Semester          *Semester `json:"semester,omitempty"`
+	ID                 uint      `json:"ID"`
+	StudentID          uint      `json:"student_id"`
+	SemesterID         uint      `json:"semester_id"`
+	Status             string    `json:"status"`
+	StatusDetail       string    `json:"status_detail"`
+	IntegralizedHours  int       `json:"integralized_hours"`
+	TotalHours         int       `json:"total_hours"`
+	PendingObligatory  int       `json:"pending_obligatory"`
+	SemestersNoHours   int       `json:"semesters_no_hours"`
+	Locks              int       `json:"locks"`
+	RiskScore          float64   `json:"risk_score"`
+	ExpectedGraduation string    `json:"expected_graduation"`
+	ExceedsMaxDuration bool      `json:"exceeds_max_duration"`
+	Student            *Student  `json:"student,omitempty"`
+	Semester           *Semester `json:"semester,omitempty"`
 }
 
 // NewAcademicRecord inclui aluno e semestre apenas quando pré-carregados.
 func NewAcademicRecord(m models.AcademicRecord) AcademicRecord {
 	r := AcademicRecord{
-		ID:                m.ID,
-		StudentID:         m.StudentID,
-		SemesterID:        m.SemesterID,
-		Status:            m.Status,
-		StatusDetail:      m.StatusDetail,
-		IntegralizedHours: m.IntegralizedHours,
-		TotalHours:        m.TotalHours,
-		PendingObligatory: m.PendingObligatory,
-		SemestersNoHours:  m.SemestersNoHours,
-		Locks:             m.Locks,
+		ID:                 m.ID,
+		StudentID:          m.StudentID,
+		SemesterID:         m.SemesterID,
+		Status:             m.Status,
+		StatusDetail:       m.StatusDetail,
+		IntegralizedHours:  m.IntegralizedHours,
+		TotalHours:         m.TotalHours,
+		PendingObligatory:  m.PendingObligatory,
+		SemestersNoHours:   m.SemestersNoHours,
+		Locks:              m.Locks,
+		RiskScore:          m.RiskScore,
+		ExpectedGraduation: m.ExpectedGraduation,
+		ExceedsMaxDuration: m.ExceedsMaxDuration,
 	}
 	if m.Student.ID != 0 {
 		student := NewStudent(m.Student)
@@ -201,6 +221,8 @@ type StudyPlan struct {
 	SemesterID  uint         `json:"semester_id"`
 	Semester    *Semester    `json:"semester,omitempty"`
 	Disciplines []Discipline `json:"disciplines"`
+	ReturnedAt  *time.Time   `json:"returned_at"`
+	ReturnNote  string       `json:"return_note,omitempty"`
 }
 
 func NewStudyPlan(m models.StudyPlan) StudyPlan {
@@ -209,6 +231,8 @@ func NewStudyPlan(m models.StudyPlan) StudyPlan {
 		StudentID:   m.StudentID,
 		SemesterID:  m.SemesterID,
 		Disciplines: NewDisciplines(m.Disciplines),
+		ReturnedAt:  m.ReturnedAt,
+		ReturnNote:  m.ReturnNote,
 	}
 	if m.Semester.ID != 0 {
 		semester := NewSemester(m.Semester)
@@ -226,7 +250,10 @@ type StudentMe struct {
 	Name         string  `json:"name"`
 	Role         string  `json:"role"`
 	Status       string  `json:"status"`
+	Email        string  `json:"email"`
 	Course       *Course `json:"course,omitempty"`
+
+	UnreadNotifications int64 `json:"unread_notifications"`
 }
 
 func NewStudentMe(m models.Student, status string) StudentMe {
@@ -236,6 +263,7 @@ func NewStudentMe(m models.Student, status string) StudentMe {
 		Name:         m.Name,
 		Role:         models.RoleStudent,
 		Status:       status,
+		Email:        m.Email,
 	}
 	if m.Course.ID != 0 {
 		course := NewCourse(m.Course)
@@ -244,21 +272,36 @@ func NewStudentMe(m models.Student, status string) StudentMe {
 	return me
 }
 
+// authorName é o nome do autor de um registro quando ele foi carregado.
+func authorName(u *models.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.Name
+}
+
+// PlanRound é a rodada; OpenedBy traz o nome de quem a abriu.
 type PlanRound struct {
-	ID           uint     `json:"ID"`
-	Open         bool     `json:"open"`
-	BaseSemester Semester `json:"base_semester"`
-	Period1      Semester `json:"period1"`
-	Period2      Semester `json:"period2"`
+	ID             uint       `json:"ID"`
+	Open           bool       `json:"open"`
+	BaseSemester   Semester   `json:"base_semester"`
+	Period1        Semester   `json:"period1"`
+	Period2        Semester   `json:"period2"`
+	ClosesAt       *time.Time `json:"closes_at"`
+	OpenedByUserID uint       `json:"opened_by_user_id"`
+	OpenedBy       string     `json:"opened_by,omitempty"`
 }
 
 func NewPlanRound(m models.PlanRound) PlanRound {
 	return PlanRound{
-		ID:           m.ID,
-		Open:         m.Open,
-		BaseSemester: NewSemester(m.BaseSemester),
-		Period1:      NewSemester(m.Period1),
-		Period2:      NewSemester(m.Period2),
+		ID:             m.ID,
+		Open:           m.Open,
+		BaseSemester:   NewSemester(m.BaseSemester),
+		Period1:        NewSemester(m.Period1),
+		Period2:        NewSemester(m.Period2),
+		ClosesAt:       m.ClosesAt,
+		OpenedByUserID: m.OpenedByUserID,
+		OpenedBy:       authorName(m.OpenedBy),
 	}
 }
 
@@ -269,3 +312,360 @@ func NewPlanRounds(ms []models.PlanRound) []PlanRound {
 	}
 	return out
 }
+
+type Webhook struct {
+	ID        uint      `json:"ID"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewWebhook(m models.Webhook) Webhook {
+	return Webhook{
+		ID:        m.ID,
+		URL:       m.URL,
+		Events:    strings.Split(m.Events, ","),
+		Active:    m.Active,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func NewWebhooks(ms []models.Webhook) []Webhook {
+	out := make([]Webhook, len(ms))
+	for i, m := range ms {
+		out[i] = NewWebhook(m)
+	}
+	return out
+}
+
+// Invitation é o convite de usuário sem o hash do token; Status resume a
+// situação (pending, accepted, revoked ou expired).
+type Invitation struct {
+	ID         uint       `json:"ID"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentCount  int        `json:"sent_count"`
+	LastSentAt time.Time  `json:"last_sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewInvitation(m models.UserInvitation) Invitation {
+	status := "pending"
+	switch {
+	case m.AcceptedAt != nil:
+		status = "accepted"
+	case m.RevokedAt != nil:
+		status = "revoked"
+	case !time.Now().Before(m.ExpiresAt):
+		status = "expired"
+	}
+	return Invitation{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		Email:      m.Email,
+		Role:       m.Role,
+		Status:     status,
+		ExpiresAt:  m.ExpiresAt,
+		SentCount:  m.SentCount,
+		LastSentAt: m.LastSentAt,
+		AcceptedAt: m.AcceptedAt,
+		RevokedAt:  m.RevokedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func NewInvitations(ms []models.UserInvitation) []Invitation {
+	out := make([]Invitation, len(ms))
+	for i, m := range ms {
+		out[i] = NewInvitation(m)
+	}
+	return out
+}
+
+// APIKey é a chave de integração sem o hash; o valor em claro só aparece
+// na resposta da criação.
+type APIKey struct {
+	ID         uint       `json:"ID"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAPIKey(m models.APIKey) APIKey {
+	return APIKey{
+		ID:         m.ID,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		Scopes:     strings.Split(m.Scopes, ","),
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		RevokedAt:  m.RevokedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func NewAPIKeys(ms []models.APIKey) []APIKey {
+	out := make([]APIKey, len(ms))
+	for i, m := range ms {
+		out[i] = NewAPIKey(m)
+	}
+	return out
+}
+
+type WebhookDelivery struct {
+	ID             uint            `json:"ID"`
+	WebhookID      uint            `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus int             `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	RedeliveryOfID *uint           `json:"redelivery_of_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func NewWebhookDelivery(m models.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             m.ID,
+		WebhookID:      m.WebhookID,
+		Event:          m.Event,
+		Payload:        json.RawMessage(m.Payload),
+		Status:         m.Status,
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		ResponseStatus: m.ResponseStatus,
+		LastError:      m.LastError,
+		DeliveredAt:    m.DeliveredAt,
+		RedeliveryOfID: m.RedeliveryOfID,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func NewWebhookDeliveries(ms []models.WebhookDelivery) []WebhookDelivery {
+	out := make([]WebhookDelivery, len(ms))
+	for i, m := range ms {
+		out[i] = NewWebhookDelivery(m)
+	}
+	return out
+}
+
+type Notification struct {
+	ID        uint       `json:"ID"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewNotification(m models.Notification) Notification {
+	return Notification{
+		ID:        m.ID,
+		Kind:      m.Kind,
+		Title:     m.Title,
+		Body:      m.Body,
+		ReadAt:    m.ReadAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func NewNotifications(ms []models.Notification) []Notification {
+	out := make([]Notification, len(ms))
+	for i, m := range ms {
+		out[i] = NewNotification(m)
+	}
+	return out
+}
+
+// AdvisorAssignment é uma atribuição; AssignedBy traz o nome de quem
+// atribuiu.
+type AdvisorAssignment struct {
+	ID               uint       `json:"ID"`
+	Semester         Semester   `json:"semester"`
+	Advisor          User       `json:"advisor"`
+	AssignedByUserID uint       `json:"assigned_by_user_id"`
+	AssignedBy       string     `json:"assigned_by,omitempty"`
+	EndedAt          *time.Time `json:"ended_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func NewAdvisorAssignment(m models.AdvisorAssignment) AdvisorAssignment {
+	return AdvisorAssignment{
+		ID:               m.ID,
+		Semester:         NewSemester(m.Semester),
+		Advisor:          NewUser(m.Advisor),
+		AssignedByUserID: m.AssignedByUserID,
+		AssignedBy:       authorName(m.AssignedBy),
+		EndedAt:          m.EndedAt,
+		CreatedAt:        m.CreatedAt,
+	}
+}
+
+func NewAdvisorAssignments(ms []models.AdvisorAssignment) []AdvisorAssignment {
+	out := make([]AdvisorAssignment, len(ms))
+	for i, m := range ms {
+		out[i] = NewAdvisorAssignment(m)
+	}
+	return out
+}
+
+// TriageRule é uma versão dos limiares de triagem; Course traz o nome do
+// curso nas regras por curso (vazio na global) e CreatedBy o do autor.
+type TriageRule struct {
+	ID                       uint      `json:"ID"`
+	CourseID                 *uint     `json:"course_id"`
+	Course                   string    `json:"course,omitempty"`
+	EffectiveFrom            string    `json:"effective_from"`
+	MaxLocks                 int       `json:"max_locks"`
+	MaxSemestersNoHours      int       `json:"max_semesters_no_hours"`
+	NearGraduationMaxPending int       `json:"near_graduation_max_pending"`
+	CreatedByUserID          uint      `json:"created_by_user_id"`
+	CreatedBy                string    `json:"created_by,omitempty"`
+	CreatedAt                time.Time `json:"created_at"`
+}
+
+func NewTriageRule(m models.TriageRule) TriageRule {
+	out := TriageRule{
+		ID:                       m.ID,
+		CourseID:                 m.CourseID,
+		EffectiveFrom:            m.EffectiveFrom,
+		MaxLocks:                 m.MaxLocks,
+		MaxSemestersNoHours:      m.MaxSemestersNoHours,
+		NearGraduationMaxPending: m.NearGraduationMaxPending,
+		CreatedByUserID:          m.CreatedByUserID,
+		CreatedBy:                authorName(m.CreatedBy),
+		CreatedAt:                m.CreatedAt,
+	}
+	if m.Course != nil {
+		out.Course = m.Course.Name
+	}
+	return out
+}
+
+func NewTriageRules(ms []models.TriageRule) []TriageRule {
+	out := make([]TriageRule, len(ms))
+	for i, m := range ms {
+		out[i] = NewTriageRule(m)
+	}
+	return out
+}
+
+// ReportView é uma visão salva; Owner traz o nome do dono e Mine indica
+// se ela pertence ao usuário autenticado.
+type ReportView struct {
+	ID        uint            `json:"ID"`
+	Name      string          `json:"name"`
+	Report    string          `json:"report"`
+	Filters   json.RawMessage `json:"filters"`
+	Columns   []string        `json:"columns"`
+	Sort      string          `json:"sort"`
+	Shared    bool            `json:"shared"`
+	UserID    uint            `json:"user_id"`
+	Owner     string          `json:"owner,omitempty"`
+	Mine      bool            `json:"mine"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func NewReportView(m models.ReportView, viewerID uint) ReportView {
+	filters := json.RawMessage(m.Filters)
+	if len(filters) == 0 {
+		filters = json.RawMessage("{}")
+	}
+	columns := []string{}
+	if m.Columns != "" {
+		columns = strings.Split(m.Columns, ",")
+	}
+	return ReportView{
+		ID:        m.ID,
+		Name:      m.Name,
+		Report:    m.Report,
+		Filters:   filters,
+		Columns:   columns,
+		Sort:      m.Sort,
+		Shared:    m.Shared,
+		UserID:    m.UserID,
+		Mine:      viewerID != 0 && m.UserID == viewerID,
+		Owner:     authorName(m.User),
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func NewReportViews(ms []models.ReportView, viewerID uint) []ReportView {
+	out := make([]ReportView, len(ms))
+	for i, m := range ms {
+		out[i] = NewReportView(m, viewerID)
+	}
+	return out
+}
+
+// ReportSchedule é a assinatura de entrega agendada, com filtros,
+// colunas e destinatários já decodificados; Owner traz o nome do
+// responsável.
+type ReportSchedule struct {
+	ID         uint            `json:"ID"`
+	Name       string          `json:"name"`
+	Cron       string          `json:"cron"`
+	Report     string          `json:"report"`
+	Filters    json.RawMessage `json:"filters"`
+	Columns    []string        `json:"columns"`
+	Sort       string          `json:"sort"`
+	Format     string          `json:"format"`
+	Recipients []string        `json:"recipients"`
+	Active     bool            `json:"active"`
+	NextRunAt  *time.Time      `json:"next_run_at"`
+	LastRunAt  *time.Time      `json:"last_run_at"`
+	UserID     uint            `json:"user_id"`
+	Owner      string          `json:"owner,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func NewReportSchedule(m models.ReportSchedule) ReportSchedule {
+	filters := json.RawMessage(m.Filters)
+	if len(filters) == 0 {
+		filters = json.RawMessage("{}")
+	}
+	columns := []string{}
+	if m.Columns != "" {
+		columns = strings.Split(m.Columns, ",")
+	}
+	return ReportSchedule{
+		ID:         m.ID,
+		Name:       m.Name,
+		Cron:       m.Cron,
+		Report:     m.Report,
+		Filters:    filters,
+		Columns:    columns,
+		Sort:       m.Sort,
+		Format:     m.Format,
+		Recipients: strings.Split(m.Recipients, ","),
+		Active:     m.Active,
+		NextRunAt:  m.NextRunAt,
+		LastRunAt:  m.LastRunAt,
+		UserID:     m.UserID,
+		Owner:      authorName(m.User),
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func NewReportSchedules(ms []models.ReportSchedule) []ReportSchedule {
+	out := make([]ReportSchedule, len(ms))
+	for i, m := range ms {
+		out[i] = NewReportSchedule(m)
+	}
+	return out
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"adamanagement/backend/internal/middlewares"
 	"adamanagement/backend/internal/services"
 )
 
@@ -24,7 +25,8 @@ func (h *ImportHandler) Upload(c *gin.Context) {
 	}
 	defer file.Close()
 
-	summary, err := h.svc.Process(file, header.Filename)
+	userID, _ := middlewares.UserID(c)
+	summary, err := h.svc.Process(file, header.Filename, userID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"adamanagement/backend/internal/middlewares"
 	"adamanagement/backend/internal/services"
 )
 
@@ -17,10 +18,71 @@ func NewIndicatorsHandler(svc *services.IndicatorsService) *IndicatorsHandler {
 }
 
 func (h *IndicatorsHandler) Dashboard(c *gin.Context) {
-	dashboard, err := h.svc.Dashboard(c.Query("semester_id"))
+	dashboard, err := h.svc.Dashboard(c.Query("semester_id"), middlewares.CourseScope(c))
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, dashboard)
 }
+
+// Trends devolve a série histórica dos indicadores entre os semestres
+// from e to (códigos, opcionais); by_course=true separa por curso.
+func (h *IndicatorsHandler) Trends(c *gin.Context) {
+	trends, err := h.svc.Trends(services.TrendsFilter{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		ByCourse: c.Query("by_course") == "true",
+		Scope:    middlewares.CourseScope(c),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trends)
+}
+
+// Cohorts devolve a retenção das coortes de ingresso por curso; com
+// format=csv ou xlsx, a mesma tabela como arquivo.
+func (h *IndicatorsHandler) Cohorts(c *gin.Context) {
+	format, err := exportFormat(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	courseID, err := courseIDQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	entryYear, err := intQuery(c, "entry_year")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	cohorts, err := h.svc.Cohorts(services.CohortsFilter{
+		CourseID:  courseID,
+		EntryYear: entryYear,
+		Scope:     middlewares.CourseScope(c),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if format != "" {
+		respondExport(c, "retencao-coortes", format, services.CohortsTable(cohorts))
+		return
+	}
+	c.JSON(http.StatusOK, cohorts)
+}
+
+// Breakdown recorta os indicadores do semestre por curso, tipo de cota ou
+// coorte de ingresso (parâmetro by).
+func (h *IndicatorsHandler) Breakdown(c *gin.Context) {
+	data, err := h.svc.Breakdown(c.Query("semester_id"), c.Query("by"), middlewares.CourseScope(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/services"
+)
+
+// InvitationHandler expõe o convite de usuários da coordenação e a
+// ativação da conta pelo convidado.
+type InvitationHandler struct {
+	svc *services.InvitationService
+}
+
+func NewInvitationHandler(svc *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{svc: svc}
+}
+
+type inviteInput struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+// Create convida o usuário. A resposta traz o link de ativação
+// (activation_url) para entrega manual caso o e-mail não chegue.
+func (h *InvitationHandler) Create(c *gin.Context) {
+	var in inviteInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	link, err := h.svc.Invite(services.InviteInput{Name: in.Name, Email: in.Email, Role: in.Role}, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invitation": dto.NewInvitation(link.Invitation), "activation_url": link.URL})
+}
+
+// List aceita ?status=pending|accepted|revoked|expired|all (padrão: pending).
+func (h *InvitationHandler) List(c *gin.Context) {
+	items, err := h.svc.List(c.Query("status"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewInvitations(items))
+}
+
+// Resend gera um link novo e reenvia o e-mail; o link anterior deixa de
+// valer.
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	link, err := h.svc.Resend(id, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitation": dto.NewInvitation(link.Invitation), "activation_url": link.URL})
+}
+
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Revoke(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Convite revogado"})
+}
+
+type activateInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Activate é a rota pública em que o convidado define a senha.
+func (h *InvitationHandler) Activate(c *gin.Context) {
+	var in activateInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	if err := h.svc.Accept(in.Token, in.Password, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Conta ativada. Faça login com a nova senha."})
+}
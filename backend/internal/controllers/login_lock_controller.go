@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// LoginLockHandler expõe os contadores de falhas de login — quem está
+// bloqueado ou em atraso progressivo, e o desbloqueio manual: à
+// coordenação, os alunos do seu escopo; à gestão de usuários, as contas
+// da coordenação e os IPs.
+type LoginLockHandler struct {
+	guard *services.LoginGuard
+}
+
+func NewLoginLockHandler(guard *services.LoginGuard) *LoginLockHandler {
+	return &LoginLockHandler{guard: guard}
+}
+
+func (h *LoginLockHandler) List(c *gin.Context) {
+	items, err := h.guard.ActiveStudents(middlewares.CourseScope(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *LoginLockHandler) Unlock(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.guard.UnlockStudent(id, middlewares.CourseScope(c), actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Acesso desbloqueado"})
+}
+
+func (h *LoginLockHandler) ListAccounts(c *gin.Context) {
+	items, err := h.guard.ActiveAccounts()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *LoginLockHandler) UnlockAccount(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.guard.UnlockAccount(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Acesso desbloqueado"})
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/models"
+	"adamanagement/backend/internal/services"
+)
+
+// streamPollInterval é o intervalo de consulta do stream de notificações.
+// A cada ciclo sem novidades, um comentário mantém a conexão viva
+// através de proxies.
+const streamPollInterval = 5 * time.Second
+
+type NotificationHandler struct {
+	svc *services.NotificationService
+	// sessions revalida, a cada ciclo do stream, a sessão que o abriu.
+	sessions middlewares.SessionValidator
+}
+
+func NewNotificationHandler(svc *services.NotificationService, sessions middlewares.SessionValidator) *NotificationHandler {
+	return &NotificationHandler{svc: svc, sessions: sessions}
+}
+
+// recipient identifica o dono das notificações pelo token: aluno ou staff.
+func recipient(c *gin.Context) services.Recipient {
+	if middlewares.Role(c) == models.RoleStudent {
+		id, _ := middlewares.StudentID(c)
+		return services.StudentRecipient(id)
+	}
+	id, _ := middlewares.UserID(c)
+	return services.UserRecipient(id)
+}
+
+// List devolve as notificações do requisitante (?unread=true filtra as não
+// lidas), com o total de não lidas no cabeçalho X-Unread-Count.
+func (h *NotificationHandler) List(c *gin.Context) {
+	limit, offset, err := pagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	to := recipient(c)
+	items, total, err := h.svc.List(to, c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	unread, err := h.svc.UnreadCount(to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setTotalHeader(c, total)
+	c.Header("X-Unread-Count", strconv.FormatInt(unread, 10))
+	c.JSON(http.StatusOK, dto.NewNotifications(items))
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	n, err := h.svc.MarkRead(recipient(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewNotification(*n))
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	updated, err := h.svc.MarkAllRead(recipient(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// Stream entrega as notificações novas por Server-Sent Events: eventos
+// "notification" (com id, para retomada via Last-Event-ID) e "unread"
+// com o contador sempre que ele muda. Sem Last-Event-ID, só o que chegar
+// após a conexão é enviado — o histórico fica em List.
+//
+// A autenticação não vale só na abertura: a cada ciclo a sessão é
+// revalidada (logout, sessão revogada, conta desativada, senha trocada) e,
+// vencido o token de acesso, o stream termina. Nos dois casos um evento
+// "expired" avisa o cliente, que reconecta com um token renovado.
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	to := recipient(c)
+	claims, _ := middlewares.CurrentClaims(c)
+
+	lastID, err := h.svc.LatestID(to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		if id, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			lastID = uint(id)
+		}
+	}
+	unread, err := h.svc.UnreadCount(to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	writeSSE(c.Writer, "unread", "", gin.H{"count": unread})
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ticker.C:
+		}
+
+		if err := h.stillAuthorized(claims); err != nil {
+			if !errors.Is(err, services.ErrUnauthorized) {
+				slog.Error("stream de notificações", "error", err)
+			}
+			writeSSE(w, "expired", "", gin.H{"reason": err.Error()})
+			return false
+		}
+
+		items, err := h.svc.Since(to, lastID)
+		if err != nil {
+			slog.Error("stream de notificações", "error", err)
+			return false
+		}
+		for _, n := range items {
+			writeSSE(w, "notification", strconv.FormatUint(uint64(n.ID), 10), dto.NewNotification(n))
+			lastID = n.ID
+		}
+
+		count, err := h.svc.UnreadCount(to)
+		if err != nil {
+			slog.Error("stream de notificações", "error", err)
+			return false
+		}
+		if count != unread {
+			unread = count
+			writeSSE(w, "unread", "", gin.H{"count": unread})
+		} else if len(items) == 0 {
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		return true
+	})
+}
+
+// stillAuthorized confere se o token que abriu o stream segue válido.
+func (h *NotificationHandler) stillAuthorized(claims *services.Claims) error {
+	if claims == nil {
+		return services.Unauthorized("sessão inválida")
+	}
+	if claims.ExpiresAt != nil && !claims.ExpiresAt.After(time.Now()) {
+		return services.Unauthorized("token expirado")
+	}
+	return h.sessions.Validate(claims)
+}
+
+// writeSSE escreve um evento no formato text/event-stream.
+func writeSSE(w io.Writer, event, id string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("serializar evento SSE", "error", err)
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"adamanagement/backend/internal/services"
+)
+
+type fakeSessions struct{ err error }
+
+func (f fakeSessions) Validate(*services.Claims) error { return f.err }
+
+func TestStreamStopsWhenSessionEnds(t *testing.T) {
+	valid := &services.Claims{SessionID: "s", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+	expired := &services.Claims{SessionID: "s", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Second)),
+	}}
+
+	open := &NotificationHandler{sessions: fakeSessions{}}
+	if err := open.stillAuthorized(valid); err != nil {
+		t.Errorf("sessão válida deveria manter o stream: %v", err)
+	}
+	if err := open.stillAuthorized(expired); !errors.Is(err, services.ErrUnauthorized) {
+		t.Errorf("token vencido deveria encerrar o stream: %v", err)
+	}
+	revoked := &NotificationHandler{sessions: fakeSessions{services.Unauthorized("sessão encerrada")}}
+	if err := revoked.stillAuthorized(valid); !errors.Is(err, services.ErrUnauthorized) {
+		t.Errorf("sessão encerrada deveria encerrar o stream: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/services"
+)
+
+// OIDCHandler expõe o login institucional (OpenID Connect). O frontend
+// pede a URL de autorização, leva o navegador ao provedor e, no retorno,
+// envia code e state para concluir o login. Um cookie HttpOnly emitido na
+// autorização prende o login ao navegador que o iniciou.
+type OIDCHandler struct {
+	svc           *services.OIDCService
+	passwordLogin bool
+}
+
+func NewOIDCHandler(svc *services.OIDCService, passwordLogin bool) *OIDCHandler {
+	return &OIDCHandler{svc: svc, passwordLogin: passwordLogin}
+}
+
+// Providers informa ao frontend quais formas de login estão disponíveis.
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"password": h.passwordLogin,
+		"oidc":     h.svc.Enabled(),
+	})
+}
+
+// oidcBindingCookie guarda o vínculo do login com o navegador; vale para
+// /api e /api/v1 e só pelo tempo do state.
+const oidcBindingCookie = "oidc_binding"
+
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authURL, binding, err := h.svc.AuthorizationURL(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setBindingCookie(c, binding, int(services.OIDCStateTTL.Seconds()))
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// setBindingCookie grava (ou, com maxAge negativo, apaga) o cookie do
+// vínculo: HttpOnly, SameSite=Lax e Secure sob HTTPS.
+func setBindingCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, value, maxAge, "/api", "", secure, true)
+}
+
+type oidcCallbackInput struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// Callback conclui o login e responde no mesmo formato do login por senha
+// da coordenação ou do aluno, conforme a conta associada.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var in oidcCallbackInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	// Sem o cookie, binding vazio: o serviço recusa o state.
+	binding, _ := c.Cookie(oidcBindingCookie)
+	setBindingCookie(c, "", -1)
+	login, err := h.svc.Callback(c.Request.Context(), in.Code, in.State, binding, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if login.Student != nil {
+		c.JSON(http.StatusOK, studentLoginResponse(login.Tokens, login.Student))
+		return
+	}
+	respondLogin(c, &services.LoginResult{Tokens: login.Tokens, User: login.User})
+}
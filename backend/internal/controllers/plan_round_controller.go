@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -19,8 +20,9 @@ func NewPlanRoundHandler(svc *services.PlanRoundService) *PlanRoundHandler {
 }
 
 type openRoundInput struct {
-	Period1 string `json:"period1" binding:"required"`
-	Period2 string `json:"period2" binding:"required"`
+	Period1  string     `json:"period1" binding:"required"`
+	Period2  string     `json:"period2" binding:"required"`
+	ClosesAt *time.Time `json:"closes_at"`
 }
 
 func (h *PlanRoundHandler) Open(c *gin.Context) {
@@ -30,7 +32,7 @@ func (h *PlanRoundHandler) Open(c *gin.Context) {
 	}
 
 	userID, _ := middlewares.UserID(c)
-	round, err := h.svc.Open(in.Period1, in.Period2, userID)
+	round, err := h.svc.Open(in.Period1, in.Period2, in.ClosesAt, userID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -77,14 +79,14 @@ func (h *PlanRoundHandler) Reopen(c *gin.Context) {
 
 // Cohort devolve a rodada e seus alunos (PAE/PIC do semestre-base).
 // A rodada é identificada por ?round_id=X (evita conflito de rota com
-// /rounds/current).
+// /rounds/current); ?mine=true restringe aos alunos do requisitante.
 func (h *PlanRoundHandler) Cohort(c *gin.Context) {
 	roundID, err := queryUintRequired(c, "round_id")
 	if err != nil {
 		respondError(c, err)
 		return
 	}
-	round, students, err := h.svc.Cohort(roundID)
+	round, students, err := h.svc.Cohort(roundID, mineFilter(c), middlewares.CourseScope(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -133,10 +135,17 @@ func (h *PlanRoundHandler) Current(c *gin.Context) {
 }
 
 func (h *PlanRoundHandler) List(c *gin.Context) {
-	rounds, err := h.svc.List()
+	page, err := listPage(c)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
+	rounds, info, err := h.svc.List(c.Query("sort"), page)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewPlanRounds(rounds))
 }
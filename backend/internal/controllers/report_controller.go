@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
 	"adamanagement/backend/internal/services"
 )
 
@@ -25,7 +26,7 @@ func (h *ReportHandler) Semesters(c *gin.Context) {
 }
 
 func (h *ReportHandler) Records(c *gin.Context) {
-	limit, offset, err := pagination(c)
+	page, err := listPage(c)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -37,23 +38,27 @@ func (h *ReportHandler) Records(c *gin.Context) {
 		return
 	}
 
-	records, total, err := h.svc.Records(services.RecordsFilter{
-		SemesterID:   c.Query("semester_id"),
-		Registration: c.Query("registration"),
-		StudentName:  c.Query("student_name"),
-		CourseName:   c.Query("course_name"),
-		Status:       c.Query("status"),
-		CriticalOnly: c.Query("mode") == "critical",
-		MaxPending:   maxPending,
-		Limit:        limit,
-		Offset:       offset,
+	records, info, err := h.svc.Records(services.RecordsFilter{
+		SemesterID:         c.Query("semester_id"),
+		Registration:       c.Query("registration"),
+		StudentName:        c.Query("student_name"),
+		CourseName:         c.Query("course_name"),
+		Status:             c.Query("status"),
+		CriticalOnly:       c.Query("mode") == "critical",
+		NearGraduationOnly: c.Query("mode") == "near_graduation",
+		MaxPending:         maxPending,
+		ExceedsMaxDuration: c.Query("exceeds_max_duration") == "true",
+		AdvisorID:          mineFilter(c),
+		Sort:               c.Query("sort"),
+		Scope:              middlewares.CourseScope(c),
+		Page:               page,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	setTotalHeader(c, total)
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewAcademicRecords(records))
 }
 
@@ -63,20 +68,30 @@ func (h *ReportHandler) Courses(c *gin.Context) {
 		respondError(c, err)
 		return
 	}
+	page, err := listPage(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-	courses, err := h.svc.Courses(services.CoursesFilter{
-		Code: code,
-		Name: c.Query("name"),
+	courses, info, err := h.svc.Courses(services.CoursesFilter{
+		Code:  code,
+		Name:  c.Query("name"),
+		Scope: middlewares.CourseScope(c),
+		Sort:  c.Query("sort"),
+		Page:  page,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
+
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewCourses(courses))
 }
 
 func (h *ReportHandler) Students(c *gin.Context) {
-	limit, offset, err := pagination(c)
+	page, err := listPage(c)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -88,20 +103,21 @@ func (h *ReportHandler) Students(c *gin.Context) {
 		return
 	}
 
-	students, total, err := h.svc.Students(services.StudentsFilter{
+	students, info, err := h.svc.Students(services.StudentsFilter{
 		SemesterID:   c.Query("semester_id"),
 		Registration: c.Query("registration"),
 		Name:         c.Query("name"),
 		EntryYear:    entryYear,
 		QuotaType:    c.Query("quota_type"),
-		Limit:        limit,
-		Offset:       offset,
+		Scope:        middlewares.CourseScope(c),
+		Sort:         c.Query("sort"),
+		Page:         page,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	setTotalHeader(c, total)
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewStudents(students))
 }
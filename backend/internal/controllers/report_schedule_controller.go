@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// ReportScheduleHandler expõe as assinaturas de entrega agendada de
+// relatórios e o histórico de execuções.
+type ReportScheduleHandler struct {
+	svc *services.ReportScheduleService
+}
+
+func NewReportScheduleHandler(svc *services.ReportScheduleService) *ReportScheduleHandler {
+	return &ReportScheduleHandler{svc: svc}
+}
+
+func (h *ReportScheduleHandler) List(c *gin.Context) {
+	schedules, err := h.svc.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportSchedules(schedules))
+}
+
+func (h *ReportScheduleHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	schedule, err := h.svc.Get(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportSchedule(*schedule))
+}
+
+type reportScheduleInput struct {
+	Name       string                     `json:"name" binding:"required"`
+	Cron       string                     `json:"cron" binding:"required"`
+	Report     string                     `json:"report" binding:"required"`
+	Filters    services.ReportViewFilters `json:"filters"`
+	Columns    []string                   `json:"columns"`
+	Sort       string                     `json:"sort"`
+	Format     string                     `json:"format" binding:"required"`
+	Recipients []string                   `json:"recipients" binding:"required"`
+	Active     *bool                      `json:"active"`
+}
+
+func (in reportScheduleInput) toService() services.ReportScheduleInput {
+	return services.ReportScheduleInput{
+		Name:       in.Name,
+		Cron:       in.Cron,
+		Report:     in.Report,
+		Filters:    in.Filters,
+		Columns:    in.Columns,
+		Sort:       in.Sort,
+		Format:     in.Format,
+		Recipients: in.Recipients,
+		Active:     in.Active,
+	}
+}
+
+func (h *ReportScheduleHandler) Create(c *gin.Context) {
+	var in reportScheduleInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	userID, _ := middlewares.UserID(c)
+	schedule, err := h.svc.Create(userID, in.toService())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewReportSchedule(*schedule))
+}
+
+func (h *ReportScheduleHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var in reportScheduleInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	userID, _ := middlewares.UserID(c)
+	schedule, err := h.svc.Update(id, userID, in.toService())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportSchedule(*schedule))
+}
+
+func (h *ReportScheduleHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Assinatura removida"})
+}
+
+// Run executa a assinatura agora; a execução (inclusive a que falhou ao
+// gerar o relatório) vai para o histórico.
+func (h *ReportScheduleHandler) Run(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	run, err := h.svc.RunNow(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, run)
+}
+
+func (h *ReportScheduleHandler) Runs(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	limit, offset, err := pagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	runs, total, err := h.svc.Runs(id, limit, offset)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setTotalHeader(c, total)
+	c.JSON(http.StatusOK, runs)
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// ReportViewHandler expõe as visões salvas dos relatórios.
+type ReportViewHandler struct {
+	svc *services.ReportViewService
+}
+
+func NewReportViewHandler(svc *services.ReportViewService) *ReportViewHandler {
+	return &ReportViewHandler{svc: svc}
+}
+
+// Columns devolve o catálogo de colunas selecionáveis por relatório.
+func (h *ReportViewHandler) Columns(c *gin.Context) {
+	c.JSON(http.StatusOK, services.ReportViewColumns())
+}
+
+func (h *ReportViewHandler) List(c *gin.Context) {
+	actor := actorFrom(c)
+	views, err := h.svc.List(actor.UserID, c.Query("report"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportViews(views, actor.UserID))
+}
+
+func (h *ReportViewHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	actor := actorFrom(c)
+	view, err := h.svc.Get(id, actor)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportView(*view, actor.UserID))
+}
+
+type reportViewInput struct {
+	Name    string                     `json:"name" binding:"required"`
+	Report  string                     `json:"report" binding:"required"`
+	Filters services.ReportViewFilters `json:"filters"`
+	Columns []string                   `json:"columns"`
+	Sort    string                     `json:"sort"`
+	Shared  bool                       `json:"shared"`
+}
+
+func (in reportViewInput) toService() services.ReportViewInput {
+	return services.ReportViewInput{
+		Name:    in.Name,
+		Report:  in.Report,
+		Filters: in.Filters,
+		Columns: in.Columns,
+		Sort:    in.Sort,
+		Shared:  in.Shared,
+	}
+}
+
+func (h *ReportViewHandler) Create(c *gin.Context) {
+	var in reportViewInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	actor := actorFrom(c)
+	view, err := h.svc.Create(in.toService(), actor)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewReportView(*view, actor.UserID))
+}
+
+func (h *ReportViewHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var in reportViewInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	actor := actorFrom(c)
+	view, err := h.svc.Update(id, in.toService(), actor)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewReportView(*view, actor.UserID))
+}
+
+func (h *ReportViewHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Run executa a visão: em JSON, as colunas escolhidas e as linhas como
+// objetos; com format=csv ou xlsx, a tabela como arquivo. semester_id
+// substitui o semestre salvo.
+func (h *ReportViewHandler) Run(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	format, err := exportFormat(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	page, err := listPage(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	actor := actorFrom(c)
+	view, table, info, err := h.svc.Run(id, actor, services.ReportViewRun{
+		SemesterID: c.Query("semester_id"),
+		Scope:      middlewares.CourseScope(c),
+		Page:       page,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if format != "" {
+		respondExport(c, "visao-"+strconv.FormatUint(uint64(view.ID), 10), format, table)
+		return
+	}
+
+	columns := make([]services.ViewColumn, len(table.Keys))
+	for i, k := range table.Keys {
+		columns[i] = services.ViewColumn{Key: k, Label: table.Columns[i]}
+	}
+	setPageHeaders(c, info)
+	c.JSON(http.StatusOK, gin.H{
+		"view":    dto.NewReportView(*view, actor.UserID),
+		"columns": columns,
+		"rows":    table.Objects(),
+	})
+}
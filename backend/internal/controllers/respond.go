@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"bytes"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"adamanagement/backend/internal/middlewares"
 	"adamanagement/backend/internal/services"
 )
 
@@ -27,6 +29,9 @@ func respondError(c *gin.Context, err error) {
 		status = http.StatusNotFound
 	case errors.Is(err, services.ErrConflict):
 		status = http.StatusConflict
+	case errors.Is(err, services.ErrTooMany):
+		middlewares.AbortTooMany(c, err)
+		return
 	default:
 		slog.Error("erro interno", "error", err, "method", c.Request.Method, "path", c.FullPath())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
@@ -100,9 +105,71 @@ func pagination(c *gin.Context) (limit, offset int, err error) {
 	return limit, offset, nil
 }
 
+// listPage lê a paginação das listagens ordenáveis: limit, offset ou
+// cursor, e count=true para pedir o total (X-Total-Count).
+func listPage(c *gin.Context) (services.Page, error) {
+	limit, offset, err := pagination(c)
+	if err != nil {
+		return services.Page{}, err
+	}
+	return services.Page{
+		Limit:  limit,
+		Offset: offset,
+		Cursor: c.Query("cursor"),
+		Count:  c.Query("count") == "true",
+	}, nil
+}
+
+// setPageHeaders expõe o total (se pedido) e o cursor da próxima página.
+func setPageHeaders(c *gin.Context, info services.PageInfo) {
+	setTotalHeader(c, info.Total)
+	if info.NextCursor != "" {
+		c.Header("X-Next-Cursor", info.NextCursor)
+	}
+}
+
 // setTotalHeader expõe o total de linhas quando a consulta foi paginada.
 func setTotalHeader(c *gin.Context, total int64) {
 	if total >= 0 {
 		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 	}
 }
+
+// exportFormat lê o parâmetro opcional format (csv ou xlsx); vazio
+// mantém a resposta em JSON.
+func exportFormat(c *gin.Context) (string, error) {
+	format := c.Query("format")
+	if format != "" && !services.ValidExportFormat(format) {
+		return "", services.Invalid("format inválido: use csv ou xlsx")
+	}
+	return format, nil
+}
+
+// respondExport envia a tabela como arquivo para download, com o nome
+// informado e a extensão do formato.
+func respondExport(c *gin.Context, name, format string, t services.Table) {
+	var buf bytes.Buffer
+	if err := t.Write(&buf, format); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+	c.Data(http.StatusOK, services.ExportContentType(format), buf.Bytes())
+}
+
+// mineFilter atende ao filtro "meus alunos" (?mine=true): devolve o
+// usuário autenticado como orientador, ou 0 sem o filtro.
+func mineFilter(c *gin.Context) uint {
+	if c.Query("mine") != "true" {
+		return 0
+	}
+	id, _ := middlewares.UserID(c)
+	return id
+}
+
+// actorFrom identifica o requisitante para a trilha de auditoria.
+func actorFrom(c *gin.Context) services.Actor {
+	userID, _ := middlewares.UserID(c)
+	studentID, _ := middlewares.StudentID(c)
+	return services.Actor{UserID: userID, StudentID: studentID, Role: middlewares.Role(c), IP: c.ClientIP()}
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// RiskHandler expõe o risco de evasão: pesos dos fatores e a lista dos
+// maiores riscos do semestre.
+type RiskHandler struct {
+	svc *services.RiskService
+}
+
+func NewRiskHandler(svc *services.RiskService) *RiskHandler { return &RiskHandler{svc: svc} }
+
+func (h *RiskHandler) Weights(c *gin.Context) {
+	weights, err := h.svc.Weights()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, weights)
+}
+
+// UpdateWeights grava os pesos e recalcula o escore de todos os registros.
+func (h *RiskHandler) UpdateWeights(c *gin.Context) {
+	var in services.RiskWeights
+	if !bindJSON(c, &in) {
+		return
+	}
+	weights, err := h.svc.UpdateWeights(in, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, weights)
+}
+
+// Top devolve os alunos de maior risco no semestre (limit, padrão 10),
+// com a contribuição de cada fator.
+func (h *RiskHandler) Top(c *gin.Context) {
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+	entries, err := h.svc.Top(c.Query("semester_id"), n, middlewares.CourseScope(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/models"
+	"adamanagement/backend/internal/services"
+)
+
+// RoleHandler expõe a gestão dos papéis configuráveis e o catálogo de
+// permissões.
+type RoleHandler struct {
+	svc *services.RoleService
+}
+
+func NewRoleHandler(svc *services.RoleService) *RoleHandler { return &RoleHandler{svc: svc} }
+
+// Permissions devolve o catálogo de permissões atribuíveis.
+func (h *RoleHandler) Permissions(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Permissions)
+}
+
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.svc.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+type roleInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func (h *RoleHandler) Create(c *gin.Context) {
+	var in roleInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	role, err := h.svc.Create(services.RoleInput{
+		Name:        in.Name,
+		Description: in.Description,
+		Permissions: in.Permissions,
+	}, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// Update troca descrição e permissões; o nome do papel não muda.
+func (h *RoleHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var in roleInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	role, err := h.svc.Update(id, services.RoleInput{
+		Description: in.Description,
+		Permissions: in.Permissions,
+	}, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Papel removido"})
+}
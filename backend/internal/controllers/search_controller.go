@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+type SearchHandler struct {
+	svc *services.SearchService
+}
+
+func NewSearchHandler(svc *services.SearchService) *SearchHandler { return &SearchHandler{svc: svc} }
+
+// Search é a busca global: q em alunos, cursos e disciplinas, do mais ao
+// menos relevante (limit, padrão 20).
+func (h *SearchHandler) Search(c *gin.Context) {
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+	hits, err := h.svc.Search(c.Query("q"), middlewares.CourseScope(c), n)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// SessionHandler renova e encerra sessões de staff e alunos.
+type SessionHandler struct {
+	svc *services.SessionService
+}
+
+func NewSessionHandler(svc *services.SessionService) *SessionHandler {
+	return &SessionHandler{svc: svc}
+}
+
+// tokenResponse é o corpo comum de login e renovação. "token" mantém o
+// nome já usado pelo frontend para o token de acesso.
+func tokenResponse(pair *services.TokenPair) gin.H {
+	return gin.H{
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+	}
+}
+
+type refreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh troca o refresh token por um novo par; o token apresentado deixa
+// de valer.
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	var in refreshInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	pair, err := h.svc.Refresh(in.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// Logout encerra a sessão do token usado na requisição.
+func (h *SessionHandler) Logout(c *gin.Context) {
+	claims, ok := middlewares.CurrentClaims(c)
+	if !ok {
+		respondError(c, services.Unauthorized("sessão inválida"))
+		return
+	}
+	if err := h.svc.Logout(claims); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada"})
+}
+
+// LogoutAll encerra todas as sessões do requisitante, em qualquer
+// dispositivo — inclusive a atual.
+func (h *SessionHandler) LogoutAll(c *gin.Context) {
+	claims, ok := middlewares.CurrentClaims(c)
+	if !ok {
+		respondError(c, services.Unauthorized("sessão inválida"))
+		return
+	}
+	if err := h.svc.LogoutAll(claims); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Todas as sessões foram encerradas"})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"adamanagement/backend/internal/models"
 	"adamanagement/backend/internal/services"
 )
 
@@ -46,19 +47,69 @@ func (h *StudentAuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, student, err := h.svc.Login(in.Registration, in.Password)
+	pair, student, err := h.svc.Login(in.Registration, in.Password, actorFrom(c))
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":           student.ID,
-			"registration": student.Registration,
-			"name":         student.Name,
-			"role":         "student",
-		},
-	})
+	c.JSON(http.StatusOK, studentLoginResponse(pair, student))
+}
+
+// studentLoginResponse é o corpo do login do aluno, por senha ou SSO.
+func studentLoginResponse(pair *services.TokenPair, student *models.Student) gin.H {
+	resp := tokenResponse(pair)
+	resp["user"] = gin.H{
+		"id":           student.ID,
+		"registration": student.Registration,
+		"name":         student.Name,
+		"role":         models.RoleStudent,
+	}
+	return resp
+}
+
+type resetIssueInput struct {
+	Delivery string `json:"delivery"`
+}
+
+// IssueReset gera o código de redefinição de senha do aluno (coordenação).
+// Na entrega impressa, o código vem na resposta — única vez em que é
+// exibido.
+func (h *StudentAuthHandler) IssueReset(c *gin.Context) {
+	var in resetIssueInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	reset, err := h.svc.IssueResetCode(c.Param("registration"), in.Delivery, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp := gin.H{"delivery": reset.Delivery, "expires_at": reset.ExpiresAt}
+	if reset.Code != "" {
+		resp["code"] = reset.Code
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+type resetRedeemInput struct {
+	Registration string `json:"registration" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	Password     string `json:"password" binding:"required,min=6"`
+}
+
+// RedeemReset é a rota pública em que o aluno troca o código por uma nova
+// senha.
+func (h *StudentAuthHandler) RedeemReset(c *gin.Context) {
+	var in resetRedeemInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	if err := h.svc.RedeemResetCode(in.Registration, in.Code, in.Password, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Senha redefinida. Faça login com sua matrícula e a nova senha."})
 }
@@ -25,7 +25,47 @@ func (h *StudentHandler) History(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"student": dto.NewStudent(*student),
-		"history": dto.NewAcademicRecords(records),
+		"student":    dto.NewStudent(*student),
+		"history":    dto.NewAcademicRecords(records),
+		"projection": services.ProjectGraduation(*student, records),
 	})
 }
+
+// Timeline devolve o acompanhamento do aluno em todos os semestres, em
+// ordem cronológica (ações, mudanças de enquadramento e planos enviados).
+func (h *StudentHandler) Timeline(c *gin.Context) {
+	limit, offset, err := pagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, total, err := h.svc.Timeline(c.Param("registration"), limit, offset)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setTotalHeader(c, total)
+	c.JSON(http.StatusOK, entries)
+}
+
+type studentContactInput struct {
+	Email string `json:"email"`
+}
+
+// UpdateContact grava o e-mail de contato do aluno (o próprio aluno ou a
+// coordenação); vazio remove o contato.
+func (h *StudentHandler) UpdateContact(c *gin.Context) {
+	var in studentContactInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	student, err := h.svc.UpdateContact(c.Param("registration"), in.Email)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewStudent(*student))
+}
@@ -58,3 +58,23 @@ func (h *StudyPlanHandler) Update(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, dto.NewStudyPlan(*plan))
 }
+
+type studyPlanReturnInput struct {
+	SemesterID uint   `json:"semester_id" binding:"required"`
+	Note       string `json:"note" binding:"required"`
+}
+
+// Return devolve o plano ao aluno para ajustes (coordenação).
+func (h *StudyPlanHandler) Return(c *gin.Context) {
+	var in studyPlanReturnInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	plan, err := h.svc.Return(c.Param("registration"), in.SemesterID, in.Note)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewStudyPlan(*plan))
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/services"
+)
+
+// TriageRuleHandler expõe as versões dos limiares de triagem.
+type TriageRuleHandler struct {
+	svc *services.TriageRuleService
+}
+
+func NewTriageRuleHandler(svc *services.TriageRuleService) *TriageRuleHandler {
+	return &TriageRuleHandler{svc: svc}
+}
+
+// courseIDQuery lê o parâmetro opcional course_id (ausente: regra global).
+func courseIDQuery(c *gin.Context) (*uint, error) {
+	n, err := intQuery(c, "course_id")
+	if err != nil || n == nil {
+		return nil, err
+	}
+	if *n <= 0 {
+		return nil, services.Invalid("course_id inválido")
+	}
+	id := uint(*n)
+	return &id, nil
+}
+
+func (h *TriageRuleHandler) List(c *gin.Context) {
+	courseID, err := courseIDQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	rules, err := h.svc.List(courseID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTriageRules(rules))
+}
+
+type triageRuleInput struct {
+	CourseID                 *uint  `json:"course_id"`
+	EffectiveFrom            string `json:"effective_from" binding:"required"`
+	MaxLocks                 *int   `json:"max_locks" binding:"required"`
+	MaxSemestersNoHours      *int   `json:"max_semesters_no_hours" binding:"required"`
+	NearGraduationMaxPending *int   `json:"near_graduation_max_pending" binding:"required"`
+}
+
+// Create cadastra uma nova versão da regra, global ou do curso.
+func (h *TriageRuleHandler) Create(c *gin.Context) {
+	var in triageRuleInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	rule, err := h.svc.Create(services.TriageRuleInput{
+		CourseID:      in.CourseID,
+		EffectiveFrom: in.EffectiveFrom,
+		TriageThresholds: services.TriageThresholds{
+			MaxLocks:                 *in.MaxLocks,
+			MaxSemestersNoHours:      *in.MaxSemestersNoHours,
+			NearGraduationMaxPending: *in.NearGraduationMaxPending,
+		},
+	}, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewTriageRule(*rule))
+}
+
+func (h *TriageRuleHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Versão da regra removida"})
+}
+
+// Effective devolve os limiares vigentes no semestre (código) para o
+// curso informado ou, sem course_id, os da regra global.
+func (h *TriageRuleHandler) Effective(c *gin.Context) {
+	courseID, err := courseIDQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	thresholds, err := h.svc.Effective(courseID, c.Query("semester"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, thresholds)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+// TwoFactorHandler expõe a configuração da verificação em duas etapas do
+// próprio usuário (/me/2fa) e, ao administrador, a política e o reset de
+// outros usuários.
+type TwoFactorHandler struct {
+	svc *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(svc *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{svc: svc}
+}
+
+type twoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	id, _ := middlewares.UserID(c)
+	status, err := h.svc.Status(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Setup gera o segredo e o URI otpauth:// (QR code); a verificação só
+// passa a valer depois de Enable.
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	id, _ := middlewares.UserID(c)
+	setup, err := h.svc.Setup(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var in twoFactorCodeInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	id, _ := middlewares.UserID(c)
+	codes, err := h.svc.Enable(id, in.Code, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var in twoFactorCodeInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	id, _ := middlewares.UserID(c)
+	if err := h.svc.Disable(id, in.Code, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verificação em duas etapas desativada"})
+}
+
+func (h *TwoFactorHandler) RecoveryCodes(c *gin.Context) {
+	var in twoFactorCodeInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	id, _ := middlewares.UserID(c)
+	codes, err := h.svc.RegenerateRecoveryCodes(id, in.Code, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Reset remove a verificação de outro usuário (administrador).
+func (h *TwoFactorHandler) Reset(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Reset(id, actorFrom(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verificação em duas etapas removida"})
+}
+
+func (h *TwoFactorHandler) Policy(c *gin.Context) {
+	policy, err := h.svc.Policy()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *TwoFactorHandler) UpdatePolicy(c *gin.Context) {
+	var in services.SecurityPolicy
+	if !bindJSON(c, &in) {
+		return
+	}
+	policy, err := h.svc.UpdatePolicy(in, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
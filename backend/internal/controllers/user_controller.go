@@ -11,21 +11,34 @@ import (
 )
 
 type UserHandler struct {
-	svc *services.UserService
+	svc    *services.UserService
+	scopes *services.ScopeService
 }
 
-func NewUserHandler(svc *services.UserService) *UserHandler { return &UserHandler{svc: svc} }
+func NewUserHandler(svc *services.UserService, scopes *services.ScopeService) *UserHandler {
+	return &UserHandler{svc: svc, scopes: scopes}
+}
 
 func (h *UserHandler) List(c *gin.Context) {
-	users, err := h.svc.List(services.UserListFilter{
-		Name:  c.Query("name"),
-		Email: c.Query("email"),
-		Role:  c.Query("role"),
+	page, err := listPage(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	users, info, err := h.svc.List(services.UserListFilter{
+		Name:   c.Query("name"),
+		Email:  c.Query("email"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+		Sort:   c.Query("sort"),
+		Page:   page,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
+
+	setPageHeaders(c, info)
 	c.JSON(http.StatusOK, dto.NewUsers(users))
 }
 
@@ -67,21 +80,55 @@ func (h *UserHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Usuário atualizado", "user": dto.NewUser(*user)})
 }
 
-func (h *UserHandler) Delete(c *gin.Context) {
+// Deactivate desativa o usuário (não há exclusão): ele deixa de entrar e
+// perde as sessões, mas continua referenciado no histórico.
+func (h *UserHandler) Deactivate(c *gin.Context) {
 	targetID, ok := parseIDParam(c)
 	if !ok {
 		return
 	}
+	user, err := h.svc.Deactivate(targetID, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário desativado", "user": dto.NewUser(*user)})
+}
 
-	requesterID, ok := middlewares.UserID(c)
+func (h *UserHandler) Reactivate(c *gin.Context) {
+	targetID, ok := parseIDParam(c)
 	if !ok {
-		respondError(c, services.Unauthorized("sessão inválida"))
 		return
 	}
+	user, err := h.svc.Reactivate(targetID, actorFrom(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário reativado", "user": dto.NewUser(*user)})
+}
+
+type userCoursesInput struct {
+	CourseIDs []uint `json:"course_ids"`
+}
 
-	if err := h.svc.Delete(requesterID, targetID); err != nil {
+// SetCourses define os cursos sob coordenação do usuário (lista vazia
+// retira todos). Papéis com courses.all não são afetados.
+func (h *UserHandler) SetCourses(c *gin.Context) {
+	targetID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var in userCoursesInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	user, err := h.scopes.SetCourses(targetID, in.CourseIDs, actorFrom(c))
+	if err != nil {
 		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Usuário deletado"})
+	c.JSON(http.StatusOK, dto.NewUser(*user))
 }
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/controllers/dto"
+	"adamanagement/backend/internal/middlewares"
+	"adamanagement/backend/internal/services"
+)
+
+type WebhookHandler struct {
+	svc *services.WebhookService
+}
+
+func NewWebhookHandler(svc *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	hooks, err := h.svc.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewWebhooks(hooks))
+}
+
+type webhookCreateInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"required"`
+	Active *bool    `json:"active"`
+}
+
+// Create cadastra o webhook e devolve o segredo de assinatura — única vez
+// em que ele é exibido.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var in webhookCreateInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	userID, _ := middlewares.UserID(c)
+	hook, err := h.svc.Create(userID, services.WebhookInput{
+		URL:    in.URL,
+		Secret: in.Secret,
+		Events: in.Events,
+		Active: in.Active,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"webhook": dto.NewWebhook(*hook), "secret": hook.Secret})
+}
+
+type webhookUpdateInput struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var in webhookUpdateInput
+	if !bindJSON(c, &in) {
+		return
+	}
+
+	hook, err := h.svc.Update(id, services.WebhookInput{
+		URL:    in.URL,
+		Secret: in.Secret,
+		Events: in.Events,
+		Active: in.Active,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewWebhook(*hook))
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook removido"})
+}
+
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	limit, offset, err := pagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	deliveries, total, err := h.svc.Deliveries(id, limit, offset)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setTotalHeader(c, total)
+	c.JSON(http.StatusOK, dto.NewWebhookDeliveries(deliveries))
+}
+
+// Redeliver agenda o reenvio de uma entrega (qualquer situação).
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.svc.Redeliver(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, dto.NewWebhookDelivery(*delivery))
+}
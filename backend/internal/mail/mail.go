@@ -0,0 +1,41 @@
+// Package mail envia as mensagens de e-mail do sistema. Os services não
+// enviam diretamente: gravam a mensagem já renderizada na outbox, e o
+// despachante em segundo plano a entrega por um Sender.
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Message é um e-mail pronto para envio, com corpo em texto e em HTML e,
+// opcionalmente, arquivos anexos.
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Attachment é um arquivo anexado à mensagem.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Sender entrega uma mensagem. Erros são tratados como falha transitória
+// pelo despachante, que agenda nova tentativa.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender apenas registra a mensagem no log. É o Sender usado quando
+// não há SMTP configurado (desenvolvimento), para que a outbox não acumule.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	slog.Info("e-mail (SMTP não configurado)", "to", msg.To, "subject", msg.Subject)
+	return nil
+}
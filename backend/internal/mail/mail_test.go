@@ -0,0 +1,175 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderAllTemplates(t *testing.T) {
+	data := map[string]string{
+		"StudentName":  "Aluna <Teste>",
+		"SemesterCode": "2025/2",
+		"ActionDate":   "10/03/2026",
+		"Description":  "Reunião de acompanhamento",
+		"Period1":      "2026/1",
+		"Period2":      "2026/2",
+		"ClosesAt":     "20/03/2026 18:00",
+		"Note":         "Inclua Cálculo I",
+		"Code":         "ABCD-2345",
+		"ExpiresAt":    "21/03/2026 18:00",
+		"Name":         "Aluna <Teste>",
+		"Link":         "https://ada.ufes.br/ativar?token=abc",
+		"Rows":         "12",
+	}
+
+	for name := range textTemplates {
+		t.Run(name, func(t *testing.T) {
+			msg, err := Render(name, data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+				t.Errorf("assunto inválido: %q", msg.Subject)
+			}
+			if !strings.Contains(msg.Text, "Aluna <Teste>") {
+				t.Errorf("corpo em texto sem o nome: %q", msg.Text)
+			}
+			// O HTML escapa o conteúdo vindo dos dados.
+			if !strings.Contains(msg.HTML, "Aluna &lt;Teste&gt;") {
+				t.Errorf("corpo HTML não escapou o nome: %q", msg.HTML)
+			}
+		})
+	}
+
+	if _, err := Render("inexistente", data); err == nil {
+		t.Error("template inexistente deveria dar erro")
+	}
+}
+
+// fakeSMTP é um servidor SMTP mínimo que aceita uma mensagem e a devolve
+// pelo canal — suficiente para exercitar o SMTPSender sem rede externa.
+func fakeSMTP(t *testing.T) (port int, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 fake ESMTP")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					ch <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case cmd == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSMTPSenderDeliversMultipartMessage(t *testing.T) {
+	port, received := fakeSMTP(t)
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, From: "ada@ufes.br"})
+
+	err := sender.Send(context.Background(), Message{
+		To:      "aluno@ufes.br",
+		Subject: "Ação de acompanhamento",
+		Text:    "Olá",
+		HTML:    "<p>Olá</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	raw := <-received
+	for _, want := range []string{
+		"To: aluno@ufes.br",
+		"Subject: =?utf-8?q?",
+		"multipart/alternative",
+		"text/plain; charset=UTF-8",
+		"text/html; charset=UTF-8",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("mensagem não contém %q:\n%s", want, raw)
+		}
+	}
+}
+
+func TestSMTPSenderAttachment(t *testing.T) {
+	port, received := fakeSMTP(t)
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, From: "ada@ufes.br"})
+
+	err := sender.Send(context.Background(), Message{
+		To:          "chefia@ufes.br",
+		Subject:     "Relatório agendado",
+		Text:        "Segue em anexo",
+		Attachments: []Attachment{{Name: "criticos.csv", ContentType: "text/csv", Data: []byte("Matrícula;Aluno\n")}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	raw := <-received
+	for _, want := range []string{
+		"multipart/mixed",
+		"multipart/alternative",
+		"Content-Disposition: attachment; filename=criticos.csv",
+		"Content-Transfer-Encoding: base64",
+		base64.StdEncoding.EncodeToString([]byte("Matrícula;Aluno\n")),
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("mensagem não contém %q:\n%s", want, raw)
+		}
+	}
+}
+
+func TestSMTPSenderConnectionError(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, From: "ada@ufes.br"})
+	if err := sender.Send(context.Background(), Message{To: "x@ufes.br"}); err == nil {
+		t.Fatal("envio para porta fechada deveria falhar (porta " + strconv.Itoa(port) + ")")
+	}
+}
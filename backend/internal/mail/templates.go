@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Cada e-mail tem dois arquivos em templates/: <nome>.txt, com os blocos
+// "subject" e "body" em texto puro, e <nome>.html, com o bloco "content"
+// inserido no leiaute comum (layout.html).
+//
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	textTemplates = map[string]*texttemplate.Template{}
+	htmlTemplates = map[string]*htmltemplate.Template{}
+)
+
+func init() {
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		file := e.Name()
+		name := strings.TrimSuffix(file, path.Ext(file))
+		switch {
+		case file == "layout.html":
+		case path.Ext(file) == ".txt":
+			textTemplates[name] = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+file))
+		case path.Ext(file) == ".html":
+			htmlTemplates[name] = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+file))
+		}
+	}
+}
+
+// Render monta assunto e corpos do e-mail name a partir de data. O
+// destinatário fica a cargo de quem chama.
+func Render(name string, data any) (Message, error) {
+	text, html := textTemplates[name], htmlTemplates[name]
+	if text == nil || html == nil {
+		return Message{}, fmt.Errorf("template de e-mail %q inexistente", name)
+	}
+
+	var subject, body, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -15,11 +17,20 @@ const (
 	ctxStudentID    = "studentID"
 	ctxRegistration = "registration"
 	ctxRole         = "role"
+	ctxClaims       = "claims"
 )
 
-// Auth valida o token JWT (somente HS256) e publica a identidade do
-// requisitante no contexto com tipos definidos — leia com UserID e Role.
-func Auth(jwtSecret string) gin.HandlerFunc {
+// SessionValidator confirma, no servidor, que a sessão do token continua
+// válida (não encerrada, conta existente, senha não trocada depois da
+// emissão). Implementado por services.SessionService.
+type SessionValidator interface {
+	Validate(claims *services.Claims) error
+}
+
+// Auth valida o token JWT (somente HS256) e a sessão a que ele pertence, e
+// publica a identidade do requisitante no contexto com tipos definidos —
+// leia com UserID e Role.
+func Auth(jwtSecret string, sessions SessionValidator) gin.HandlerFunc {
 	secret := []byte(jwtSecret)
 
 	return func(c *gin.Context) {
@@ -43,15 +54,74 @@ func Auth(jwtSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido ou expirado"})
 			return
 		}
+		if err := sessions.Validate(claims); err != nil {
+			abortAuth(c, "validação de sessão", err)
+			return
+		}
 
-		c.Set(ctxUserID, claims.UserID)
-		c.Set(ctxStudentID, claims.StudentID)
-		c.Set(ctxRegistration, claims.Registration)
-		c.Set(ctxRole, claims.Role)
+		publish(c, claims)
+		c.Next()
+	}
+}
+
+// HeaderAPIKey é o cabeçalho em que as integrações enviam a chave de API.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKeyAuthenticator valida uma chave de API e devolve as claims da
+// integração. Implementado por services.APIKeyService.
+type APIKeyAuthenticator interface {
+	Authenticate(raw string) (*services.Claims, error)
+}
+
+// AuthOrAPIKey é o Auth das rotas que também aceitam chave de API: com o
+// cabeçalho X-API-Key, a chave substitui o token; sem ele, vale o Auth
+// comum. A chave só passa pelas rotas protegidas com RequireAPIScope.
+func AuthOrAPIKey(jwtSecret string, sessions SessionValidator, keys APIKeyAuthenticator) gin.HandlerFunc {
+	auth := Auth(jwtSecret, sessions)
+
+	return func(c *gin.Context) {
+		raw := c.GetHeader(HeaderAPIKey)
+		if raw == "" {
+			auth(c)
+			return
+		}
+		claims, err := keys.Authenticate(raw)
+		if err != nil {
+			abortAuth(c, "validação de chave de API", err)
+			return
+		}
+		publish(c, claims)
 		c.Next()
 	}
 }
 
+func publish(c *gin.Context, claims *services.Claims) {
+	c.Set(ctxClaims, claims)
+	c.Set(ctxUserID, claims.UserID)
+	c.Set(ctxStudentID, claims.StudentID)
+	c.Set(ctxRegistration, claims.Registration)
+	c.Set(ctxRole, claims.Role)
+}
+
+func abortAuth(c *gin.Context, what string, err error) {
+	if errors.Is(err, services.ErrUnauthorized) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	slog.Error(what, "error", err)
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
+}
+
+// CurrentClaims devolve as claims do token autenticado.
+func CurrentClaims(c *gin.Context) (*services.Claims, bool) {
+	v, ok := c.Get(ctxClaims)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*services.Claims)
+	return claims, ok
+}
+
 // UserID devolve o identificador do usuário (staff) autenticado.
 func UserID(c *gin.Context) (uint, bool) {
 	v, ok := c.Get(ctxUserID)
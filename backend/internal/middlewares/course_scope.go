@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/services"
+)
+
+const ctxScope = "courseScope"
+
+// ScopeResolver resolve os cursos visíveis ao usuário da coordenação e
+// confere se um aluno pertence a eles. Implementado por
+// services.ScopeService.
+type ScopeResolver interface {
+	Resolve(claims *services.Claims) (services.CourseScope, error)
+	CheckStudent(scope services.CourseScope, registration string) error
+}
+
+// ScopeCourses publica no contexto o escopo de cursos do requisitante
+// (leia com CourseScope) e, em rotas com :registration, recusa alunos de
+// cursos fora dele. Deve ser aplicado após Auth, em rotas de staff.
+func ScopeCourses(scopes ScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if resolveScope(c, scopes) {
+			c.Next()
+		}
+	}
+}
+
+// CourseScope devolve o escopo publicado por ScopeCourses. Sem ele, nada
+// é visível.
+func CourseScope(c *gin.Context) services.CourseScope {
+	if v, ok := c.Get(ctxScope); ok {
+		if scope, ok := v.(services.CourseScope); ok {
+			return scope
+		}
+	}
+	return services.RestrictTo(nil)
+}
+
+func resolveScope(c *gin.Context, scopes ScopeResolver) bool {
+	claims, ok := CurrentClaims(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token não fornecido"})
+		return false
+	}
+	scope, err := scopes.Resolve(claims)
+	if err == nil {
+		if registration := c.Param("registration"); registration != "" {
+			err = scopes.CheckStudent(scope, registration)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return false
+		}
+		slog.Error("escopo de cursos", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
+		return false
+	}
+	c.Set(ctxScope, scope)
+	return true
+}
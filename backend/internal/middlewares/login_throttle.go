@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/services"
+)
+
+// AttemptLimiter guarda contadores de falhas por chave. Implementado por
+// services.LoginGuard.
+type AttemptLimiter interface {
+	Check(key string) error
+	Fail(key string, actor services.Actor) error
+}
+
+// ThrottleIP limita, por IP de origem, as rotas públicas que conferem
+// credenciais: recusa com 429 enquanto o IP estiver bloqueado e conta como
+// falha toda resposta 401 do handler. O limite por conta fica nos services.
+func ThrottleIP(limiter AttemptLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := services.IPKey(c.ClientIP())
+		if err := limiter.Check(key); err != nil {
+			if errors.Is(err, services.ErrTooMany) {
+				AbortTooMany(c, err)
+				return
+			}
+			slog.Error("limite de tentativas", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if err := limiter.Fail(key, services.Actor{IP: c.ClientIP()}); err != nil {
+				slog.Error("registro de falha de login", "error", err)
+			}
+		}
+	}
+}
+
+// AbortTooMany responde 429 com Retry-After (em segundos, arredondado
+// para cima) quando o erro informa a espera.
+func AbortTooMany(c *gin.Context, err error) {
+	if wait, ok := services.RetryAfter(err); ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"adamanagement/backend/internal/services"
+)
+
+// fakeLimiter bloqueia as chaves marcadas e anota as falhas registradas.
+type fakeLimiter struct {
+	blocked map[string]bool
+	failed  []string
+}
+
+func (f *fakeLimiter) Check(key string) error {
+	if f.blocked[key] {
+		return services.TooMany("bloqueado", 90*time.Second)
+	}
+	return nil
+}
+
+func (f *fakeLimiter) Fail(key string, _ services.Actor) error {
+	f.failed = append(f.failed, key)
+	return nil
+}
+
+func TestThrottleIP(t *testing.T) {
+	limiter := &fakeLimiter{blocked: map[string]bool{}}
+	r := gin.New()
+	r.POST("/login", ThrottleIP(limiter), func(c *gin.Context) {
+		c.Status(http.StatusUnauthorized)
+	})
+
+	do := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "10.0.0.9:1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d; esperado 401", w.Code)
+	}
+	if len(limiter.failed) != 1 || limiter.failed[0] != "ip:10.0.0.9" {
+		t.Fatalf("401 deveria contar falha do IP; obtive %v", limiter.failed)
+	}
+
+	limiter.blocked["ip:10.0.0.9"] = true
+	w := do()
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "90" {
+		t.Fatalf("IP bloqueado: status=%d Retry-After=%q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if len(limiter.failed) != 1 {
+		t.Errorf("requisição recusada não deveria contar como falha; obtive %v", limiter.failed)
+	}
+}
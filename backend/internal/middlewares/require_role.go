@@ -1,22 +1,38 @@
 package middlewares
 
 import (
+	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 
 	"adamanagement/backend/internal/models"
 )
 
-// RequireAnyRole restringe o acesso aos papéis informados. Deve ser
-// aplicado após Auth, que publica o papel no contexto.
-func RequireAnyRole(roles ...string) gin.HandlerFunc {
-	allowed := make(map[string]struct{}, len(roles))
-	for _, r := range roles {
-		allowed[r] = struct{}{}
+// PermissionChecker informa se um papel concede uma permissão nomeada.
+// Implementado por services.RoleService.
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// RequirePermission restringe o acesso aos papéis que concedem a
+// permissão. Deve ser aplicado após Auth, que publica o papel no
+// contexto; o token de aluno nunca tem permissões nomeadas.
+func RequirePermission(perms PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowed(c, perms, permission) {
+			c.Next()
+		}
 	}
+}
+
+// RequireStaffAccount barra o token de aluno e a chave de API em rotas da
+// própria conta da coordenação (como /me/2fa), que não dependem de
+// permissão.
+func RequireStaffAccount() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if _, ok := allowed[Role(c)]; !ok {
+		if id, ok := UserID(c); !ok || id == 0 || Role(c) == models.RoleStudent {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso não autorizado para este perfil"})
 			return
 		}
@@ -24,27 +40,57 @@ func RequireAnyRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
-// RequireRole restringe o acesso a um único papel.
-func RequireRole(role string) gin.HandlerFunc {
-	return RequireAnyRole(role)
-}
-
-// RequireStaff libera apenas a coordenação (admin ou user), barrando o aluno.
-func RequireStaff() gin.HandlerFunc {
-	return RequireAnyRole(models.RoleAdmin, models.RoleUser)
-}
-
-// RequireSelfOrStaff protege rotas cujo recurso pertence a um aluno
+// RequireSelfOrPermission protege rotas cujo recurso pertence a um aluno
 // (identificado pela matrícula em :registration). O aluno só acessa a
-// própria matrícula (RN20); staff acessa qualquer uma.
-func RequireSelfOrStaff() gin.HandlerFunc {
+// própria matrícula (RN20); a coordenação precisa da permissão e acessa
+// apenas os alunos dos cursos do seu escopo (RN26), publicado no
+// contexto como em ScopeCourses.
+func RequireSelfOrPermission(perms PermissionChecker, scopes ScopeResolver, permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if Role(c) == models.RoleStudent {
 			if c.Param("registration") != Registration(c) {
 				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Você só pode acessar os seus próprios dados"})
 				return
 			}
+		} else if !allowed(c, perms, permission) || !resolveScope(c, scopes) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAPIScope libera a rota à chave de API que tenha o escopo e, para
+// tokens, exige a permissão como RequirePermission. Deve ser aplicado após
+// AuthOrAPIKey.
+func RequireAPIScope(perms PermissionChecker, permission, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if Role(c) != models.RoleAPIKey {
+			if allowed(c, perms, permission) {
+				c.Next()
+			}
+			return
+		}
+		if claims, ok := CurrentClaims(c); !ok || !slices.Contains(claims.Scopes, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Chave de API sem o escopo " + scope})
+			return
 		}
 		c.Next()
 	}
 }
+
+func allowed(c *gin.Context, perms PermissionChecker, permission string) bool {
+	role := Role(c)
+	ok := false
+	if role != models.RoleStudent {
+		var err error
+		if ok, err = perms.HasPermission(role, permission); err != nil {
+			slog.Error("checagem de permissão", "error", err, "permission", permission)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
+			return false
+		}
+	}
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso não autorizado para este perfil"})
+	}
+	return ok
+}
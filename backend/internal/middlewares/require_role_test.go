@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"adamanagement/backend/internal/models"
+	"adamanagement/backend/internal/services"
 )
 
 func init() { gin.SetMode(gin.TestMode) }
@@ -19,6 +20,7 @@ func runWith(role, tokenRegistration, paramRegistration string, mw gin.HandlerFu
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/x", nil)
 	c.Params = gin.Params{{Key: "registration", Value: paramRegistration}}
+	c.Set(ctxClaims, &services.Claims{Role: role, Registration: tokenRegistration})
 	c.Set(ctxRole, role)
 	c.Set(ctxRegistration, tokenRegistration)
 
@@ -29,8 +31,44 @@ func runWith(role, tokenRegistration, paramRegistration string, mw gin.HandlerFu
 	return w.Code
 }
 
-func TestRequireSelfOrStaff(t *testing.T) {
-	mw := RequireSelfOrStaff()
+// fakeScopes coloca o usuário "user" no curso 1; a matrícula 2022999 é
+// de outro curso.
+type fakeScopes struct{}
+
+func (fakeScopes) Resolve(claims *services.Claims) (services.CourseScope, error) {
+	if claims.Role == models.RoleAdmin {
+		return services.AllCourses(), nil
+	}
+	return services.RestrictTo([]uint{1}), nil
+}
+
+func (fakeScopes) CheckStudent(scope services.CourseScope, registration string) error {
+	course := uint(1)
+	if registration == "2022999" {
+		course = 2
+	}
+	if !scope.Covers(course) {
+		return services.Forbidden("fora do escopo")
+	}
+	return nil
+}
+
+// fakePerms concede reports.read a admin, user e viewer; só o admin tem
+// users.manage.
+type fakePerms struct{}
+
+func (fakePerms) HasPermission(role, permission string) (bool, error) {
+	switch permission {
+	case models.PermReportsRead:
+		return role == models.RoleAdmin || role == models.RoleUser || role == models.RoleViewer, nil
+	case models.PermUsersManage:
+		return role == models.RoleAdmin, nil
+	}
+	return false, nil
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	mw := RequireSelfOrPermission(fakePerms{}, fakeScopes{}, models.PermReportsRead)
 
 	cases := []struct {
 		name       string
@@ -41,8 +79,10 @@ func TestRequireSelfOrStaff(t *testing.T) {
 	}{
 		{"aluno na própria matrícula", models.RoleStudent, "2022001", "2022001", http.StatusOK},
 		{"aluno em matrícula alheia", models.RoleStudent, "2022001", "2022999", http.StatusForbidden},
-		{"staff (user) em qualquer aluno", models.RoleUser, "", "2022999", http.StatusOK},
+		{"staff (user) em aluno do seu curso", models.RoleUser, "", "2022001", http.StatusOK},
+		{"staff (user) em aluno de outro curso", models.RoleUser, "", "2022999", http.StatusForbidden},
 		{"staff (admin) em qualquer aluno", models.RoleAdmin, "", "2022999", http.StatusOK},
+		{"papel sem a permissão", "secretaria", "", "2022001", http.StatusForbidden},
 	}
 
 	for _, tc := range cases {
@@ -54,13 +94,45 @@ func TestRequireSelfOrStaff(t *testing.T) {
 	}
 }
 
-func TestRequireStaffBlocksStudent(t *testing.T) {
-	mw := RequireStaff()
+func TestRequireAPIScope(t *testing.T) {
+	mw := RequireAPIScope(fakePerms{}, models.PermReportsRead, models.ScopeReportsRecords)
+
+	run := func(claims *services.Claims) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/x", nil)
+		publish(c, claims)
+		mw(c)
+		if !c.IsAborted() {
+			c.Status(http.StatusOK)
+		}
+		return w.Code
+	}
+
+	if got := run(&services.Claims{Role: models.RoleAPIKey, Scopes: []string{models.ScopeReportsRecords}}); got != http.StatusOK {
+		t.Errorf("chave com o escopo deve passar; obtive %d", got)
+	}
+	if got := run(&services.Claims{Role: models.RoleAPIKey, Scopes: []string{models.ScopeReportsDashboard}}); got != http.StatusForbidden {
+		t.Errorf("chave sem o escopo deve dar 403; obtive %d", got)
+	}
+	if got := run(&services.Claims{UserID: 2, Role: models.RoleViewer}); got != http.StatusOK {
+		t.Errorf("token com a permissão deve passar; obtive %d", got)
+	}
+	if got := run(&services.Claims{StudentID: 3, Role: models.RoleStudent}); got != http.StatusForbidden {
+		t.Errorf("aluno deve dar 403; obtive %d", got)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	mw := RequirePermission(fakePerms{}, models.PermUsersManage)
 
 	if got := runWith(models.RoleStudent, "2022001", "2022001", mw); got != http.StatusForbidden {
 		t.Errorf("aluno em rota de staff deve dar 403; obtive %d", got)
 	}
-	if got := runWith(models.RoleUser, "", "", mw); got != http.StatusOK {
-		t.Errorf("staff deve passar; obtive %d", got)
+	if got := runWith(models.RoleUser, "", "", mw); got != http.StatusForbidden {
+		t.Errorf("papel sem a permissão deve dar 403; obtive %d", got)
+	}
+	if got := runWith(models.RoleAdmin, "", "", mw); got != http.StatusOK {
+		t.Errorf("papel com a permissão deve passar; obtive %d", got)
 	}
 }
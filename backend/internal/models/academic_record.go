@@ -18,4 +18,16 @@ type AcademicRecord struct {
 	PendingObligatory int    `json:"pending_obligatory"`
 	SemestersNoHours  int    `json:"semesters_no_hours"`
 	Locks             int    `json:"locks"` // trancamentos de matrícula (coluna NUM_TRANCAMENTOS)
+
+	// RiskScore é o risco de evasão (0 a 100) calculado a partir dos
+	// indicadores do registro e do histórico do aluno; recalculado a cada
+	// importação e a cada troca dos pesos (services.RiskService).
+	RiskScore float64 `json:"risk_score" gorm:"index"`
+
+	// Projeção de formatura no ritmo do aluno até este semestre:
+	// ExpectedGraduation é o código do semestre previsto ("" se não há
+	// como projetar) e ExceedsMaxDuration indica que ele passa do prazo
+	// máximo do curso. Recalculados junto com RiskScore.
+	ExpectedGraduation string `json:"expected_graduation" gorm:"size:20"`
+	ExceedsMaxDuration bool   `json:"exceeds_max_duration" gorm:"index"`
 }
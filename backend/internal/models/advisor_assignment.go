@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdvisorAssignment vincula um aluno a um orientador (usuário da
+// coordenação) em um semestre. A atribuição vigente do par (aluno,
+// semestre) é a que tem EndedAt nulo; trocas de orientador encerram a
+// anterior em vez de apagá-la, preservando o histórico. AssignedBy é
+// carregado só para exibir quem atribuiu (sem chave estrangeira).
+type AdvisorAssignment struct {
+	gorm.Model
+	StudentID  uint     `json:"student_id" gorm:"not null;index:idx_advisor_student_semester"`
+	Student    Student  `json:"student" gorm:"foreignKey:StudentID"`
+	SemesterID uint     `json:"semester_id" gorm:"not null;index:idx_advisor_student_semester"`
+	Semester   Semester `json:"semester" gorm:"foreignKey:SemesterID"`
+	AdvisorID  uint     `json:"advisor_id" gorm:"not null;index"`
+	Advisor    User     `json:"advisor" gorm:"foreignKey:AdvisorID"`
+
+	AssignedByUserID uint       `json:"assigned_by_user_id"`
+	AssignedBy       *User      `json:"assigned_by,omitempty" gorm:"foreignKey:AssignedByUserID;-:migration"`
+	EndedAt          *time.Time `json:"ended_at" gorm:"index"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleAPIKey identifica, nas claims, a requisição autenticada por chave de
+// API. Não é um papel da tabela roles e não tem permissões nomeadas.
+const RoleAPIKey = "api_key"
+
+// Escopos de uma chave de API. Cada escopo libera uma rota de leitura de
+// relatórios; nenhuma rota de escrita aceita chave.
+const (
+	ScopeReportsRecords   = "reports.records"   // GET /reports/records
+	ScopeReportsStudents  = "reports.students"  // GET /reports/students
+	ScopeReportsDashboard = "reports.dashboard" // GET /reports/dashboard, /trends, /breakdown e /risk
+	ScopeReportsCatalog   = "reports.catalog"   // GET /semesters e /reports/courses
+	ScopeReportsViews     = "reports.views"     // GET /report-views/:id/run (visões compartilhadas)
+)
+
+// APIKeyScopes é o catálogo de escopos atribuíveis a uma chave.
+var APIKeyScopes = []string{
+	ScopeReportsRecords, ScopeReportsStudents, ScopeReportsDashboard, ScopeReportsCatalog, ScopeReportsViews,
+}
+
+// APIKey é uma chave de integração emitida pelo administrador. Só o hash
+// SHA-256 da chave é guardado; Prefix (os primeiros caracteres) permite
+// reconhecê-la na listagem. Scopes guarda os escopos separados por
+// vírgula. Chaves revogadas são mantidas para consulta.
+type APIKey struct {
+	gorm.Model
+	Name            string     `json:"name" gorm:"size:100;not null"`
+	Prefix          string     `json:"prefix" gorm:"size:16;not null"`
+	KeyHash         string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes          string     `json:"scopes" gorm:"not null"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	RevokedAt       *time.Time `json:"revoked_at" gorm:"index"`
+	CreatedByUserID uint       `json:"created_by_user_id"`
+}
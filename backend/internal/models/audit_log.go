@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AuditLog registra operações sensíveis (quem, o quê, sobre quem, de onde).
+// É apenas inserida — nunca atualizada nem apagada pela aplicação —, por
+// isso dispensa gorm.Model.
+type AuditLog struct {
+	ID        uint      `json:"ID" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	Action         string `json:"action" gorm:"type:varchar(60);not null;index"`
+	ActorUserID    *uint  `json:"actor_user_id" gorm:"index"`
+	ActorStudentID *uint  `json:"actor_student_id"`
+	Target         string `json:"target" gorm:"type:varchar(120);index"` // ex.: "student:2022001"
+	Detail         string `json:"detail" gorm:"type:varchar(500)"`
+	IP             string `json:"ip" gorm:"type:varchar(64)"`
+}
@@ -6,5 +6,10 @@ type Course struct {
 	gorm.Model
 	Code        int    `json:"code" gorm:"uniqueIndex"`
 	Name        string `json:"name"`
+	SearchName  string `json:"-"` // nome normalizado por Fold
 	Coordinator string `json:"coordinator"`
+
+	// MaxDurationSemesters é o prazo máximo de integralização do curso,
+	// em semestres (0: não configurado). Base da projeção de formatura.
+	MaxDurationSemesters int `json:"max_duration_semesters"`
 }
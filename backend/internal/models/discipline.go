@@ -4,6 +4,7 @@ import "gorm.io/gorm"
 
 type Discipline struct {
 	gorm.Model
-	Code string `json:"code" gorm:"uniqueIndex"`
-	Name string `json:"name"`
+	Code       string `json:"code" gorm:"uniqueIndex"`
+	Name       string `json:"name"`
+	SearchName string `json:"-"` // nome normalizado por Fold
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LoginThrottle é o contador de falhas de autenticação de uma chave: uma
+// conta ("staff:<email>", "student:<matrícula>") ou um IP ("ip:<endereço>").
+// Fica no próprio PostgreSQL para valer entre réplicas sem depender de um
+// armazenamento extra.
+type LoginThrottle struct {
+	ID        uint      `json:"ID" gorm:"primarykey"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Key           string     `json:"key" gorm:"type:varchar(120);not null;uniqueIndex"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at" gorm:"index"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
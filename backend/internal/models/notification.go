@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification é um aviso exibido na central de notificações do app. O
+// destinatário é um usuário da coordenação (UserID) ou um aluno
+// (StudentID) — exatamente um dos dois é preenchido.
+type Notification struct {
+	gorm.Model
+	UserID    *uint      `json:"user_id" gorm:"index"`
+	StudentID *uint      `json:"student_id" gorm:"index"`
+	Kind      string     `json:"kind" gorm:"type:varchar(40);not null"`
+	Title     string     `json:"title" gorm:"type:varchar(200);not null"`
+	Body      string     `json:"body" gorm:"type:varchar(500)"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OIDCLoginState guarda, entre o redirecionamento ao provedor de
+// identidade e o retorno, o que o login único precisa conferir: o state
+// (anti-CSRF), o nonce do ID token e o code_verifier do PKCE. BindingHash
+// prende o login ao navegador que o iniciou: o valor original fica só no
+// cookie desse navegador. Cada linha é consumida uma única vez.
+type OIDCLoginState struct {
+	ID        uint      `json:"ID" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	StateHash    string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Nonce        string    `json:"-" gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128);not null"`
+	BindingHash  string    `json:"-" gorm:"type:varchar(64);not null;default:''"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+}
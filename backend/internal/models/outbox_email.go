@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Situações de uma mensagem da outbox.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// OutboxEmail é um e-mail já renderizado, gravado na mesma transação do
+// evento de domínio que o originou (outbox transacional). O despachante em
+// segundo plano o entrega e registra as tentativas; sem o commit do
+// evento, nenhuma mensagem é enviada.
+type OutboxEmail struct {
+	gorm.Model
+	Event     string `json:"event" gorm:"index"`
+	Recipient string `json:"recipient" gorm:"not null"`
+	Subject   string `json:"subject"`
+	TextBody  string `json:"text_body" gorm:"type:text"`
+	HTMLBody  string `json:"html_body" gorm:"type:text"`
+
+	// Anexo opcional (entrega agendada de relatórios), gravado uma única
+	// vez e compartilhado pelas mensagens da mesma entrega.
+	AttachmentID *uint             `json:"attachment_id" gorm:"index"`
+	Attachment   *OutboxAttachment `json:"attachment,omitempty"`
+
+	Status        string     `json:"status" gorm:"index;not null;default:'pending'"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+}
+
+// OutboxAttachment é o arquivo anexado às mensagens de uma entrega. Um
+// relatório enviado a vários destinatários ocupa uma linha, referenciada
+// por todas as mensagens. O conteúdo não é serializado na API.
+type OutboxAttachment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	ContentType string    `json:"content_type"`
+	Data        []byte    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Canais de entrega do código de redefinição de senha.
+const (
+	ResetDeliveryPrint = "print"
+	ResetDeliveryEmail = "email"
+)
+
+// PasswordResetCode é um código de uso único, gerado pela coordenação, que
+// permite ao aluno definir uma nova senha. Só o hash é gravado; o código em
+// claro é exibido (ou enviado por e-mail) uma única vez.
+type PasswordResetCode struct {
+	gorm.Model
+	StudentID       uint   `json:"student_id" gorm:"not null;index"`
+	CodeHash        string `json:"-" gorm:"type:varchar(64);not null"`
+	Delivery        string `json:"delivery" gorm:"type:varchar(10);not null"`
+	CreatedByUserID uint   `json:"created_by_user_id"`
+
+	ExpiresAt time.Time  `json:"expires_at"`
+	Attempts  int        `json:"attempts"`
+	UsedAt    *time.Time `json:"used_at"`
+
+	// InvalidatedAt é preenchido quando o código deixa de valer sem uso:
+	// novo código emitido ou tentativas esgotadas.
+	InvalidatedAt *time.Time `json:"invalidated_at"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // PlanRound é a rodada de cadastro de planos de integralização aberta pela
 // coordenação. Mira dois períodos futuros (escolhidos pelo coordenador) e
@@ -23,4 +27,13 @@ type PlanRound struct {
 
 	Open           bool `json:"open" gorm:"index"`
 	OpenedByUserID uint `json:"opened_by_user_id"`
+	// OpenedBy é carregado para exibir o nome de quem abriu; sem chave
+	// estrangeira, para a migração não validar as rodadas já gravadas.
+	OpenedBy *User `json:"opened_by,omitempty" gorm:"foreignKey:OpenedByUserID;-:migration"`
+
+	// ClosesAt é o prazo informado aos alunos (opcional). O encerramento
+	// continua manual; o prazo alimenta o lembrete enviado 48h antes,
+	// registrado em ClosingReminderSentAt para não se repetir.
+	ClosesAt              *time.Time `json:"closes_at"`
+	ClosingReminderSentAt *time.Time `json:"closing_reminder_sent_at"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken é um token de renovação de sessão. Só o hash SHA-256 é
+// gravado; o valor em claro é entregue uma única vez ao cliente. Os tokens
+// de uma mesma sessão compartilham FamilyID: a cada renovação o token
+// apresentado é revogado e substituído por outro (rotação), e reapresentar
+// um token já revogado encerra a sessão inteira.
+type RefreshToken struct {
+	gorm.Model
+	UserID    *uint  `json:"user_id" gorm:"index"`
+	StudentID *uint  `json:"student_id" gorm:"index"`
+	FamilyID  string `json:"family_id" gorm:"type:varchar(64);not null;index"`
+	TokenHash string `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"index"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportSchedule é uma assinatura de entrega periódica de relatório por
+// e-mail, cadastrada pelo administrador. Cron segue o formato de cinco
+// campos; Filters, Columns e Sort têm o mesmo formato das visões salvas
+// (sem semester_id, vale o semestre mais recente com dados). Recipients
+// guarda os e-mails separados por vírgula. NextRunAt é persistido para que
+// a agenda sobreviva a reinícios: uma execução perdida com o servidor
+// parado é feita assim que ele volta. User, o responsável, é carregado só
+// para exibir o nome (sem chave estrangeira).
+type ReportSchedule struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	User       *User      `json:"user,omitempty" gorm:"-:migration"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Cron       string     `json:"cron" gorm:"size:100;not null"`
+	Report     string     `json:"report" gorm:"size:20;not null"`
+	Filters    string     `json:"filters" gorm:"type:text"`
+	Columns    string     `json:"columns"`
+	Sort       string     `json:"sort" gorm:"size:100"`
+	Format     string     `json:"format" gorm:"size:10;not null"`
+	Recipients string     `json:"recipients" gorm:"type:text;not null"`
+	Active     bool       `json:"active" gorm:"index"`
+	NextRunAt  *time.Time `json:"next_run_at" gorm:"index"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+}
+
+// Situações de uma execução agendada.
+const (
+	ScheduleRunSuccess = "success"
+	ScheduleRunFailed  = "failed"
+)
+
+// Origens de uma execução.
+const (
+	ScheduleTriggerCron   = "schedule"
+	ScheduleTriggerManual = "manual"
+)
+
+// ReportScheduleRun é o histórico de execuções de uma assinatura: o
+// semestre usado, quantas linhas o relatório teve e para quantos
+// destinatários foi enfileirado; Error explica a falha.
+type ReportScheduleRun struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	ScheduleID   uint      `json:"schedule_id" gorm:"index;not null"`
+	Trigger      string    `json:"trigger" gorm:"size:20"`
+	Status       string    `json:"status" gorm:"size:20;index"`
+	SemesterCode string    `json:"semester_code" gorm:"size:20"`
+	Rows         int       `json:"rows"`
+	Recipients   int       `json:"recipients"`
+	Error        string    `json:"error" gorm:"type:text"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
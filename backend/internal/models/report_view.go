@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// ReportView é uma visão salva de relatório: os filtros (JSON no mesmo
+// formato dos parâmetros da rota), as colunas escolhidas (separadas por
+// vírgula; vazio: todas) e a ordenação. Só o dono edita; compartilhada,
+// qualquer usuário com reports.read a vê e executa.
+type ReportView struct {
+	gorm.Model
+	UserID  uint   `json:"user_id" gorm:"index;not null"`
+	User    *User  `json:"user,omitempty"`
+	Name    string `json:"name" gorm:"size:100;not null"`
+	Report  string `json:"report" gorm:"size:20;not null"`
+	Filters string `json:"filters" gorm:"type:text"`
+	Columns string `json:"columns"`
+	Sort    string `json:"sort" gorm:"size:100"`
+	Shared  bool   `json:"shared" gorm:"index"`
+}
@@ -1,9 +1,53 @@
 package models
 
-// Papéis de acesso. Staff (coordenação) usa Admin/User na tabela users;
-// Student identifica o token do aluno autenticado contra a tabela students.
+// Papéis de acesso. Os papéis da coordenação ficam na tabela roles (ver
+// Role) e são referenciados por nome em users.role; Admin, User e Viewer
+// são semeados na inicialização. Student identifica o token do aluno
+// autenticado contra a tabela students e não tem permissões nomeadas.
 const (
 	RoleAdmin   = "admin"
 	RoleUser    = "user"
+	RoleViewer  = "viewer"
 	RoleStudent = "student"
 )
+
+// Permissões nomeadas, atribuídas aos papéis e exigidas pelas rotas
+// (middlewares.RequirePermission).
+const (
+	PermReportsRead       = "reports.read"       // relatórios, indicadores, históricos e rodadas (leitura)
+	PermCoursesAll        = "courses.all"        // enxerga todos os cursos, sem vínculo em user_courses
+	PermActionsWrite      = "actions.write"      // ações de acompanhamento
+	PermPlansManage       = "plans.manage"       // planos de integralização em nome do aluno e devolução
+	PermRoundsManage      = "rounds.manage"      // abrir, fechar, reabrir e apagar rodadas
+	PermAdvisorsManage    = "advisors.manage"    // atribuição de orientadores
+	PermStudentsManage    = "students.manage"    // contato, códigos de redefinição e desbloqueio de login
+	PermDisciplinesManage = "disciplines.manage" // catálogo de disciplinas
+	PermImportUpload      = "import.upload"      // importação de planilhas
+	PermUsersManage       = "users.manage"       // usuários, papéis, cursos e verificação em duas etapas de terceiros
+	PermAuditRead         = "audit.read"         // trilha de auditoria
+	PermSettingsManage    = "settings.manage"    // política de segurança, regras de triagem, pesos de risco, entregas agendadas, webhooks e chaves de API
+)
+
+// Permissions é o catálogo completo, na ordem exibida ao administrador.
+var Permissions = []string{
+	PermReportsRead, PermCoursesAll, PermActionsWrite, PermPlansManage,
+	PermRoundsManage, PermAdvisorsManage, PermStudentsManage, PermDisciplinesManage,
+	PermImportUpload, PermUsersManage, PermAuditRead, PermSettingsManage,
+}
+
+// Role é um papel configurável da coordenação. System marca o papel admin,
+// que sempre tem todas as permissões e não pode ser alterado nem removido.
+type Role struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name" gorm:"size:40;uniqueIndex;not null"`
+	Description string           `json:"description"`
+	System      bool             `json:"system"`
+	Permissions []RolePermission `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// RolePermission concede uma permissão nomeada a um papel.
+type RolePermission struct {
+	ID         uint   `gorm:"primaryKey"`
+	RoleID     uint   `gorm:"not null;uniqueIndex:idx_role_permission"`
+	Permission string `gorm:"size:60;not null;uniqueIndex:idx_role_permission"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// Fold normaliza um texto para busca: minúsculas, sem acentos e com os
+// espaços colapsados — "  JOÃO  da Silva" vira "joao da silva". As colunas
+// SearchName guardam o nome já normalizado, e o termo buscado passa pela
+// mesma função, de modo que a comparação não depende de collation nem de
+// extensões do banco.
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// Os ganchos mantêm SearchName em dia a cada Create/Save. Atualizações
+// por mapa (Updates) precisam incluir a coluna explicitamente.
+
+func (s *Student) BeforeSave(*gorm.DB) error {
+	s.SearchName = Fold(s.Name)
+	return nil
+}
+
+func (c *Course) BeforeSave(*gorm.DB) error {
+	c.SearchName = Fold(c.Name)
+	return nil
+}
+
+func (d *Discipline) BeforeSave(*gorm.DB) error {
+	d.SearchName = Fold(d.Name)
+	return nil
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Setting guarda uma configuração do sistema editável pelo administrador
+// (chave → valor textual), como a política de verificação em duas etapas.
+type Setting struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(80)"`
+	Value     string    `json:"value" gorm:"type:text;not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,19 +1,33 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Student struct {
 	gorm.Model
 	Registration string `json:"registration" gorm:"uniqueIndex"`
 	Name         string `json:"name"`
-	EntryYear    int    `json:"entry_year"`
-	EntryPeriod  string `json:"entry_period"`
-	QuotaType    string `json:"quota_type"`
+	// SearchName é o nome normalizado por Fold, usado nas buscas.
+	SearchName  string `json:"-"`
+	EntryYear   int    `json:"entry_year"`
+	EntryPeriod string `json:"entry_period"`
+	QuotaType   string `json:"quota_type"`
+
+	// Email é o contato informado pelo próprio aluno (ou pela coordenação)
+	// para receber as notificações; não vem da planilha importada.
+	Email string `json:"email"`
 
 	// Password é o hash BCrypt definido pelo aluno no autocadastro
 	// (login = matrícula). Vazio enquanto o aluno não criou acesso.
 	Password string `json:"-"`
 
+	// PasswordChangedAt marca a última troca de senha: tokens de acesso
+	// emitidos antes dela deixam de ser aceitos.
+	PasswordChangedAt *time.Time `json:"-"`
+
 	CourseID uint   `json:"course_id"`
 	Course   Course `json:"course" gorm:"foreignKey:CourseID"`
 }
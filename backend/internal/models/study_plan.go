@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type StudyPlan struct {
 	gorm.Model
@@ -9,4 +13,9 @@ type StudyPlan struct {
 	SemesterID  uint         `json:"semester_id" gorm:"uniqueIndex:idx_plan_student_semester"`
 	Semester    Semester     `json:"semester" gorm:"foreignKey:SemesterID"`
 	Disciplines []Discipline `json:"disciplines" gorm:"many2many:study_plan_disciplines;"`
+
+	// ReturnedAt/ReturnNote marcam o plano devolvido pela coordenação para
+	// ajustes; são limpos quando o plano é editado novamente.
+	ReturnedAt *time.Time `json:"returned_at"`
+	ReturnNote string     `json:"return_note" gorm:"type:varchar(500)"`
 }
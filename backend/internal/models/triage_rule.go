@@ -0,0 +1,28 @@
+package models
+
+import "gorm.io/gorm"
+
+// TriageRule é uma versão dos limiares de triagem (RN02 e RN03), global
+// (CourseID nulo) ou de um curso, válida a partir do semestre
+// EffectiveFrom (código, ex.: "2025/1") até a versão seguinte do mesmo
+// alcance. A regra do curso prevalece sobre a global; sem nenhuma versão
+// aplicável valem os limiares padrão. As versões não são editadas: uma
+// mudança é cadastrada como nova versão.
+type TriageRule struct {
+	gorm.Model
+	CourseID      *uint   `json:"course_id" gorm:"index"`
+	Course        *Course `json:"course,omitempty" gorm:"foreignKey:CourseID"`
+	EffectiveFrom string  `json:"effective_from" gorm:"size:20;not null;index"`
+
+	// Crítico: "Em regularidade" com trancamentos acima de MaxLocks ou
+	// semestres sem carga horária acima de MaxSemestersNoHours.
+	MaxLocks            int `json:"max_locks"`
+	MaxSemestersNoHours int `json:"max_semesters_no_hours"`
+	// Próximo da formatura: "Em regularidade" com até
+	// NearGraduationMaxPending obrigatórias pendentes.
+	NearGraduationMaxPending int `json:"near_graduation_max_pending"`
+
+	CreatedByUserID uint `json:"created_by_user_id"`
+	// CreatedBy traz o nome do autor da versão (apenas leitura, sem FK).
+	CreatedBy *User `json:"created_by,omitempty" gorm:"foreignKey:CreatedByUserID;-:migration"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RecoveryCode é um código de recuperação da verificação em duas etapas:
+// substitui o código do autenticador uma única vez. Só o hash é gravado.
+type RecoveryCode struct {
+	ID        uint      `json:"ID" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// TwoFactorChallenge é um desafio do segundo passo do login emitido e
+// ainda não concluído, identificado pelo jti do JWT. Concluir o login
+// apaga a linha: o mesmo desafio não abre uma segunda sessão.
+type TwoFactorChallenge struct {
+	ID        string    `json:"-" gorm:"type:varchar(32);primarykey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
@@ -1,11 +1,40 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
 	Name     string `json:"name"`
 	Email    string `gorm:"uniqueIndex;not null" json:"email"`
 	Password string `gorm:"not null" json:"-"`
-	Role     string `gorm:"default:'user'" json:"role"` // 'admin' ou 'user'
+	Role     string `gorm:"default:'user'" json:"role"` // nome de um papel da tabela roles
+
+	// InvitationPending marca o usuário convidado que ainda não definiu a
+	// senha pelo link do convite (UserInvitation); até lá não faz login.
+	InvitationPending bool `json:"invitation_pending" gorm:"not null;default:false"`
+
+	// DeactivatedAt marca o usuário desativado: não faz login e perde as
+	// sessões, mas o registro é mantido para que rodadas, ações e a trilha
+	// de auditoria continuem apontando para ele.
+	DeactivatedAt *time.Time `json:"deactivated_at" gorm:"index"`
+
+	// PasswordChangedAt marca a última troca de senha: tokens de acesso
+	// emitidos antes dela deixam de ser aceitos.
+	PasswordChangedAt *time.Time `json:"-"`
+
+	// Verificação em duas etapas (TOTP). TOTPSecret é gravado na
+	// configuração e só passa a ser exigido no login quando TOTPEnabledAt
+	// é preenchido; TOTPLastStep impede reusar um código já aceito.
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	TOTPLastStep  int64      `json:"-"`
+
+	// Courses delimita os alunos visíveis ao usuário: relatórios, ações,
+	// planos e rodadas se restringem a esses cursos. Papéis com a
+	// permissão courses.all enxergam todos, independentemente da lista.
+	Courses []Course `json:"courses" gorm:"many2many:user_courses"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserInvitation é o convite de um usuário da coordenação. O usuário é
+// criado junto, com InvitationPending, e só entra no sistema depois de
+// definir a própria senha pelo link do convite. Só o hash do token é
+// gravado; reenviar gera um token novo e invalida o anterior.
+type UserInvitation struct {
+	gorm.Model
+	UserID          uint   `json:"user_id" gorm:"not null;index"`
+	Name            string `json:"name"`
+	Email           string `json:"email" gorm:"not null;index"`
+	Role            string `json:"role"`
+	TokenHash       string `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	CreatedByUserID uint   `json:"created_by_user_id"`
+
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentCount  int        `json:"sent_count"`
+	LastSentAt time.Time  `json:"last_sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
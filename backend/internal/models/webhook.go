@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook é uma assinatura de eventos de domínio cadastrada pelo
+// administrador. Events guarda os tipos assinados separados por vírgula
+// (ex.: "round.opened,round.closed"). Secret assina o corpo das entregas
+// (HMAC-SHA256) e nunca é serializado.
+type Webhook struct {
+	gorm.Model
+	URL             string `json:"url" gorm:"not null"`
+	Secret          string `json:"-" gorm:"not null"`
+	Events          string `json:"events" gorm:"not null"`
+	Active          bool   `json:"active" gorm:"index"`
+	CreatedByUserID uint   `json:"created_by_user_id"`
+}
+
+// Situações de uma entrega de webhook.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery é uma entrega de evento a um webhook, gravada na mesma
+// transação do evento (mesmo padrão da outbox de e-mails) e mantida como
+// registro das tentativas. Um reenvio manual gera uma nova entrega que
+// aponta a original em RedeliveryOfID.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID uint   `json:"webhook_id" gorm:"not null;index"`
+	Event     string `json:"event" gorm:"index"`
+	Payload   string `json:"payload" gorm:"type:text"`
+
+	Status         string     `json:"status" gorm:"index;not null;default:'pending'"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	RedeliveryOfID *uint      `json:"redelivery_of_id"`
+}
@@ -0,0 +1,239 @@
+// Package oidctest é um provedor OpenID Connect mínimo para testes e
+// desenvolvimento local do login único: descoberta, JWKS, autorização com
+// PKCE (S256) e token endpoint emitindo ID tokens RS256. Não faz
+// autenticação de verdade — a identidade é a informada no formulário.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-1"
+
+// Provider atende as rotas do provedor. Issuer deve ser a URL base em que
+// ele está servindo (definida depois de subir o servidor nos testes).
+type Provider struct {
+	Issuer string
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// grant é uma autorização emitida e ainda não trocada no token endpoint.
+type grant struct {
+	claims      map[string]any
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+func New() (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{key: key, grants: map[string]grant{}}, nil
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                p.Issuer,
+			"authorization_endpoint":                p.Issuer + "/authorize",
+			"token_endpoint":                        p.Issuer + "/token",
+			"jwks_uri":                              p.Issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"code_challenge_methods_supported":      []string{"S256"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	case "/jwks":
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}}})
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var loginForm = template.Must(template.New("login").Parse(`<!doctype html>
+<meta charset="utf-8"><title>Provedor OIDC de teste</title>
+<h1>Provedor OIDC de teste</h1>
+<form method="get" action="/authorize">
+{{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">
+{{end}}<p><label>E-mail <input name="email"></label></p>
+<p><label>Matrícula <input name="registration"></label></p>
+<p><label>Nome <input name="name"></label></p>
+<button>Entrar</button>
+</form>`))
+
+// authorize mostra o formulário de identidade ou, já preenchido,
+// redireciona ao cliente com o code.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("email") == "" && q.Get("registration") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = loginForm.Execute(w, q)
+		return
+	}
+
+	claims := map[string]any{"email_verified": true}
+	for _, k := range []string{"email", "registration", "name"} {
+		if v := q.Get(k); v != "" {
+			claims[k] = v
+		}
+	}
+	redirect, err := p.Authorize(r.URL.String(), claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// Authorize processa uma URL de autorização gerada pelo cliente como se o
+// usuário tivesse se identificado com claims, e devolve a URL de retorno
+// (redirect_uri com code e state). Usado diretamente pelos testes.
+func (p *Provider) Authorize(authURL string, claims map[string]any) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if q.Get("response_type") != "code" {
+		return "", errors.New("response_type deve ser code")
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		return "", errors.New("PKCE S256 obrigatório")
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		return "", errors.New("redirect_uri inválida")
+	}
+
+	code := randomHex()
+	p.mu.Lock()
+	p.grants[code] = grant{
+		claims:      claims,
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	p.mu.Unlock()
+
+	back := redirect.Query()
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+	redirect.RawQuery = back.Encode()
+	return redirect.String(), nil
+}
+
+// token troca o code (uma única vez) pelo ID token, conferindo o
+// code_verifier contra o code_challenge da autorização.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(user)
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case clientID != g.clientID || r.PostForm.Get("redirect_uri") != g.redirectURI:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.Issuer,
+		"aud":   g.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce,
+	}
+	for k, v := range g.claims {
+		claims[k] = v
+	}
+	if _, ok := claims["sub"]; !ok {
+		claims["sub"] = fmt.Sprintf("mock|%v|%v", g.claims["email"], g.claims["registration"])
+	}
+	idToken, err := p.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomHex(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// Sign assina claims com a chave do provedor (RS256), para testes que
+// precisam de ID tokens fora do fluxo normal.
+func (p *Provider) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(p.key)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
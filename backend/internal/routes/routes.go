@@ -10,90 +10,270 @@ import (
 
 // Handlers agrupa os handlers HTTP montados pelo roteador.
 type Handlers struct {
-	Auth        *controllers.AuthHandler
-	StudentAuth *controllers.StudentAuthHandler
-	Users       *controllers.UserHandler
-	Import      *controllers.ImportHandler
-	Reports     *controllers.ReportHandler
-	Indicators  *controllers.IndicatorsHandler
-	Students    *controllers.StudentHandler
-	Actions     *controllers.ActionHandler
-	Disciplines *controllers.DisciplineHandler
-	Plans       *controllers.StudyPlanHandler
-	Rounds      *controllers.PlanRoundHandler
+	Auth            *controllers.AuthHandler
+	Sessions        *controllers.SessionHandler
+	StudentAuth     *controllers.StudentAuthHandler
+	Users           *controllers.UserHandler
+	Import          *controllers.ImportHandler
+	Reports         *controllers.ReportHandler
+	Indicators      *controllers.IndicatorsHandler
+	Students        *controllers.StudentHandler
+	Actions         *controllers.ActionHandler
+	Disciplines     *controllers.DisciplineHandler
+	Plans           *controllers.StudyPlanHandler
+	Rounds          *controllers.PlanRoundHandler
+	Webhooks        *controllers.WebhookHandler
+	Notifications   *controllers.NotificationHandler
+	Advisors        *controllers.AdvisorHandler
+	Audit           *controllers.AuditHandler
+	LoginLocks      *controllers.LoginLockHandler
+	TwoFactor       *controllers.TwoFactorHandler
+	OIDC            *controllers.OIDCHandler
+	Roles           *controllers.RoleHandler
+	APIKeys         *controllers.APIKeyHandler
+	Invitations     *controllers.InvitationHandler
+	TriageRules     *controllers.TriageRuleHandler
+	Risk            *controllers.RiskHandler
+	Courses         *controllers.CourseHandler
+	ReportViews     *controllers.ReportViewHandler
+	ReportSchedules *controllers.ReportScheduleHandler
+	Search          *controllers.SearchHandler
+}
+
+// Guards reúne as checagens que os middlewares consultam no servidor.
+type Guards struct {
+	// Sessions valida, a cada requisição autenticada, a sessão do token.
+	Sessions middlewares.SessionValidator
+	// Attempts limita por IP as rotas públicas que conferem credenciais.
+	Attempts middlewares.AttemptLimiter
+	// Scopes restringe a coordenação aos alunos dos seus cursos.
+	Scopes middlewares.ScopeResolver
+	// Permissions resolve as permissões nomeadas de cada papel.
+	Permissions middlewares.PermissionChecker
+	// APIKeys valida as chaves de integração aceitas nas rotas de leitura
+	// de relatórios.
+	APIKeys middlewares.APIKeyAuthenticator
 }
 
 // Register monta a API em /api/v1 e mantém /api como alias de
 // compatibilidade para clientes anteriores ao versionamento.
-func Register(r *gin.Engine, h Handlers, jwtSecret string) {
-	register(r.Group("/api"), h, jwtSecret)
-	register(r.Group("/api/v1"), h, jwtSecret)
+func Register(r *gin.Engine, h Handlers, jwtSecret string, g Guards) {
+	register(r.Group("/api"), h, jwtSecret, g)
+	register(r.Group("/api/v1"), h, jwtSecret, g)
 }
 
-func register(api *gin.RouterGroup, h Handlers, jwtSecret string) {
+func register(api *gin.RouterGroup, h Handlers, jwtSecret string, g Guards) {
+	auth := middlewares.Auth(jwtSecret, g.Sessions)
+	throttle := middlewares.ThrottleIP(g.Attempts)
+	scoped := middlewares.ScopeCourses(g.Scopes)
+	can := func(permission string) gin.HandlerFunc {
+		return middlewares.RequirePermission(g.Permissions, permission)
+	}
+	selfOr := func(permission string) gin.HandlerFunc {
+		return middlewares.RequireSelfOrPermission(g.Permissions, g.Scopes, permission)
+	}
+	readOrKey := func(scope string) gin.HandlerFunc {
+		return middlewares.RequireAPIScope(g.Permissions, models.PermReportsRead, scope)
+	}
+
 	// Público
-	api.POST("/login", h.Auth.Login)
+	api.POST("/login", throttle, h.Auth.Login)
+	api.POST("/login/2fa", throttle, h.Auth.VerifyTwoFactor)
+	api.POST("/login/2fa/enroll", throttle, h.Auth.EnrollTwoFactor)
+	api.POST("/refresh", h.Sessions.Refresh)
+	api.GET("/auth/providers", h.OIDC.Providers)
+	api.GET("/auth/oidc/authorize", h.OIDC.Authorize)
+	api.POST("/auth/oidc/callback", throttle, h.OIDC.Callback)
 	// Prefixo singular /student evita conflito de rota com /students/:registration.
+	api.POST("/activate", throttle, h.Invitations.Activate)
 	api.POST("/student/register", h.StudentAuth.Register)
-	api.POST("/student/login", h.StudentAuth.Login)
+	api.POST("/student/login", throttle, h.StudentAuth.Login)
+	api.POST("/student/password-reset", throttle, h.StudentAuth.RedeemReset)
+
+	// Leitura de relatórios: coordenação com reports.read ou integração
+	// com chave de API do escopo da rota (RN28)
+	keyed := api.Group("/")
+	keyed.Use(middlewares.AuthOrAPIKey(jwtSecret, g.Sessions, g.APIKeys))
+	{
+		keyed.GET("/semesters", readOrKey(models.ScopeReportsCatalog), scoped, h.Reports.Semesters)
+		keyed.GET("/reports/courses", readOrKey(models.ScopeReportsCatalog), scoped, h.Reports.Courses)
+		keyed.GET("/reports/records", readOrKey(models.ScopeReportsRecords), scoped, h.Reports.Records)
+		keyed.GET("/reports/students", readOrKey(models.ScopeReportsStudents), scoped, h.Reports.Students)
+		keyed.GET("/search", readOrKey(models.ScopeReportsStudents), scoped, h.Search.Search)
+		keyed.GET("/reports/dashboard", readOrKey(models.ScopeReportsDashboard), scoped, h.Indicators.Dashboard)
+		keyed.GET("/reports/trends", readOrKey(models.ScopeReportsDashboard), scoped, h.Indicators.Trends)
+		keyed.GET("/reports/breakdown", readOrKey(models.ScopeReportsDashboard), scoped, h.Indicators.Breakdown)
+		keyed.GET("/reports/risk", readOrKey(models.ScopeReportsDashboard), scoped, h.Risk.Top)
+		keyed.GET("/reports/cohorts", readOrKey(models.ScopeReportsDashboard), scoped, h.Indicators.Cohorts)
+		keyed.GET("/report-views/:id/run", readOrKey(models.ScopeReportsViews), scoped, h.ReportViews.Run)
+	}
 
 	protected := api.Group("/")
-	protected.Use(middlewares.Auth(jwtSecret))
+	protected.Use(auth)
 	{
 		// Qualquer autenticado (staff ou aluno)
 		protected.GET("/me", h.Auth.Me)
+		protected.POST("/logout", h.Sessions.Logout)
+		protected.POST("/logout/all", h.Sessions.LogoutAll)
 		protected.GET("/disciplines", h.Disciplines.List)
 		protected.GET("/rounds/current", h.Rounds.Current)
 
-		// Recurso do aluno: o próprio aluno (dono) ou a coordenação (RN20)
-		self := protected.Group("/")
-		self.Use(middlewares.RequireSelfOrStaff())
+		// Central de notificações do próprio requisitante (staff ou aluno)
+		protected.GET("/notifications", h.Notifications.List)
+		protected.GET("/notifications/stream", h.Notifications.Stream)
+		protected.PUT("/notifications/read-all", h.Notifications.MarkAllRead)
+		protected.PUT("/notifications/:id/read", h.Notifications.MarkRead)
+
+		// Recurso do aluno: o próprio aluno (dono) ou a coordenação com a
+		// permissão e o curso do aluno no escopo (RN20, RN26)
+		protected.GET("/students/:registration/history", selfOr(models.PermReportsRead), h.Students.History)
+		protected.GET("/students/:registration/rounds", selfOr(models.PermReportsRead), h.Rounds.StudentRounds)
+		protected.GET("/students/:registration/plan", selfOr(models.PermReportsRead), h.Plans.Get)
+		protected.POST("/students/:registration/plan", selfOr(models.PermPlansManage), h.Plans.Create)
+		protected.PUT("/students/:registration/plan", selfOr(models.PermPlansManage), h.Plans.Update)
+		protected.PUT("/students/:registration/contact", selfOr(models.PermStudentsManage), h.Students.UpdateContact)
+
+		// Conta própria da coordenação: verificação em duas etapas
+		account := protected.Group("/")
+		account.Use(middlewares.RequireStaffAccount())
+		{
+			account.GET("/me/2fa", h.TwoFactor.Status)
+			account.POST("/me/2fa/setup", h.TwoFactor.Setup)
+			account.POST("/me/2fa/enable", h.TwoFactor.Enable)
+			account.POST("/me/2fa/disable", h.TwoFactor.Disable)
+			account.POST("/me/2fa/recovery-codes", h.TwoFactor.RecoveryCodes)
+		}
+
+		// Consulta: relatórios, indicadores e acompanhamento, restritos
+		// aos cursos do escopo (RN26)
+		reports := protected.Group("/")
+		reports.Use(can(models.PermReportsRead), scoped)
+		{
+			reports.GET("/reports/caseload", h.Advisors.Caseload)
+			reports.GET("/triage-rules", h.TriageRules.List)
+			reports.GET("/triage-rules/effective", h.TriageRules.Effective)
+			reports.GET("/risk/weights", h.Risk.Weights)
+
+			reports.GET("/report-views", h.ReportViews.List)
+			reports.GET("/report-views/columns", h.ReportViews.Columns)
+			reports.GET("/report-views/:id", h.ReportViews.Get)
+			reports.POST("/report-views", h.ReportViews.Create)
+			reports.PUT("/report-views/:id", h.ReportViews.Update)
+			reports.DELETE("/report-views/:id", h.ReportViews.Delete)
+
+			reports.GET("/students/:registration/timeline", h.Students.Timeline)
+			reports.GET("/students/:registration/advisors", h.Advisors.History)
+			reports.GET("/students/:registration/actions", h.Actions.List)
+			reports.GET("/rounds", h.Rounds.List)
+			reports.GET("/rounds/students", h.Rounds.Cohort) // ?round_id=X → rodada + alunos do semestre-base
+		}
+
+		actions := protected.Group("/")
+		actions.Use(can(models.PermActionsWrite), scoped)
+		{
+			actions.POST("/students/:registration/actions", h.Actions.Create)
+			actions.PUT("/actions/:id", h.Actions.Update)
+			actions.DELETE("/actions/:id", h.Actions.Delete)
+		}
+
+		plans := protected.Group("/")
+		plans.Use(can(models.PermPlansManage), scoped)
+		{
+			plans.PUT("/students/:registration/plan/return", h.Plans.Return)
+		}
+
+		advisors := protected.Group("/")
+		advisors.Use(can(models.PermAdvisorsManage), scoped)
+		{
+			advisors.PUT("/students/:registration/advisor", h.Advisors.Assign)
+			advisors.DELETE("/students/:registration/advisor", h.Advisors.Unassign) // ?semester_id=X
+			advisors.POST("/advisors/bulk", h.Advisors.Bulk)
+		}
+
+		students := protected.Group("/")
+		students.Use(can(models.PermStudentsManage), scoped)
+		{
+			students.POST("/students/:registration/password-reset", h.StudentAuth.IssueReset)
+			students.GET("/login-locks", h.LoginLocks.List) // alunos do escopo
+			students.DELETE("/login-locks/:id", h.LoginLocks.Unlock)
+		}
+
+		disciplines := protected.Group("/")
+		disciplines.Use(can(models.PermDisciplinesManage))
 		{
-			self.GET("/students/:registration/history", h.Students.History)
-			self.GET("/students/:registration/rounds", h.Rounds.StudentRounds)
-			self.GET("/students/:registration/plan", h.Plans.Get)
-			self.POST("/students/:registration/plan", h.Plans.Create)
-			self.PUT("/students/:registration/plan", h.Plans.Update)
+			disciplines.POST("/disciplines", h.Disciplines.Create)
+			disciplines.PUT("/disciplines/:id", h.Disciplines.Update)
+			disciplines.DELETE("/disciplines/:id", h.Disciplines.Delete)
 		}
 
-		// Coordenação (admin ou user) — alunos não têm acesso
-		staff := protected.Group("/")
-		staff.Use(middlewares.RequireStaff())
+		rounds := protected.Group("/")
+		rounds.Use(can(models.PermRoundsManage))
 		{
-			staff.GET("/semesters", h.Reports.Semesters)
-			staff.GET("/reports/records", h.Reports.Records)
-			staff.GET("/reports/courses", h.Reports.Courses)
-			staff.GET("/reports/students", h.Reports.Students)
-			staff.GET("/reports/dashboard", h.Indicators.Dashboard)
-
-			staff.GET("/students/:registration/actions", h.Actions.List)
-			staff.POST("/students/:registration/actions", h.Actions.Create)
-			staff.PUT("/actions/:id", h.Actions.Update)
-			staff.DELETE("/actions/:id", h.Actions.Delete)
-
-			staff.POST("/disciplines", h.Disciplines.Create)
-			staff.PUT("/disciplines/:id", h.Disciplines.Update)
-			staff.DELETE("/disciplines/:id", h.Disciplines.Delete)
-
-			staff.GET("/rounds", h.Rounds.List)
-			staff.GET("/rounds/students", h.Rounds.Cohort) // ?round_id=X → rodada + alunos do semestre-base
-			staff.POST("/rounds", h.Rounds.Open)
-			staff.PUT("/rounds/:id/close", h.Rounds.Close)
-			staff.PUT("/rounds/:id/reopen", h.Rounds.Reopen)
-			staff.DELETE("/rounds/:id", h.Rounds.Delete)
+			rounds.POST("/rounds", h.Rounds.Open)
+			rounds.PUT("/rounds/:id/close", h.Rounds.Close)
+			rounds.PUT("/rounds/:id/reopen", h.Rounds.Reopen)
+			rounds.DELETE("/rounds/:id", h.Rounds.Delete)
 		}
 
-		// Perfil próprio: staff edita seu usuário; regra completa no service.
+		protected.POST("/upload", can(models.PermImportUpload), h.Import.Upload)
+		protected.GET("/audit-logs", can(models.PermAuditRead), h.Audit.List)
+
+		// Perfil próprio: qualquer staff edita seu usuário; alterar
+		// terceiros e papéis exige users.manage (regra no service).
 		protected.PUT("/users/:id", h.Users.Update)
 
-		// Exclusivo do administrador
-		admin := protected.Group("/")
-		admin.Use(middlewares.RequireRole(models.RoleAdmin))
+		users := protected.Group("/")
+		users.Use(can(models.PermUsersManage))
+		{
+			users.GET("/invitations", h.Invitations.List) // ?status=pending|accepted|revoked|expired|all
+			users.POST("/invitations", h.Invitations.Create)
+			users.POST("/invitations/:id/resend", h.Invitations.Resend)
+			users.DELETE("/invitations/:id", h.Invitations.Revoke)
+			users.GET("/users", h.Users.List)
+			users.PUT("/users/:id/deactivate", h.Users.Deactivate)
+			users.PUT("/users/:id/reactivate", h.Users.Reactivate)
+			users.PUT("/users/:id/courses", h.Users.SetCourses)
+			users.DELETE("/users/:id/2fa", h.TwoFactor.Reset)
+			users.GET("/login-locks/accounts", h.LoginLocks.ListAccounts) // coordenação e IPs
+			users.DELETE("/login-locks/accounts/:id", h.LoginLocks.UnlockAccount)
+
+			users.GET("/permissions", h.Roles.Permissions)
+			users.GET("/roles", h.Roles.List)
+			users.POST("/roles", h.Roles.Create)
+			users.PUT("/roles/:id", h.Roles.Update)
+			users.DELETE("/roles/:id", h.Roles.Delete)
+		}
+
+		settings := protected.Group("/")
+		settings.Use(can(models.PermSettingsManage))
 		{
-			admin.POST("/register", h.Auth.Register)
-			admin.POST("/upload", h.Import.Upload)
-			admin.GET("/users", h.Users.List)
-			admin.DELETE("/users/:id", h.Users.Delete)
+			settings.GET("/security/policy", h.TwoFactor.Policy)
+			settings.PUT("/security/policy", h.TwoFactor.UpdatePolicy)
+
+			settings.GET("/api-keys", h.APIKeys.List)
+			settings.GET("/api-keys/scopes", h.APIKeys.Scopes)
+			settings.POST("/api-keys", h.APIKeys.Create)
+			settings.DELETE("/api-keys/:id", h.APIKeys.Revoke)
+
+			settings.POST("/triage-rules", h.TriageRules.Create)
+			settings.DELETE("/triage-rules/:id", h.TriageRules.Delete)
+			settings.PUT("/risk/weights", h.Risk.UpdateWeights)
+			settings.PUT("/courses/:id/max-duration", h.Courses.SetMaxDuration)
+
+			settings.GET("/webhooks", h.Webhooks.List)
+			settings.POST("/webhooks", h.Webhooks.Create)
+			settings.PUT("/webhooks/:id", h.Webhooks.Update)
+			settings.DELETE("/webhooks/:id", h.Webhooks.Delete)
+			settings.GET("/webhooks/:id/deliveries", h.Webhooks.Deliveries)
+			settings.POST("/webhook-deliveries/:id/redeliver", h.Webhooks.Redeliver)
+
+			settings.GET("/report-schedules", h.ReportSchedules.List)
+			settings.POST("/report-schedules", h.ReportSchedules.Create)
+			settings.GET("/report-schedules/:id", h.ReportSchedules.Get)
+			settings.PUT("/report-schedules/:id", h.ReportSchedules.Update)
+			settings.DELETE("/report-schedules/:id", h.ReportSchedules.Delete)
+			settings.POST("/report-schedules/:id/run", h.ReportSchedules.Run)
+			settings.GET("/report-schedules/:id/runs", h.ReportSchedules.Runs)
 		}
 	}
 }
@@ -17,17 +17,34 @@ func TestRegisterNoRouteConflict(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	h := Handlers{
-		Auth:        controllers.NewAuthHandler(nil, nil),
-		StudentAuth: controllers.NewStudentAuthHandler(nil),
-		Users:       controllers.NewUserHandler(nil),
-		Import:      controllers.NewImportHandler(nil),
-		Reports:     controllers.NewReportHandler(nil),
-		Indicators:  controllers.NewIndicatorsHandler(nil),
-		Students:    controllers.NewStudentHandler(nil),
-		Actions:     controllers.NewActionHandler(nil),
-		Disciplines: controllers.NewDisciplineHandler(nil),
-		Plans:       controllers.NewStudyPlanHandler(nil),
-		Rounds:      controllers.NewPlanRoundHandler(nil),
+		Auth:            controllers.NewAuthHandler(nil, nil, nil),
+		Sessions:        controllers.NewSessionHandler(nil),
+		StudentAuth:     controllers.NewStudentAuthHandler(nil),
+		Users:           controllers.NewUserHandler(nil, nil),
+		Import:          controllers.NewImportHandler(nil),
+		Reports:         controllers.NewReportHandler(nil),
+		Indicators:      controllers.NewIndicatorsHandler(nil),
+		Students:        controllers.NewStudentHandler(nil),
+		Actions:         controllers.NewActionHandler(nil),
+		Disciplines:     controllers.NewDisciplineHandler(nil),
+		Plans:           controllers.NewStudyPlanHandler(nil),
+		Rounds:          controllers.NewPlanRoundHandler(nil),
+		Webhooks:        controllers.NewWebhookHandler(nil),
+		Notifications:   controllers.NewNotificationHandler(nil, nil),
+		Advisors:        controllers.NewAdvisorHandler(nil),
+		Audit:           controllers.NewAuditHandler(nil),
+		LoginLocks:      controllers.NewLoginLockHandler(nil),
+		TwoFactor:       controllers.NewTwoFactorHandler(nil),
+		OIDC:            controllers.NewOIDCHandler(nil, true),
+		Roles:           controllers.NewRoleHandler(nil),
+		APIKeys:         controllers.NewAPIKeyHandler(nil),
+		Invitations:     controllers.NewInvitationHandler(nil),
+		TriageRules:     controllers.NewTriageRuleHandler(nil),
+		Risk:            controllers.NewRiskHandler(nil),
+		Courses:         controllers.NewCourseHandler(nil),
+		ReportViews:     controllers.NewReportViewHandler(nil),
+		ReportSchedules: controllers.NewReportScheduleHandler(nil),
+		Search:          controllers.NewSearchHandler(nil),
 	}
 
 	defer func() {
@@ -36,5 +53,5 @@ func TestRegisterNoRouteConflict(t *testing.T) {
 		}
 	}()
 
-	Register(gin.New(), h, "test-secret")
+	Register(gin.New(), h, "test-secret", Guards{})
 }
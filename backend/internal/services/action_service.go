@@ -57,7 +57,8 @@ type ActionInput struct {
 }
 
 // Create registra uma ação de acompanhamento. Alunos em regularidade não
-// admitem novas ações (RN05).
+// admitem novas ações (RN05). Os avisos ao aluno (app e e-mail) e o evento
+// action.created são gravados na mesma transação.
 func (s *ActionService) Create(registration string, in ActionInput) (*models.StudentAction, error) {
 	if len(in.Description) > MaxActionDescription {
 		return nil, Invalid("Descrição deve ter no máximo 500 caracteres")
@@ -82,6 +83,11 @@ func (s *ActionService) Create(registration string, in ActionInput) (*models.Stu
 		return nil, Forbidden("Não é possível registrar ações para alunos em situação regular")
 	}
 
+	var semester models.Semester
+	if err := s.db.First(&semester, in.SemesterID).Error; err != nil {
+		return nil, err
+	}
+
 	action := models.StudentAction{
 		StudentID:    student.ID,
 		SemesterID:   in.SemesterID,
@@ -89,7 +95,31 @@ func (s *ActionService) Create(registration string, in ActionInput) (*models.Stu
 		Description:  in.Description,
 		ResponseDate: in.ResponseDate,
 	}
-	if err := s.db.Create(&action).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&action).Error; err != nil {
+			return err
+		}
+		if err := publishEvent(tx, EventActionCreated, map[string]any{
+			"action_id":    action.ID,
+			"registration": student.Registration,
+			"semester":     semester.Code,
+			"action_date":  action.ActionDate,
+			"description":  action.Description,
+		}); err != nil {
+			return err
+		}
+		if err := notify(tx, StudentRecipient(student.ID), NotifyActionCreated,
+			"Nova ação de acompanhamento registrada", action.Description); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, EmailActionCreated, student.Email, map[string]any{
+			"StudentName":  student.Name,
+			"SemesterCode": semester.Code,
+			"ActionDate":   action.ActionDate.Format(dateLayout),
+			"Description":  action.Description,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &action, nil
@@ -101,12 +131,9 @@ type ActionUpdateInput struct {
 	ResponseDate *time.Time
 }
 
-func (s *ActionService) Update(id uint, in ActionUpdateInput) (*models.StudentAction, error) {
-	var action models.StudentAction
-	if err := s.db.First(&action, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, NotFound("Ação não encontrada")
-		}
+func (s *ActionService) Update(id uint, in ActionUpdateInput, scope CourseScope) (*models.StudentAction, error) {
+	action, err := s.load(id, scope)
+	if err != nil {
 		return nil, err
 	}
 
@@ -127,19 +154,31 @@ func (s *ActionService) Update(id uint, in ActionUpdateInput) (*models.StudentAc
 		return nil, Invalid("Nenhum campo fornecido para atualização")
 	}
 
-	if err := s.db.Model(&action).Updates(updates).Error; err != nil {
+	if err := s.db.Model(action).Updates(updates).Error; err != nil {
 		return nil, err
 	}
-	return &action, nil
+	return action, nil
+}
+
+func (s *ActionService) Delete(id uint, scope CourseScope) error {
+	action, err := s.load(id, scope)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(action).Error
 }
 
-func (s *ActionService) Delete(id uint) error {
+// load busca a ação e recusa as de alunos fora do escopo de cursos.
+func (s *ActionService) load(id uint, scope CourseScope) (*models.StudentAction, error) {
 	var action models.StudentAction
 	if err := s.db.First(&action, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return NotFound("Ação não encontrada")
+			return nil, NotFound("Ação não encontrada")
 		}
-		return err
+		return nil, err
 	}
-	return s.db.Delete(&action).Error
+	if err := scope.checkStudent(s.db, action.StudentID); err != nil {
+		return nil, err
+	}
+	return &action, nil
 }
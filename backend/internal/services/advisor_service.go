@@ -0,0 +1,359 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+type AdvisorService struct {
+	db     *gorm.DB
+	perms  PermissionChecker
+	scopes *ScopeService
+}
+
+func NewAdvisorService(db *gorm.DB, perms PermissionChecker) *AdvisorService {
+	return &AdvisorService{db: db, perms: perms, scopes: NewScopeService(db, perms)}
+}
+
+// currentAdvisorJoin liga o registro acadêmico à atribuição vigente do
+// aluno no mesmo semestre. Reutilizado pelos filtros "meus alunos".
+const currentAdvisorJoin = "JOIN advisor_assignments ON advisor_assignments.student_id = academic_records.student_id" +
+	" AND advisor_assignments.semester_id = academic_records.semester_id" +
+	" AND advisor_assignments.ended_at IS NULL AND advisor_assignments.deleted_at IS NULL"
+
+// advisedBy restringe uma consulta sobre academic_records aos alunos
+// atribuídos ao orientador no semestre de cada registro.
+func advisedBy(q *gorm.DB, advisorID uint) *gorm.DB {
+	return q.Joins(currentAdvisorJoin).Where("advisor_assignments.advisor_id = ?", advisorID)
+}
+
+func (s *AdvisorService) findStudent(registration string) (*models.Student, error) {
+	var student models.Student
+	if err := s.db.Where("registration = ?", registration).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Aluno não encontrado")
+		}
+		return nil, err
+	}
+	return &student, nil
+}
+
+// findAdvisor garante que o orientador é um usuário ativo da coordenação,
+// com convite aceito e permissão para registrar ações de acompanhamento, e
+// devolve o escopo de cursos dele: só recebe alunos dos cursos que enxerga.
+func (s *AdvisorService) findAdvisor(id uint) (*models.User, CourseScope, error) {
+	var user models.User
+	if err := s.db.Omit("password").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, CourseScope{}, NotFound("Orientador não encontrado")
+		}
+		return nil, CourseScope{}, err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, CourseScope{}, Invalid("O orientador está desativado")
+	}
+	if user.InvitationPending {
+		return nil, CourseScope{}, Invalid("O orientador ainda não aceitou o convite")
+	}
+	allowed, err := s.perms.HasPermission(user.Role, models.PermActionsWrite)
+	if err != nil {
+		return nil, CourseScope{}, err
+	}
+	if !allowed {
+		return nil, CourseScope{}, Invalid("O papel do orientador não permite registrar ações de acompanhamento")
+	}
+	scope, err := s.scopes.Resolve(&Claims{UserID: user.ID, Role: user.Role})
+	if err != nil {
+		return nil, CourseScope{}, err
+	}
+	return &user, scope, nil
+}
+
+func (s *AdvisorService) findSemester(id uint) (*models.Semester, error) {
+	var semester models.Semester
+	if err := s.db.First(&semester, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Semestre não encontrado")
+		}
+		return nil, err
+	}
+	return &semester, nil
+}
+
+// Assign define o orientador do aluno no semestre. Uma atribuição vigente
+// a outro orientador é encerrada na mesma transação; atribuir de novo ao
+// mesmo orientador não altera nada.
+func (s *AdvisorService) Assign(registration string, semesterID, advisorID, byUserID uint) (*models.AdvisorAssignment, error) {
+	student, err := s.findStudent(registration)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findSemester(semesterID); err != nil {
+		return nil, err
+	}
+	_, advisorScope, err := s.findAdvisor(advisorID)
+	if err != nil {
+		return nil, err
+	}
+	if !advisorScope.Covers(student.CourseID) {
+		return nil, Invalid("O curso do aluno está fora do escopo do orientador")
+	}
+
+	var id uint
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		id, _, err = assignAdvisor(tx, student.ID, semesterID, advisorID, byUserID, time.Now())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return s.load(id)
+}
+
+// assignAdvisor encerra a atribuição vigente (se de outro orientador) e cria
+// a nova. Devolve o ID da atribuição vigente ao final e se ela foi criada
+// nesta chamada.
+func assignAdvisor(tx *gorm.DB, studentID, semesterID, advisorID, byUserID uint, now time.Time) (uint, bool, error) {
+	var current models.AdvisorAssignment
+	err := tx.Where("student_id = ? AND semester_id = ? AND ended_at IS NULL", studentID, semesterID).
+		First(&current).Error
+	switch {
+	case err == nil && current.AdvisorID == advisorID:
+		return current.ID, false, nil
+	case err == nil:
+		if err := tx.Model(&current).Update("ended_at", now).Error; err != nil {
+			return 0, false, err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, false, err
+	}
+
+	assignment := models.AdvisorAssignment{
+		StudentID:        studentID,
+		SemesterID:       semesterID,
+		AdvisorID:        advisorID,
+		AssignedByUserID: byUserID,
+	}
+	if err := tx.Create(&assignment).Error; err != nil {
+		return 0, false, err
+	}
+	return assignment.ID, true, nil
+}
+
+// Unassign encerra a atribuição vigente do aluno no semestre.
+func (s *AdvisorService) Unassign(registration string, semesterID uint) error {
+	student, err := s.findStudent(registration)
+	if err != nil {
+		return err
+	}
+	res := s.db.Model(&models.AdvisorAssignment{}).
+		Where("student_id = ? AND semester_id = ? AND ended_at IS NULL", student.ID, semesterID).
+		Update("ended_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return NotFound("O aluno não tem orientador neste semestre")
+	}
+	return nil
+}
+
+// History devolve todas as atribuições do aluno, vigentes e encerradas,
+// do semestre mais recente para o mais antigo.
+func (s *AdvisorService) History(registration string) ([]models.AdvisorAssignment, error) {
+	student, err := s.findStudent(registration)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.AdvisorAssignment
+	if err := s.preloaded().
+		Joins("JOIN semesters ON semesters.id = advisor_assignments.semester_id").
+		Where("advisor_assignments.student_id = ?", student.ID).
+		Order("semesters.code DESC").
+		Order("advisor_assignments.id DESC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// BulkAssignInput seleciona o grupo da atribuição em lote: os alunos de um
+// curso com registro no semestre, ou o grupo de uma rodada (PAE/PIC no
+// semestre-base, que passa a ser o semestre da atribuição). Exatamente um
+// de CourseID e RoundID deve ser informado.
+type BulkAssignInput struct {
+	AdvisorID      uint
+	SemesterID     uint
+	CourseID       uint
+	RoundID        uint
+	Statuses       []string
+	OnlyUnassigned bool
+	Scope          CourseScope
+}
+
+// BulkAssign atribui o orientador a todos os alunos do grupo em uma única
+// transação e devolve quantas atribuições foram criadas. Com
+// OnlyUnassigned, alunos que já têm orientador no semestre são mantidos —
+// é o modo de repartir um grupo entre vários orientadores.
+func (s *AdvisorService) BulkAssign(in BulkAssignInput, byUserID uint) (int, error) {
+	if (in.CourseID == 0) == (in.RoundID == 0) {
+		return 0, Invalid("informe course_id ou round_id")
+	}
+	if in.CourseID != 0 && !in.Scope.Covers(in.CourseID) {
+		return 0, Forbidden("Curso fora da sua coordenação")
+	}
+	for _, st := range in.Statuses {
+		if st != models.StatusRegular && st != models.StatusPAE && st != models.StatusPIC {
+			return 0, Invalid("situação inválida: " + st)
+		}
+	}
+	_, advisorScope, err := s.findAdvisor(in.AdvisorID)
+	if err != nil {
+		return 0, err
+	}
+	if in.CourseID != 0 && !advisorScope.Covers(in.CourseID) {
+		return 0, Invalid("O curso está fora do escopo do orientador")
+	}
+
+	q := s.db.Model(&models.AcademicRecord{})
+	if in.RoundID != 0 {
+		var round models.PlanRound
+		if err := s.db.First(&round, in.RoundID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, NotFound("Rodada não encontrada")
+			}
+			return 0, err
+		}
+		in.SemesterID = round.BaseSemesterID
+		statuses := in.Statuses
+		if len(statuses) == 0 {
+			statuses = []string{models.StatusPAE, models.StatusPIC}
+		}
+		q = q.Where("academic_records.status IN ?", statuses)
+	} else {
+		if in.SemesterID == 0 {
+			return 0, Invalid("semester_id é obrigatório")
+		}
+		q = q.Joins("JOIN students ON students.id = academic_records.student_id").
+			Where("students.course_id = ?", in.CourseID)
+		if len(in.Statuses) > 0 {
+			q = q.Where("academic_records.status IN ?", in.Statuses)
+		}
+	}
+	if _, err := s.findSemester(in.SemesterID); err != nil {
+		return 0, err
+	}
+	// O grupo de uma rodada atravessa cursos: ficam só os alunos que o
+	// requisitante e o orientador enxergam.
+	q = in.Scope.applyStudents(q.Where("academic_records.semester_id = ?", in.SemesterID), "academic_records.student_id")
+	q = advisorScope.applyStudents(q, "academic_records.student_id")
+	if in.OnlyUnassigned {
+		q = q.Where("NOT EXISTS (SELECT 1 FROM advisor_assignments aa WHERE aa.student_id = academic_records.student_id" +
+			" AND aa.semester_id = academic_records.semester_id AND aa.ended_at IS NULL AND aa.deleted_at IS NULL)")
+	}
+
+	var studentIDs []uint
+	if err := q.Pluck("academic_records.student_id", &studentIDs).Error; err != nil {
+		return 0, err
+	}
+
+	created := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, id := range studentIDs {
+			_, isNew, err := assignAdvisor(tx, id, in.SemesterID, in.AdvisorID, byUserID, now)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				created++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// CaseloadEntry é a carga de um orientador no semestre: total de alunos
+// atribuídos e quantos estão em PAE, PIC ou em situação crítica (RN02).
+type CaseloadEntry struct {
+	AdvisorID   uint   `json:"advisor_id"`
+	AdvisorName string `json:"advisor_name"`
+	Students    int64  `json:"students"`
+	PAE         int64  `json:"pae"`
+	PIC         int64  `json:"pic"`
+	Critical    int64  `json:"critical"`
+}
+
+// Caseload consolida as atribuições vigentes do semestre por orientador,
+// do mais ao menos carregado, contando apenas alunos do escopo.
+func (s *AdvisorService) Caseload(semesterID uint, scope CourseScope) ([]CaseloadEntry, error) {
+	if semesterID == 0 {
+		return nil, Invalid("semester_id é obrigatório")
+	}
+
+	base := func() *gorm.DB {
+		q := s.db.Model(&models.AcademicRecord{}).
+			Joins(currentAdvisorJoin).
+			Where("academic_records.semester_id = ?", semesterID)
+		return scope.applyStudents(q, "academic_records.student_id")
+	}
+
+	var entries []CaseloadEntry
+	if err := base().
+		Joins("JOIN users ON users.id = advisor_assignments.advisor_id").
+		Select("advisor_assignments.advisor_id, users.name AS advisor_name, COUNT(*) AS students, "+
+			"SUM(CASE WHEN academic_records.status = ? THEN 1 ELSE 0 END) AS pae, "+
+			"SUM(CASE WHEN academic_records.status = ? THEN 1 ELSE 0 END) AS pic",
+			models.StatusPAE, models.StatusPIC).
+		Group("advisor_assignments.advisor_id, users.name").
+		Order("students DESC, users.name ASC").
+		Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var critical []struct {
+		AdvisorID uint
+		Total     int64
+	}
+	triage, err := loadTriage(s.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := triage.criticalScope(base().Joins("JOIN students ON students.id = academic_records.student_id")).
+		Select("advisor_assignments.advisor_id, COUNT(*) AS total").
+		Group("advisor_assignments.advisor_id").
+		Scan(&critical).Error; err != nil {
+		return nil, err
+	}
+	byAdvisor := make(map[uint]int64, len(critical))
+	for _, c := range critical {
+		byAdvisor[c.AdvisorID] = c.Total
+	}
+	for i := range entries {
+		entries[i].Critical = byAdvisor[entries[i].AdvisorID]
+	}
+	return entries, nil
+}
+
+func (s *AdvisorService) preloaded() *gorm.DB {
+	return s.db.Preload("Semester").Preload("Advisor", func(db *gorm.DB) *gorm.DB {
+		return db.Omit("password")
+	}).Preload("AssignedBy", authorName)
+}
+
+func (s *AdvisorService) load(id uint) (*models.AdvisorAssignment, error) {
+	var assignment models.AdvisorAssignment
+	if err := s.preloaded().First(&assignment, id).Error; err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func seedAdvisor(t *testing.T, svc *AuthService, email string) *models.User {
+	t.Helper()
+	user, err := svc.CreateUser("Prof "+email, email, "segredo", models.RoleUser)
+	if err != nil {
+		t.Fatalf("seed advisor: %v", err)
+	}
+	// O orientador só recebe alunos dos cursos vinculados a ele.
+	course := models.Course{Code: 1, Name: "Curso Teste"}
+	svc.db.FirstOrCreate(&course, models.Course{Code: 1})
+	if err := svc.db.Model(user).Association("Courses").Append(&course); err != nil {
+		t.Fatalf("seed advisor courses: %v", err)
+	}
+	return user
+}
+
+func TestAssignRequiresEligibleAdvisor(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, "secret")
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+
+	viewer, _ := auth.CreateUser("Leitor", "leitor@ufes.br", "segredo", models.RoleViewer)
+	pending := seedAdvisor(t, auth, "convidado@ufes.br")
+	db.Model(pending).Update("invitation_pending", true)
+	unlinked, _ := auth.CreateUser("Sem curso", "semcurso@ufes.br", "segredo", models.RoleUser)
+
+	svc := NewAdvisorService(db, NewRoleService(db))
+	for name, id := range map[string]uint{"viewer": viewer.ID, "convite pendente": pending.ID, "fora do escopo": unlinked.ID} {
+		if _, err := svc.Assign("2022001", sem.ID, id, 1); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Assign (%s): esperava ErrInvalid, obtive %v", name, err)
+		}
+	}
+	if _, err := svc.BulkAssign(BulkAssignInput{AdvisorID: unlinked.ID, SemesterID: sem.ID, CourseID: 1}, 1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("BulkAssign fora do escopo do orientador: %v", err)
+	}
+}
+
+func TestAssignKeepsHistoryOnReassignment(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, "secret")
+	ana, bia := seedAdvisor(t, auth, "ana@ufes.br"), seedAdvisor(t, auth, "bia@ufes.br")
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+
+	svc := NewAdvisorService(db, NewRoleService(db))
+	first, err := svc.Assign("2022001", sem.ID, ana.ID, 1)
+	if err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	again, err := svc.Assign("2022001", sem.ID, ana.ID, 1)
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("reatribuir ao mesmo orientador não deveria criar linha: %v, %d != %d", err, again.ID, first.ID)
+	}
+	if _, err := svc.Assign("2022001", sem.ID, bia.ID, 1); err != nil {
+		t.Fatalf("Assign (troca): %v", err)
+	}
+
+	history, err := svc.History("2022001")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("esperava 2 atribuições no histórico; obtive %d", len(history))
+	}
+	if history[0].Advisor.Name != bia.Name || history[0].EndedAt != nil {
+		t.Errorf("atribuição vigente deveria ser a da Bia: %+v", history[0])
+	}
+	if history[1].AdvisorID != ana.ID || history[1].EndedAt == nil {
+		t.Errorf("atribuição anterior deveria estar encerrada: %+v", history[1])
+	}
+	// Quem atribuiu (usuário 1, a Ana) vem carregado com o nome.
+	if history[0].AssignedBy == nil || history[0].AssignedBy.Name != ana.Name {
+		t.Errorf("autor da atribuição não carregado: %+v", history[0].AssignedBy)
+	}
+}
+
+func TestBulkAssignSplitsRoundCohortAndFiltersMine(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, "secret")
+	ana, bia := seedAdvisor(t, auth, "ana@ufes.br"), seedAdvisor(t, auth, "bia@ufes.br")
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	seedStudentWithStatus(t, db, "2022002", "2025/2", models.StatusPIC)
+	seedStudentWithStatus(t, db, "2022003", "2025/2", models.StatusRegular)
+
+	rounds := NewPlanRoundService(db)
+	round, err := rounds.Open("2026/1", "2026/2", nil, 1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if round.OpenedBy == nil || round.OpenedBy.Name != ana.Name {
+		t.Errorf("quem abriu a rodada não foi carregado: %+v", round.OpenedBy)
+	}
+
+	svc := NewAdvisorService(db, NewRoleService(db))
+	if _, err := svc.Assign("2022001", round.BaseSemesterID, ana.ID, 1); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	n, err := svc.BulkAssign(BulkAssignInput{AdvisorID: bia.ID, RoundID: round.ID, OnlyUnassigned: true}, 1)
+	if err != nil || n != 1 {
+		t.Fatalf("BulkAssign deveria atribuir só o PIC livre: n=%d err=%v", n, err)
+	}
+	if _, err := svc.BulkAssign(BulkAssignInput{AdvisorID: bia.ID, CourseID: 1, RoundID: round.ID}, 1); err == nil {
+		t.Error("course_id e round_id juntos deveriam ser rejeitados")
+	}
+
+	_, mine, err := rounds.Cohort(round.ID, bia.ID, AllCourses())
+	if err != nil || len(mine) != 1 || mine[0].Registration != "2022002" {
+		t.Fatalf("grupo da Bia deveria ter só 2022002: %+v (err=%v)", mine, err)
+	}
+
+	records, _, err := NewReportService(db).Records(RecordsFilter{AdvisorID: ana.ID})
+	if err != nil || len(records) != 1 || records[0].Student.Registration != "2022001" {
+		t.Fatalf("relatório da Ana deveria ter só 2022001: %d registros (err=%v)", len(records), err)
+	}
+}
+
+func TestCaseloadCountsStatusesAndCritical(t *testing.T) {
+	db := newTestDB(t)
+	ana := seedAdvisor(t, NewAuthService(db, "secret"), "ana@ufes.br")
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	seedStudentWithStatus(t, db, "2022002", "2025/2", models.StatusRegular)
+	db.Model(&models.AcademicRecord{}).Where("student_id = (SELECT id FROM students WHERE registration = ?)", "2022002").
+		Update("locks", 2)
+
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+
+	svc := NewAdvisorService(db, NewRoleService(db))
+	if n, err := svc.BulkAssign(BulkAssignInput{AdvisorID: ana.ID, SemesterID: sem.ID, CourseID: 1}, 1); err != nil || n != 2 {
+		t.Fatalf("BulkAssign por curso: n=%d err=%v", n, err)
+	}
+
+	entries, err := svc.Caseload(sem.ID, AllCourses())
+	if err != nil {
+		t.Fatalf("Caseload: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("esperava 1 orientador; obtive %d", len(entries))
+	}
+	e := entries[0]
+	if e.AdvisorName != ana.Name || e.Students != 2 || e.PAE != 1 || e.PIC != 0 || e.Critical != 1 {
+		t.Errorf("carga incorreta: %+v", e)
+	}
+}
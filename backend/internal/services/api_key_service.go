@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Ações de auditoria das chaves de API.
+const (
+	AuditAPIKeyCreated = "api_key.created"
+	AuditAPIKeyRevoked = "api_key.revoked"
+)
+
+// apiKeyPrefix identifica as chaves emitidas pelo sistema; o prefixo
+// exibido na listagem é ele mais os primeiros caracteres aleatórios.
+const (
+	apiKeyPrefix       = "ada_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+)
+
+// apiKeyTouchInterval limita a gravação de last_used_at: uma integração
+// que faça muitas chamadas seguidas não gera uma escrita por requisição.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService emite, lista e revoga as chaves de integração e as
+// valida para o middleware de autenticação.
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService { return &APIKeyService{db: db} }
+
+type APIKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+func apiKeyTarget(id uint) string { return "api_key:" + strconv.FormatUint(uint64(id), 10) }
+
+// List devolve todas as chaves, inclusive revogadas e expiradas, das mais
+// recentes para as mais antigas.
+func (s *APIKeyService) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := s.db.Order("id desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Create emite uma chave e devolve o seu valor em claro — única vez em
+// que ele existe fora do cliente; o banco guarda só o hash.
+func (s *APIKeyService) Create(in APIKeyInput, actor Actor) (*models.APIKey, string, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", Invalid("nome da chave é obrigatório (até 100 caracteres)")
+	}
+	scopes, err := validateAPIKeyScopes(in.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, "", Invalid("expires_at deve estar no futuro")
+	}
+
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := apiKeyPrefix + secret
+
+	key := models.APIKey{
+		Name:            name,
+		Prefix:          raw[:apiKeyPrefixLength],
+		KeyHash:         hashToken(raw),
+		Scopes:          strings.Join(scopes, ","),
+		ExpiresAt:       in.ExpiresAt,
+		CreatedByUserID: actor.UserID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditAPIKeyCreated, apiKeyTarget(key.ID), name+" ("+key.Scopes+")")
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &key, raw, nil
+}
+
+// Revoke invalida a chave imediatamente. A chave continua listada, com a
+// data da revogação; revogar de novo não altera nada.
+func (s *APIKeyService) Revoke(id uint, actor Actor) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := s.db.First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Chave de API não encontrada")
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return &key, nil
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&key).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditAPIKeyRevoked, apiKeyTarget(key.ID), key.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Authenticate valida a chave apresentada no cabeçalho X-API-Key e devolve
+// as claims da integração (papel models.RoleAPIKey, com os escopos da
+// chave). Chave desconhecida, revogada ou expirada é Unauthorized.
+func (s *APIKeyService) Authenticate(raw string) (*Claims, error) {
+	invalid := Unauthorized("Chave de API inválida, revogada ou expirada")
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, invalid
+	}
+
+	var key models.APIKey
+	if err := s.db.Where("key_hash = ?", hashToken(raw)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return nil, invalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.db.Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &Claims{
+		Role:     models.RoleAPIKey,
+		APIKeyID: key.ID,
+		Scopes:   strings.Split(key.Scopes, ","),
+	}, nil
+}
+
+// validateAPIKeyScopes exige ao menos um escopo do catálogo e devolve a
+// lista sem repetições, na ordem do catálogo.
+func validateAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, Invalid("informe ao menos um escopo")
+	}
+	for _, sc := range scopes {
+		if !slices.Contains(models.APIKeyScopes, sc) {
+			return nil, Invalid("escopo desconhecido: " + sc)
+		}
+	}
+	out := make([]string, 0, len(scopes))
+	for _, sc := range models.APIKeyScopes {
+		if slices.Contains(scopes, sc) {
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
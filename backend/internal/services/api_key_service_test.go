@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	keys := NewAPIKeyService(db)
+	admin := Actor{UserID: 1}
+
+	if _, _, err := keys.Create(APIKeyInput{Name: "BI", Scopes: []string{"reports.write"}}, admin); !errors.Is(err, ErrInvalid) {
+		t.Errorf("escopo desconhecido deveria ser recusado; obtive %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := keys.Create(APIKeyInput{Name: "BI", Scopes: []string{models.ScopeReportsRecords}, ExpiresAt: &past}, admin); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expiração no passado deveria ser recusada; obtive %v", err)
+	}
+
+	key, raw, err := keys.Create(APIKeyInput{
+		Name:   "BI noturno",
+		Scopes: []string{models.ScopeReportsDashboard, models.ScopeReportsRecords, models.ScopeReportsRecords},
+	}, admin)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(raw, key.Prefix) || key.KeyHash == raw || key.Scopes != "reports.records,reports.dashboard" {
+		t.Errorf("chave emitida inconsistente: prefixo %q, escopos %q", key.Prefix, key.Scopes)
+	}
+
+	claims, err := keys.Authenticate(raw)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if claims.Role != models.RoleAPIKey || claims.APIKeyID != key.ID || len(claims.Scopes) != 2 {
+		t.Errorf("claims da chave: %+v", claims)
+	}
+	if scope, _ := NewScopeService(db, NewRoleService(db)).Resolve(claims); !scope.Global() {
+		t.Error("chave de API deveria ler todos os cursos")
+	}
+	if ok, _ := NewRoleService(db).HasPermission(models.RoleAPIKey, models.PermReportsRead); ok {
+		t.Error("chave de API não tem permissões nomeadas")
+	}
+
+	var stored models.APIKey
+	db.First(&stored, key.ID)
+	if stored.LastUsedAt == nil {
+		t.Error("last_used_at deveria ser gravado no uso")
+	}
+
+	if _, err := keys.Authenticate(raw + "0"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("chave desconhecida deveria ser recusada; obtive %v", err)
+	}
+
+	if _, err := keys.Revoke(key.ID, admin); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := keys.Authenticate(raw); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("chave revogada deveria ser recusada; obtive %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	expiring, raw2, err := keys.Create(APIKeyInput{Name: "Temporária", Scopes: []string{models.ScopeReportsCatalog}, ExpiresAt: &future}, admin)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	db.Model(expiring).Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err := keys.Authenticate(raw2); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("chave expirada deveria ser recusada; obtive %v", err)
+	}
+
+	var logs int64
+	db.Model(&models.AuditLog{}).Where("action LIKE ?", "api_key.%").Count(&logs)
+	if logs != 3 {
+		t.Errorf("auditoria: %d entradas; esperado 3", logs)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Ações registradas na trilha de auditoria (AuditLog.Action).
+const (
+	AuditResetIssued   = "student.password_reset.issued"
+	AuditResetRedeemed = "student.password_reset.redeemed"
+	AuditResetFailed   = "student.password_reset.failed"
+)
+
+// Actor identifica quem executa uma operação auditada: usuário da
+// coordenação, aluno ou anônimo (rotas públicas), com o IP de origem.
+// Role é o papel do usuário da coordenação, usado nas checagens de
+// gestão de contas.
+type Actor struct {
+	UserID    uint
+	StudentID uint
+	Role      string
+	IP        string
+}
+
+// audit grava uma entrada na trilha dentro da transação tx da operação,
+// de modo que operação e registro entram (ou não) juntos.
+func audit(tx *gorm.DB, actor Actor, action, target, detail string) error {
+	entry := models.AuditLog{
+		Action: action,
+		Target: target,
+		Detail: truncate(detail, 500),
+		IP:     truncate(actor.IP, 64),
+	}
+	if actor.UserID != 0 {
+		entry.ActorUserID = &actor.UserID
+	}
+	if actor.StudentID != 0 {
+		entry.ActorStudentID = &actor.StudentID
+	}
+	return tx.Create(&entry).Error
+}
+
+// studentTarget é o alvo de auditoria de um aluno.
+func studentTarget(registration string) string { return "student:" + registration }
+
+func roleTarget(name string) string { return "role:" + name }
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService { return &AuditService{db: db} }
+
+type AuditFilter struct {
+	Action string // prefixo, ex.: "student.password_reset"
+	Target string
+	Limit  int
+	Offset int
+}
+
+// List devolve a trilha de auditoria, mais recentes primeiro. Quando
+// Limit > 0 a consulta é paginada e o total é calculado; caso contrário
+// total é -1.
+func (s *AuditService) List(f AuditFilter) ([]models.AuditLog, int64, error) {
+	q := s.db.Model(&models.AuditLog{})
+	if f.Action = strings.TrimSpace(f.Action); f.Action != "" {
+		q = q.Where("action LIKE ?", f.Action+"%")
+	}
+	if f.Target != "" {
+		q = q.Where("target = ?", f.Target)
+	}
+
+	total := int64(-1)
+	if f.Limit > 0 {
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+		q = q.Limit(f.Limit).Offset(f.Offset)
+	}
+
+	var entries []models.AuditLog
+	if err := q.Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
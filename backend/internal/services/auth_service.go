@@ -1,7 +1,10 @@
 package services
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,65 +14,289 @@ import (
 	"adamanagement/backend/internal/models"
 )
 
-// TokenTTL é a validade do token JWT (RN08).
-const TokenTTL = 24 * time.Hour
-
 // Claims é o payload dos tokens emitidos pelo sistema. O middleware de
 // autenticação valida com este mesmo tipo, mantendo o contrato em um
 // único lugar. Tokens de staff carregam UserID; tokens de aluno
-// (role="student") carregam StudentID e Registration.
+// (role="student") carregam StudentID e Registration. SessionID liga o
+// token de acesso à sessão (família de refresh tokens) que o emitiu.
 type Claims struct {
 	UserID       uint   `json:"user_id,omitempty"`
 	StudentID    uint   `json:"student_id,omitempty"`
 	Registration string `json:"registration,omitempty"`
 	Role         string `json:"role"`
+	SessionID    string `json:"sid,omitempty"`
+	// APIKeyID e Scopes só existem nas claims de uma chave de API
+	// (APIKeyService.Authenticate); nunca vão para um JWT.
+	APIKeyID uint     `json:"-"`
+	Scopes   []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
 type AuthService struct {
 	db        *gorm.DB
-	jwtSecret []byte
+	sessions  *SessionService
+	guard     *LoginGuard
+	twoFactor *TwoFactorService
+	// challengeKey assina o desafio do segundo passo do login. É derivada
+	// do segredo JWT por HMAC, mas distinta dele: um desafio nunca vale
+	// como token de acesso.
+	challengeKey []byte
+	// passwordLogin desligado deixa apenas o login institucional (SSO).
+	passwordLogin bool
 }
 
 func NewAuthService(db *gorm.DB, jwtSecret string) *AuthService {
-	return &AuthService{db: db, jwtSecret: []byte(jwtSecret)}
+	return &AuthService{
+		db:            db,
+		sessions:      NewSessionService(db, jwtSecret),
+		guard:         NewLoginGuard(db),
+		twoFactor:     NewTwoFactorService(db),
+		challengeKey:  deriveKey(jwtSecret, "two-factor"),
+		passwordLogin: true,
+	}
+}
+
+// SetPasswordLogin liga ou desliga o login local por senha.
+func (s *AuthService) SetPasswordLogin(enabled bool) { s.passwordLogin = enabled }
+
+// errPasswordLoginDisabled orienta o uso do login institucional.
+var errPasswordLoginDisabled = Forbidden("Login por senha desativado: entre com a conta institucional")
+
+// TwoFactorChallengeTTL é o prazo para concluir o segundo passo do login.
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+var (
+	errBadCredentials = Unauthorized("usuário ou senha incorretos")
+	errBadChallenge   = Unauthorized("desafio de login inválido ou expirado: entre novamente")
+	// errDeactivated só é respondido depois da senha correta, para não
+	// revelar a situação da conta a quem não a conhece.
+	errDeactivated = Forbidden("Sua conta está desativada. Procure a administração do sistema.")
+)
+
+// LoginResult é o desfecho de um passo do login. Com a verificação em duas
+// etapas, o primeiro passo devolve apenas Challenge (e EnrollmentRequired
+// quando o administrador ainda precisa configurá-la); Tokens vem no passo
+// final. RecoveryCodes só é preenchido quando o login concluiu a ativação.
+type LoginResult struct {
+	Tokens             *TokenPair
+	User               *models.User
+	Challenge          string
+	EnrollmentRequired bool
+	RecoveryCodes      []string
 }
 
-// Login valida as credenciais e emite um token JWT assinado com HS256.
-func (s *AuthService) Login(email, password string) (string, *models.User, error) {
+// Login valida as credenciais e abre uma sessão: token de acesso JWT
+// (HS256) e refresh token. Falhas contam para o limite de tentativas da
+// conta (LoginGuard); conta inexistente conta igual, sem revelar e-mails.
+// Usuário com verificação em duas etapas (ou administrador obrigado a
+// configurá-la) recebe um desafio em vez da sessão.
+func (s *AuthService) Login(email, password string, actor Actor) (*LoginResult, error) {
+	if !s.passwordLogin {
+		return nil, errPasswordLoginDisabled
+	}
+	key := StaffAccountKey(email)
+	if err := s.guard.Check(key); err != nil {
+		return nil, err
+	}
+
 	var user models.User
 	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, Unauthorized("usuário ou senha incorretos")
+			return nil, s.guard.loginFailed(key, actor, "conta inexistente", errBadCredentials)
 		}
-		return "", nil, err
+		return nil, err
 	}
 
+	// O convidado ainda sem senha recebe a mesma resposta de credencial
+	// inválida: a ativação é só pelo link do convite.
+	if user.InvitationPending {
+		return nil, s.guard.loginFailed(key, actor, "convite pendente", errBadCredentials)
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", nil, Unauthorized("usuário ou senha incorretos")
+		return nil, s.guard.loginFailed(key, actor, "senha incorreta", errBadCredentials)
+	}
+	if user.DeactivatedAt != nil {
+		return nil, errDeactivated
+	}
+
+	enroll := false
+	if user.TOTPEnabledAt == nil {
+		required, err := s.twoFactor.requiredFor(&user)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return s.complete(&user, key)
+		}
+		enroll = true
 	}
 
+	challenge, err := s.signChallenge(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{User: &user, Challenge: challenge, EnrollmentRequired: enroll}, nil
+}
+
+// EnrollTwoFactor atende o administrador que a política obriga a
+// configurar a verificação durante o login: gera o segredo a partir do
+// desafio, sem sessão aberta.
+func (s *AuthService) EnrollTwoFactor(challenge string) (*TOTPSetup, error) {
+	user, _, err := s.challengeUser(challenge)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, Conflict("A verificação em duas etapas já está ativa")
+	}
+	return s.twoFactor.Setup(user.ID)
+}
+
+// VerifyTwoFactor conclui o login com o código do aplicativo ou um código
+// de recuperação. Se a verificação ainda não estava ativa (configuração
+// obrigatória), o código confirma o segredo, a ativa e o resultado traz os
+// códigos de recuperação. Códigos errados contam para o limite da conta.
+func (s *AuthService) VerifyTwoFactor(challenge, code string, actor Actor) (*LoginResult, error) {
+	user, jti, err := s.challengeUser(challenge)
+	if err != nil {
+		return nil, err
+	}
+	key := StaffAccountKey(user.Email)
+	if err := s.guard.Check(key); err != nil {
+		return nil, err
+	}
+	actor.UserID = user.ID
+
+	if user.TOTPEnabledAt == nil {
+		codes, err := s.twoFactor.Enable(user.ID, code, actor)
+		if errors.Is(err, errInvalidTOTP) {
+			return nil, s.guard.loginFailed(key, actor, "código de verificação incorreto", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := s.consumeChallenge(jti); err != nil {
+			return nil, err
+		}
+		result, err := s.complete(user, key)
+		if err != nil {
+			return nil, err
+		}
+		result.RecoveryCodes = codes
+		return result, nil
+	}
+
+	if err := verifySecondFactor(s.db, user, code, actor); err != nil {
+		if errors.Is(err, errInvalidTOTP) {
+			return nil, s.guard.loginFailed(key, actor, "código de verificação incorreto", err)
+		}
+		return nil, err
+	}
+	if err := s.consumeChallenge(jti); err != nil {
+		return nil, err
+	}
+	return s.complete(user, key)
+}
+
+// consumeChallenge apaga o desafio jti ao concluir o segundo passo. Um
+// desafio já concluído (reapresentado em paralelo) é recusado.
+func (s *AuthService) consumeChallenge(jti string) error {
+	res := s.db.Where("id = ?", jti).Delete(&models.TwoFactorChallenge{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errBadChallenge
+	}
+	return nil
+}
+
+// complete zera o contador de falhas da conta e abre a sessão.
+func (s *AuthService) complete(user *models.User, key string) (*LoginResult, error) {
+	if err := s.guard.Reset(key); err != nil {
+		return nil, err
+	}
+	pair, err := s.sessions.Start(Claims{UserID: user.ID, Role: user.Role})
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: pair, User: user}, nil
+}
+
+// deriveKey deriva do segredo JWT uma chave para outro propósito
+// (HMAC-SHA256 com o rótulo), sem relação utilizável com o segredo.
+func deriveKey(secret, label string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// signChallenge emite o desafio e registra o jti dele, que a conclusão do
+// login consome.
+func (s *AuthService) signChallenge(userID uint) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
 	now := time.Now()
-	claims := &Claims{
-		UserID: user.ID,
-		Role:   user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
-		},
+	expires := now.Add(TwoFactorChallengeTTL)
+	if err := s.db.Create(&models.TwoFactorChallenge{ID: jti, UserID: userID, ExpiresAt: expires}).Error; err != nil {
+		return "", err
 	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        jti,
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expires),
+	}).SignedString(s.challengeKey)
+}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+// challengeUser valida o desafio — assinatura, prazo e jti ainda não
+// consumido — e recarrega o usuário dele. Devolve também o jti.
+func (s *AuthService) challengeUser(challenge string) (*models.User, string, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(challenge, claims, func(*jwt.Token) (any, error) {
+		return s.challengeKey, nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil || !token.Valid || claims.ID == "" {
+		return nil, "", errBadChallenge
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
 	if err != nil {
-		return "", nil, err
+		return nil, "", errBadChallenge
+	}
+	var pending int64
+	if err := s.db.Model(&models.TwoFactorChallenge{}).
+		Where("id = ? AND user_id = ? AND expires_at > ?", claims.ID, id, time.Now()).
+		Count(&pending).Error; err != nil {
+		return nil, "", err
 	}
-	return token, &user, nil
+	if pending == 0 {
+		return nil, "", errBadChallenge
+	}
+	var user models.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errBadChallenge
+		}
+		return nil, "", err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, "", errDeactivated
+	}
+	return &user, claims.ID, nil
+}
+
+// PurgeTwoFactorChallenges remove desafios vencidos sem conclusão.
+func PurgeTwoFactorChallenges(db *gorm.DB, now time.Time) (int64, error) {
+	res := db.Where("expires_at < ?", now).Delete(&models.TwoFactorChallenge{})
+	return res.RowsAffected, res.Error
 }
 
 // Me retorna o usuário identificado pelo token.
 func (s *AuthService) Me(id uint) (*models.User, error) {
 	var user models.User
-	if err := s.db.Omit("password").First(&user, id).Error; err != nil {
+	if err := s.db.Omit("password").Preload("Courses").First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, NotFound("Usuário não encontrado")
 		}
@@ -78,15 +305,20 @@ func (s *AuthService) Me(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// Permissions lista as permissões do papel do usuário (exibidas em /me).
+func (s *AuthService) Permissions(role string) ([]string, error) {
+	return rolePermissions(s.db, role)
+}
+
 // CreateUser cadastra um usuário com a senha em hash BCrypt (RN07).
 // A unicidade do e-mail é garantida pelo índice único: a violação é
 // traduzida para conflito, sem janela de corrida (check-then-act).
 func (s *AuthService) CreateUser(name, email, password, role string) (*models.User, error) {
 	if role == "" {
-		role = "user"
+		role = models.RoleUser
 	}
-	if role != "user" && role != "admin" {
-		return nil, Invalid("papel inválido: use 'admin' ou 'user'")
+	if err := ensureRole(s.db, role); err != nil {
+		return nil, err
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// AuditUserCoursesUpdated registra a troca dos cursos de um usuário.
+const AuditUserCoursesUpdated = "user.courses.updated"
+
+// CourseScope delimita os cursos cujos alunos o requisitante enxerga. O
+// valor zero não restringe (papéis com courses.all e rotinas internas);
+// para os demais papéis o escopo é a lista de cursos vinculados ao
+// usuário — sem nenhum curso, nada é visível.
+type CourseScope struct {
+	restricted bool
+	courseIDs  []uint
+}
+
+// AllCourses é o escopo global.
+func AllCourses() CourseScope { return CourseScope{} }
+
+// RestrictTo limita o escopo aos cursos informados.
+func RestrictTo(courseIDs []uint) CourseScope {
+	return CourseScope{restricted: true, courseIDs: courseIDs}
+}
+
+// Global informa se o escopo abrange todos os cursos.
+func (s CourseScope) Global() bool { return !s.restricted }
+
+// CourseIDs devolve os cursos do escopo restrito (nil no escopo global).
+func (s CourseScope) CourseIDs() []uint { return s.courseIDs }
+
+// Covers informa se o curso está no escopo.
+func (s CourseScope) Covers(courseID uint) bool {
+	if !s.restricted {
+		return true
+	}
+	for _, id := range s.courseIDs {
+		if id == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// apply filtra a consulta pela coluna de curso informada (por exemplo
+// "students.course_id").
+func (s CourseScope) apply(q *gorm.DB, column string) *gorm.DB {
+	if !s.restricted {
+		return q
+	}
+	if len(s.courseIDs) == 0 {
+		return q.Where("1 = 0")
+	}
+	return q.Where(column+" IN ?", s.courseIDs)
+}
+
+// applyStudents filtra por uma coluna de aluno (por exemplo
+// "academic_records.student_id"), para consultas que não juntam students.
+func (s CourseScope) applyStudents(q *gorm.DB, column string) *gorm.DB {
+	if !s.restricted {
+		return q
+	}
+	if len(s.courseIDs) == 0 {
+		return q.Where("1 = 0")
+	}
+	return q.Where(column+" IN (SELECT id FROM students WHERE course_id IN ?)", s.courseIDs)
+}
+
+// errOutOfScope é a recusa padrão a alunos de cursos fora do escopo.
+var errOutOfScope = Forbidden("Aluno fora dos cursos sob sua coordenação")
+
+// checkStudent recusa o aluno cujo curso está fora do escopo.
+func (s CourseScope) checkStudent(db *gorm.DB, studentID uint) error {
+	if !s.restricted {
+		return nil
+	}
+	var student models.Student
+	if err := db.Select("id", "course_id").First(&student, studentID).Error; err != nil {
+		return err
+	}
+	if !s.Covers(student.CourseID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// PermissionChecker informa se um papel concede uma permissão nomeada.
+// Implementado por RoleService, que mantém as permissões em cache.
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// ScopeService resolve o escopo de cursos do requisitante e mantém os
+// vínculos usuário ↔ curso.
+type ScopeService struct {
+	db    *gorm.DB
+	perms PermissionChecker
+}
+
+func NewScopeService(db *gorm.DB, perms PermissionChecker) *ScopeService {
+	return &ScopeService{db: db, perms: perms}
+}
+
+// Resolve devolve o escopo do token: global para papéis com a permissão
+// courses.all (admin e viewer, por padrão), restrito aos cursos
+// vinculados para os demais; chaves de API leem todos os cursos. O
+// vínculo é lido a cada requisição, de modo que alterações valem
+// imediatamente.
+func (s *ScopeService) Resolve(claims *Claims) (CourseScope, error) {
+	if claims.Role == models.RoleAPIKey {
+		return AllCourses(), nil
+	}
+	global, err := s.perms.HasPermission(claims.Role, models.PermCoursesAll)
+	if err != nil {
+		return CourseScope{}, err
+	}
+	if global {
+		return AllCourses(), nil
+	}
+	var ids []uint
+	if err := s.db.Table("user_courses").
+		Where("user_id = ?", claims.UserID).
+		Pluck("course_id", &ids).Error; err != nil {
+		return CourseScope{}, err
+	}
+	return RestrictTo(ids), nil
+}
+
+// CheckStudent recusa (Forbidden) o acesso a um aluno de curso fora do
+// escopo. Matrícula inexistente passa: o handler responde 404 como antes.
+func (s *ScopeService) CheckStudent(scope CourseScope, registration string) error {
+	if scope.Global() {
+		return nil
+	}
+	var student models.Student
+	err := s.db.Select("id", "course_id").Where("registration = ?", registration).First(&student).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !scope.Covers(student.CourseID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// SetCourses substitui os cursos vinculados ao usuário.
+func (s *ScopeService) SetCourses(userID uint, courseIDs []uint, actor Actor) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Usuário não encontrado")
+		}
+		return nil, err
+	}
+
+	var courses []models.Course
+	if len(courseIDs) > 0 {
+		if err := s.db.Order("name").Find(&courses, courseIDs).Error; err != nil {
+			return nil, err
+		}
+		if len(courses) != len(courseIDs) {
+			return nil, Invalid("um ou mais cursos informados não existem")
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Association("Courses").Replace(courses); err != nil {
+			return err
+		}
+		names := make([]string, len(courses))
+		for i, c := range courses {
+			names[i] = c.Name
+		}
+		return audit(tx, actor, AuditUserCoursesUpdated, userTarget(user.Email), strings.Join(names, ", "))
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Courses = courses
+	return &user, nil
+}
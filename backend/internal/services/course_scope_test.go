@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestCourseScopeRestrictsStaffToTheirCourses(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	scopes := NewScopeService(db, NewRoleService(db))
+	ana, _ := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser)
+
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	other := seedStudentWithStatus(t, db, "2022002", "2025/2", models.StatusPIC)
+	physics := models.Course{Code: 2, Name: "Física"}
+	db.Create(&physics)
+	db.Model(other).Update("course_id", physics.ID)
+
+	// Sem cursos vinculados, o usuário não enxerga nenhum aluno.
+	scope, err := scopes.Resolve(&Claims{UserID: ana.ID, Role: models.RoleUser})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	reports := NewReportService(db)
+	if records, _, _ := reports.Records(RecordsFilter{Scope: scope}); len(records) != 0 {
+		t.Errorf("usuário sem cursos não deveria ver registros; obtive %d", len(records))
+	}
+
+	if _, err := scopes.SetCourses(ana.ID, []uint{other.CourseID + 100}, Actor{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("curso inexistente deveria ser recusado; obtive %v", err)
+	}
+	if _, err := scopes.SetCourses(ana.ID, []uint{1}, Actor{UserID: 1}); err != nil {
+		t.Fatalf("SetCourses: %v", err)
+	}
+	scope, _ = scopes.Resolve(&Claims{UserID: ana.ID, Role: models.RoleUser})
+
+	records, _, _ := reports.Records(RecordsFilter{Scope: scope})
+	if len(records) != 1 || records[0].Student.Registration != "2022001" {
+		t.Errorf("relatório deveria trazer só o aluno do curso vinculado; obtive %d registros", len(records))
+	}
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+	dashboard, err := NewIndicatorsService(db).Dashboard(strconv.FormatUint(uint64(sem.ID), 10), scope)
+	if err != nil {
+		t.Fatalf("Dashboard: %v", err)
+	}
+	if len(dashboard.StatusDistribution) != 1 || dashboard.StatusDistribution[0].Name != models.StatusPAE {
+		t.Errorf("distribuição deveria contar só o curso vinculado: %+v", dashboard.StatusDistribution)
+	}
+
+	if err := scopes.CheckStudent(scope, "2022001"); err != nil {
+		t.Errorf("aluno do curso vinculado deveria passar: %v", err)
+	}
+	if err := scopes.CheckStudent(scope, "2022002"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("aluno de outro curso deveria ser recusado; obtive %v", err)
+	}
+
+	// Ações identificadas só pelo ID também respeitam o escopo.
+	actions := NewActionService(db)
+	action, err := actions.Create("2022002", ActionInput{SemesterID: sem.ID, ActionDate: time.Now(), Description: "Reunião"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := actions.Delete(action.ID, scope); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ação de aluno de outro curso deveria ser recusada; obtive %v", err)
+	}
+
+	admin, _ := scopes.Resolve(&Claims{UserID: 1, Role: models.RoleAdmin})
+	if records, _, _ := reports.Records(RecordsFilter{Scope: admin}); len(records) != 2 {
+		t.Errorf("administrador deveria ver todos os cursos; obtive %d registros", len(records))
+	}
+}
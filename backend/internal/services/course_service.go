@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// AuditCourseMaxDuration registra a troca do prazo máximo de um curso.
+const AuditCourseMaxDuration = "course.max_duration.updated"
+
+// maxCourseDuration limita o prazo aceito; acima disso é erro de digitação.
+const maxCourseDuration = 40
+
+// CourseService mantém os dados de curso que não vêm da planilha de
+// importação.
+type CourseService struct {
+	db *gorm.DB
+}
+
+func NewCourseService(db *gorm.DB) *CourseService { return &CourseService{db: db} }
+
+// SetMaxDuration grava o prazo máximo de integralização do curso, em
+// semestres (0 remove), e refaz as projeções de formatura (RN33) na mesma
+// transação.
+func (s *CourseService) SetMaxDuration(id uint, semesters int, actor Actor) (*models.Course, error) {
+	if semesters < 0 || semesters > maxCourseDuration {
+		return nil, Invalid(fmt.Sprintf("max_duration_semesters deve estar entre 0 e %d", maxCourseDuration))
+	}
+	var course models.Course
+	if err := s.db.First(&course, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Curso não encontrado")
+		}
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&course).Update("max_duration_semesters", semesters).Error; err != nil {
+			return err
+		}
+		// O prazo só afeta a projeção dos alunos do curso.
+		var studentIDs []uint
+		if err := tx.Model(&models.Student{}).Where("course_id = ?", course.ID).Pluck("id", &studentIDs).Error; err != nil {
+			return err
+		}
+		if err := recomputeRecords(tx, studentIDs); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditCourseMaxDuration, "course:"+strconv.FormatUint(uint64(course.ID), 10),
+			fmt.Sprintf("%s: %d semestres", course.Name, semesters))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
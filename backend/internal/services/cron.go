@@ -0,0 +1,138 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Agendamentos no formato do cron: cinco campos (minuto, hora, dia do
+// mês, mês e dia da semana) com "*", listas, intervalos e passos — "0 7 *
+// * 1" é toda segunda às 7h. Os horários são os do fuso do servidor.
+
+// cronAliases são os atalhos aceitos no lugar dos cinco campos.
+var cronAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronSchedule guarda cada campo como um conjunto de bits. Como no cron,
+// se dia do mês e dia da semana estão ambos restritos, basta um coincidir.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// cronFields são os limites de cada campo, na ordem da expressão.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minuto", 0, 59},
+	{"hora", 0, 23},
+	{"dia do mês", 1, 31},
+	{"mês", 1, 12},
+	{"dia da semana", 0, 7},
+}
+
+// parseCron valida e interpreta a expressão.
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, Invalid("cron deve ter 5 campos: minuto hora dia-do-mês mês dia-da-semana")
+	}
+	var sets [5]uint64
+	for i, part := range parts {
+		f := cronFields[i]
+		set, err := parseCronField(part, f.min, f.max)
+		if err != nil {
+			return nil, Invalid("cron: " + f.name + " inválido (" + part + ")")
+		}
+		sets[i] = set
+	}
+	// Domingo pode ser 0 ou 7.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return &cronSchedule{
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		domAny: parts[2] == "*", dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField interpreta um campo: itens separados por vírgula, cada um
+// "*", "n" ou "a-b", opcionalmente seguido de "/passo".
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, strconv.ErrSyntax
+			}
+			step = n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, strconv.ErrRange
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next devolve o primeiro horário da agenda estritamente posterior a t
+// (zero se não há nenhum nos próximos anos, ex.: 31 de fevereiro).
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -14,12 +14,20 @@ type DisciplineService struct {
 
 func NewDisciplineService(db *gorm.DB) *DisciplineService { return &DisciplineService{db: db} }
 
-func (s *DisciplineService) List() ([]models.Discipline, error) {
-	var disciplines []models.Discipline
-	if err := s.db.Order("name asc").Find(&disciplines).Error; err != nil {
-		return nil, err
+var disciplineSortKeys = []sortKey[models.Discipline]{
+	{"code", "code", func(d models.Discipline) any { return d.Code }},
+	{"name", "name", func(d models.Discipline) any { return d.Name }},
+	{"id", "id", func(d models.Discipline) any { return d.ID }},
+}
+
+// List lista o catálogo ordenado por sort (chaves de disciplineSortKeys;
+// padrão: nome).
+func (s *DisciplineService) List(sort string, page Page) ([]models.Discipline, PageInfo, error) {
+	terms, err := parseSort(sort, "name", disciplineSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
-	return disciplines, nil
+	return paginate(s.db.Model(&models.Discipline{}), terms, page)
 }
 
 // Create insere a disciplina e traduz a violação do índice único de
@@ -50,6 +58,7 @@ func (s *DisciplineService) Update(id uint, code, name *string) (*models.Discipl
 	}
 	if name != nil {
 		updates["name"] = *name
+		updates["search_name"] = models.Fold(*name)
 	}
 	if len(updates) == 0 {
 		return nil, Invalid("Nenhum campo fornecido para atualização")
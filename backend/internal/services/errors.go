@@ -1,6 +1,9 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Sentinelas de erro do domínio. Os controllers traduzem cada categoria
 // para o status HTTP correspondente em um único ponto (respondError);
@@ -11,6 +14,7 @@ var (
 	ErrForbidden    = errors.New("operação não permitida")
 	ErrNotFound     = errors.New("recurso não encontrado")
 	ErrConflict     = errors.New("conflito de estado")
+	ErrTooMany      = errors.New("muitas tentativas")
 )
 
 type domainError struct {
@@ -26,3 +30,23 @@ func Unauthorized(msg string) error { return &domainError{ErrUnauthorized, msg}
 func Forbidden(msg string) error    { return &domainError{ErrForbidden, msg} }
 func NotFound(msg string) error     { return &domainError{ErrNotFound, msg} }
 func Conflict(msg string) error     { return &domainError{ErrConflict, msg} }
+
+// throttledError é um ErrTooMany que informa quando o cliente pode tentar
+// de novo (cabeçalho Retry-After).
+type throttledError struct {
+	domainError
+	retryAfter time.Duration
+}
+
+func TooMany(msg string, retryAfter time.Duration) error {
+	return &throttledError{domainError{ErrTooMany, msg}, retryAfter}
+}
+
+// RetryAfter devolve a espera sugerida por um erro ErrTooMany.
+func RetryAfter(err error) (time.Duration, bool) {
+	var t *throttledError
+	if errors.As(err, &t) {
+		return t.retryAfter, true
+	}
+	return 0, false
+}
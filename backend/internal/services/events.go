@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Eventos de domínio publicados para integrações externas (webhooks).
+const (
+	EventImportCompleted = "import.completed"
+	EventRoundOpened     = "round.opened"
+	EventRoundClosed     = "round.closed"
+	EventPlanSubmitted   = "plan.submitted"
+	EventActionCreated   = "action.created"
+)
+
+// WebhookEvents lista os eventos que podem ser assinados.
+var WebhookEvents = []string{
+	EventImportCompleted,
+	EventRoundOpened,
+	EventRoundClosed,
+	EventPlanSubmitted,
+	EventActionCreated,
+}
+
+// eventEnvelope é o corpo JSON entregue aos webhooks.
+type eventEnvelope struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// publishEvent grava, na transação tx do evento, uma entrega pendente para
+// cada webhook ativo que assina event. Como a outbox de e-mails, nada é
+// entregue se a transação não for confirmada.
+func publishEvent(tx *gorm.DB, event string, data any) error {
+	var hooks []models.Webhook
+	if err := tx.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, hook := range hooks {
+		if !slices.Contains(splitEvents(hook.Events), event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(eventEnvelope{Event: event, OccurredAt: time.Now().UTC(), Data: data})
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitEvents(raw string) []string {
+	var events []string
+	for _, e := range strings.Split(raw, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// roundEventData é o conteúdo dos eventos de rodada. A rodada precisa vir
+// com os semestres carregados.
+func roundEventData(round *models.PlanRound) map[string]any {
+	return map[string]any{
+		"round_id":      round.ID,
+		"base_semester": round.BaseSemester.Code,
+		"period1":       round.Period1.Code,
+		"period2":       round.Period2.Code,
+		"closes_at":     round.ClosesAt,
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formatos de exportação dos relatórios.
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// Table é um relatório já achatado em linhas, pronto para exportar. As
+// células mantêm o tipo (números continuam números na planilha). Keys,
+// quando presente, nomeia as colunas na resposta JSON (Objects).
+type Table struct {
+	Sheet   string
+	Keys    []string
+	Columns []string
+	Rows    [][]any
+}
+
+// Objects devolve as linhas como objetos indexados por Keys.
+func (t Table) Objects() []map[string]any {
+	out := make([]map[string]any, len(t.Rows))
+	for i, row := range t.Rows {
+		obj := make(map[string]any, len(t.Keys))
+		for j, k := range t.Keys {
+			if j < len(row) {
+				obj[k] = row[j]
+			}
+		}
+		out[i] = obj
+	}
+	return out
+}
+
+// ValidExportFormat informa se o formato é suportado.
+func ValidExportFormat(format string) bool {
+	return format == ExportCSV || format == ExportXLSX
+}
+
+// ExportContentType é o tipo MIME do arquivo no formato informado.
+func ExportContentType(format string) string {
+	if format == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write grava a tabela no formato informado: CSV separado por ";" (o
+// mesmo layout aceito na importação) ou XLSX com uma aba.
+func (t Table) Write(w io.Writer, format string) error {
+	switch format {
+	case ExportCSV:
+		return t.writeCSV(w)
+	case ExportXLSX:
+		return t.writeXLSX(w)
+	default:
+		return Invalid("format inválido: use csv ou xlsx")
+	}
+}
+
+func (t Table) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			switch v := v.(type) {
+			case float64:
+				// Vírgula decimal, como nas planilhas institucionais.
+				cells[i] = strings.Replace(fmt.Sprintf("%.2f", v), ".", ",", 1)
+			case nil:
+			default:
+				cells[i] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sheetName adapta o nome às regras do Excel: até 31 caracteres e sem
+// : \ / ? * [ ].
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		return "Relatório"
+	}
+	return name
+}
+
+func (t Table) writeXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := sheetName(t.Sheet)
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+	header := make([]any, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, row := range t.Rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"mime/multipart"
 	"path/filepath"
 	"strconv"
@@ -31,7 +32,8 @@ type ImportSummary struct {
 
 // Process lê o arquivo, valida o cabeçalho e grava todas as linhas em uma
 // única transação: ou a planilha inteira entra, ou nada é alterado (RNF-05).
-func (s *ImportService) Process(file multipart.File, filename string) (*ImportSummary, error) {
+// O resultado é notificado no app a quem enviou o arquivo (userID).
+func (s *ImportService) Process(file multipart.File, filename string, userID uint) (*ImportSummary, error) {
 	var rows [][]string
 	var err error
 
@@ -62,13 +64,42 @@ func (s *ImportService) Process(file multipart.File, filename string) (*ImportSu
 	}
 
 	if err := s.db.Transaction(func(tx *gorm.DB) error {
-		return persistRows(tx, parsed.Rows, summary)
+		touched, err := persistRows(tx, parsed.Rows, summary)
+		if err != nil {
+			return err
+		}
+		if err := recomputeRecords(tx, touched); err != nil {
+			return err
+		}
+		if userID != 0 {
+			if err := notify(tx, UserRecipient(userID), NotifyImportCompleted,
+				importNotificationTitle(summary), filename); err != nil {
+				return err
+			}
+		}
+		return publishEvent(tx, EventImportCompleted, map[string]any{
+			"filename": filename,
+			"summary":  summary,
+		})
 	}); err != nil {
 		return nil, err
 	}
 	return summary, nil
 }
 
+// importNotificationTitle resume o resultado da importação para o aviso no
+// app, destacando as linhas ignoradas.
+func importNotificationTitle(summary *ImportSummary) string {
+	switch summary.SkippedRows {
+	case 0:
+		return fmt.Sprintf("Importação concluída: %d linhas processadas", summary.TotalRows)
+	case 1:
+		return "Importação concluída com 1 linha ignorada"
+	default:
+		return fmt.Sprintf("Importação concluída com %d linhas ignoradas", summary.SkippedRows)
+	}
+}
+
 func readCSV(file multipart.File) ([][]string, error) {
 	reader := csv.NewReader(file)
 	reader.Comma = ';'
@@ -205,11 +236,12 @@ func parseRows(raw [][]string) (*parsedFile, error) {
 
 // persistRows grava as linhas dentro da transação recebida. Cursos,
 // semestres, alunos e registros são pré-carregados em mapas — as buscas
-// repetidas por linha (padrão N+1) são eliminadas.
-func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
+// repetidas por linha (padrão N+1) são eliminadas. Devolve os IDs dos
+// alunos presentes no arquivo, cujos registros derivados são recalculados.
+func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) ([]uint, error) {
 	var allCourses []models.Course
 	if err := tx.Find(&allCourses).Error; err != nil {
-		return err
+		return nil, err
 	}
 	courses := make(map[int]*models.Course, len(allCourses))
 	for i := range allCourses {
@@ -218,7 +250,7 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 
 	var allSemesters []models.Semester
 	if err := tx.Find(&allSemesters).Error; err != nil {
-		return err
+		return nil, err
 	}
 	semesters := make(map[string]*models.Semester, len(allSemesters))
 	for i := range allSemesters {
@@ -227,7 +259,7 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 
 	var allStudents []models.Student
 	if err := tx.Find(&allStudents).Error; err != nil {
-		return err
+		return nil, err
 	}
 	students := make(map[string]*models.Student, len(allStudents))
 	for i := range allStudents {
@@ -237,7 +269,7 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 	type recordKey struct{ StudentID, SemesterID uint }
 	var allRecords []models.AcademicRecord
 	if err := tx.Find(&allRecords).Error; err != nil {
-		return err
+		return nil, err
 	}
 	records := make(map[recordKey]*models.AcademicRecord, len(allRecords))
 	for i := range allRecords {
@@ -245,6 +277,8 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 		records[recordKey{r.StudentID, r.SemesterID}] = r
 	}
 
+	touched := []uint{}
+	seen := map[uint]bool{}
 	for i := range rows {
 		row := &rows[i]
 
@@ -252,14 +286,14 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 		if course == nil {
 			course = &models.Course{Code: row.CourseCode, Name: row.CourseName, Coordinator: row.Coordinator}
 			if err := tx.Create(course).Error; err != nil {
-				return err
+				return nil, err
 			}
 			courses[row.CourseCode] = course
 		} else if course.Name != row.CourseName || course.Coordinator != row.Coordinator {
 			course.Name = row.CourseName
 			course.Coordinator = row.Coordinator
 			if err := tx.Save(course).Error; err != nil {
-				return err
+				return nil, err
 			}
 		}
 
@@ -267,7 +301,7 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 		if semester == nil {
 			semester = &models.Semester{Code: row.SemesterCode}
 			if err := tx.Create(semester).Error; err != nil {
-				return err
+				return nil, err
 			}
 			semesters[row.SemesterCode] = semester
 		}
@@ -283,7 +317,7 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 		student.QuotaType = row.QuotaType
 		student.CourseID = course.ID
 		if err := tx.Save(student).Error; err != nil {
-			return err
+			return nil, err
 		}
 
 		key := recordKey{student.ID, semester.ID}
@@ -301,14 +335,18 @@ func persistRows(tx *gorm.DB, rows []importRow, summary *ImportSummary) error {
 		record.SemestersNoHours = row.SemestersNoHours
 		record.Locks = row.Locks
 		if err := tx.Save(record).Error; err != nil {
-			return err
+			return nil, err
 		}
 
+		if !seen[student.ID] {
+			seen[student.ID] = true
+			touched = append(touched, student.ID)
+		}
 		if isNew {
 			summary.RecordsCreated++
 		} else {
 			summary.RecordsUpdated++
 		}
 	}
-	return nil
+	return touched, nil
 }
@@ -1,15 +1,16 @@
 package services
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
 	"gorm.io/gorm"
 
 	"adamanagement/backend/internal/models"
 )
 
-// NearGraduationMaxPending é o limite de disciplinas obrigatórias
-// pendentes usado no painel de indicadores (RN03).
-const NearGraduationMaxPending = 6
-
 type IndicatorsService struct {
 	db *gorm.DB
 }
@@ -46,16 +47,23 @@ type NearGraduationStudent struct {
 	PendingObligatory int    `json:"pending_obligatory"`
 }
 
-func (s *IndicatorsService) Dashboard(semesterID string) (*DashboardData, error) {
+// Dashboard consolida o semestre para os cursos do escopo.
+func (s *IndicatorsService) Dashboard(semesterID string, scope CourseScope) (*DashboardData, error) {
 	if semesterID == "" {
 		return nil, Invalid("semester_id é obrigatório")
 	}
 
+	triage, err := loadTriage(s.db)
+	if err != nil {
+		return nil, err
+	}
+
 	var dashboard DashboardData
 
-	if err := s.db.Model(&models.AcademicRecord{}).
+	distribution := s.db.Model(&models.AcademicRecord{}).
 		Select("status as name, COUNT(id) as value").
-		Where("semester_id = ?", semesterID).
+		Where("semester_id = ?", semesterID)
+	if err := scope.applyStudents(distribution, "student_id").
 		Group("status").
 		Scan(&dashboard.StatusDistribution).Error; err != nil {
 		return nil, err
@@ -66,7 +74,7 @@ func (s *IndicatorsService) Dashboard(semesterID string) (*DashboardData, error)
 		Joins("JOIN students ON students.id = academic_records.student_id").
 		Where("academic_records.semester_id = ?", semesterID).
 		Where("academic_records.deleted_at IS NULL")
-	if err := criticalScope(critical).
+	if err := triage.criticalScope(scope.apply(critical, "students.course_id")).
 		Order("academic_records.locks DESC, academic_records.semesters_no_hours DESC").
 		Scan(&dashboard.CriticalStudents).Error; err != nil {
 		return nil, err
@@ -78,7 +86,7 @@ func (s *IndicatorsService) Dashboard(semesterID string) (*DashboardData, error)
 		Joins("JOIN courses ON courses.id = students.course_id").
 		Where("academic_records.semester_id = ?", semesterID).
 		Where("academic_records.deleted_at IS NULL")
-	if err := nearGraduationScope(near, NearGraduationMaxPending).
+	if err := triage.nearGraduationScope(scope.apply(near, "students.course_id")).
 		Order("academic_records.pending_obligatory ASC").
 		Scan(&dashboard.NearGraduationStudents).Error; err != nil {
 		return nil, err
@@ -86,3 +94,611 @@ func (s *IndicatorsService) Dashboard(semesterID string) (*DashboardData, error)
 
 	return &dashboard, nil
 }
+
+// TrendsFilter delimita a série histórica: From e To são códigos de
+// semestre (ex.: "2023/1"), inclusivos e opcionais; ByCourse separa uma
+// série por curso em vez da série consolidada do escopo.
+type TrendsFilter struct {
+	From     string
+	To       string
+	ByCourse bool
+	Scope    CourseScope
+}
+
+// semestersInRange resolve os IDs dos semestres entre from e to
+// (inclusivos; vazio deixa o lado em aberto), comparando pela ordem do
+// calendário e não pelo texto do código. Códigos malformados e
+// intervalos invertidos são recusados.
+func semestersInRange(db *gorm.DB, from, to string) ([]uint, error) {
+	lo, hi := math.MinInt, math.MaxInt
+	if from != "" {
+		i, ok := semesterIndex(from)
+		if !ok {
+			return nil, Invalid("from inválido: use o formato AAAA/1 ou AAAA/2")
+		}
+		lo = i
+	}
+	if to != "" {
+		i, ok := semesterIndex(to)
+		if !ok {
+			return nil, Invalid("to inválido: use o formato AAAA/1 ou AAAA/2")
+		}
+		hi = i
+	}
+	if lo > hi {
+		return nil, Invalid("from deve ser anterior ou igual a to")
+	}
+
+	var semesters []models.Semester
+	if err := db.Select("id", "code").Find(&semesters).Error; err != nil {
+		return nil, err
+	}
+	ids := []uint{}
+	for _, sem := range semesters {
+		if i, ok := semesterIndex(sem.Code); ok && i >= lo && i <= hi {
+			ids = append(ids, sem.ID)
+		}
+	}
+	return ids, nil
+}
+
+// TrendsData é a evolução dos indicadores semestre a semestre. Semesters
+// é o eixo comum; cada série tem um ponto por semestre do eixo, na mesma
+// ordem, com zeros onde o curso não tem registros.
+type TrendsData struct {
+	Semesters []string      `json:"semesters"`
+	Series    []TrendSeries `json:"series"`
+}
+
+// TrendSeries é a série de um curso; na série consolidada CourseID é 0 e
+// Course fica vazio.
+type TrendSeries struct {
+	CourseID uint         `json:"course_id,omitempty"`
+	Course   string       `json:"course,omitempty"`
+	Points   []TrendPoint `json:"points"`
+}
+
+// StatusCounts é a contagem de alunos por enquadramento, com o
+// percentual de cada um sobre o total.
+type StatusCounts struct {
+	Total      int64   `json:"total"`
+	Regular    int64   `json:"regular"`
+	PAE        int64   `json:"pae"`
+	PIC        int64   `json:"pic"`
+	RegularPct float64 `json:"regular_pct"`
+	PAEPct     float64 `json:"pae_pct"`
+	PICPct     float64 `json:"pic_pct"`
+}
+
+// add soma n alunos do enquadramento informado; outros enquadramentos
+// entram apenas no total.
+func (c *StatusCounts) add(status string, n int64) {
+	c.Total += n
+	switch status {
+	case models.StatusRegular:
+		c.Regular += n
+	case models.StatusPAE:
+		c.PAE += n
+	case models.StatusPIC:
+		c.PIC += n
+	}
+}
+
+// fillPercents calcula os percentuais a partir das contagens.
+func (c *StatusCounts) fillPercents() {
+	c.RegularPct = percent(c.Regular, c.Total)
+	c.PAEPct = percent(c.PAE, c.Total)
+	c.PICPct = percent(c.PIC, c.Total)
+}
+
+// TrendPoint resume um semestre: alunos por enquadramento e as contagens
+// de triagem (RN02 e RN03).
+type TrendPoint struct {
+	Semester string `json:"semester"`
+	StatusCounts
+	Critical       int64 `json:"critical"`
+	NearGraduation int64 `json:"near_graduation"`
+}
+
+// trendCount é uma linha agregada por semestre e curso.
+type trendCount struct {
+	Code     string
+	CourseID uint
+	Status   string
+	N        int64
+}
+
+// Trends calcula os indicadores de cada semestre do intervalo para os
+// cursos do escopo. Entram no eixo os semestres com registros acadêmicos
+// visíveis; os critérios de triagem são os mesmos do painel (rules.go).
+func (s *IndicatorsService) Trends(f TrendsFilter) (*TrendsData, error) {
+	ranged := f.From != "" || f.To != ""
+	var semesterIDs []uint
+	if ranged {
+		var err error
+		if semesterIDs, err = semestersInRange(s.db, f.From, f.To); err != nil {
+			return nil, err
+		}
+	}
+
+	base := func() *gorm.DB {
+		q := s.db.Table("academic_records").
+			Joins("JOIN students ON students.id = academic_records.student_id").
+			Joins("JOIN semesters ON semesters.id = academic_records.semester_id").
+			Where("academic_records.deleted_at IS NULL")
+		switch {
+		case !ranged:
+		case len(semesterIDs) == 0:
+			q = q.Where("1 = 0")
+		default:
+			q = q.Where("academic_records.semester_id IN ?", semesterIDs)
+		}
+		return f.Scope.apply(q, "students.course_id")
+	}
+	const groupBy = "semesters.code, students.course_id"
+
+	triage, err := loadTriage(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var distribution, critical, near []trendCount
+	if err := base().
+		Select(groupBy + ", academic_records.status, COUNT(*) AS n").
+		Group(groupBy + ", academic_records.status").
+		Scan(&distribution).Error; err != nil {
+		return nil, err
+	}
+	if err := triage.criticalScope(base()).
+		Select(groupBy + ", COUNT(*) AS n").
+		Group(groupBy).
+		Scan(&critical).Error; err != nil {
+		return nil, err
+	}
+	if err := triage.nearGraduationScope(base()).
+		Select(groupBy + ", COUNT(*) AS n").
+		Group(groupBy).
+		Scan(&near).Error; err != nil {
+		return nil, err
+	}
+
+	// Pontos indexados por curso (0 na série consolidada) e semestre.
+	points := map[uint]map[string]*TrendPoint{}
+	point := func(c trendCount) *TrendPoint {
+		key := uint(0)
+		if f.ByCourse {
+			key = c.CourseID
+		}
+		if points[key] == nil {
+			points[key] = map[string]*TrendPoint{}
+		}
+		p := points[key][c.Code]
+		if p == nil {
+			p = &TrendPoint{Semester: c.Code}
+			points[key][c.Code] = p
+		}
+		return p
+	}
+
+	codes := map[string]bool{}
+	for _, c := range distribution {
+		codes[c.Code] = true
+		point(c).add(c.Status, c.N)
+	}
+	for _, c := range critical {
+		point(c).Critical += c.N
+	}
+	for _, c := range near {
+		point(c).NearGraduation += c.N
+	}
+
+	data := TrendsData{Semesters: make([]string, 0, len(codes)), Series: []TrendSeries{}}
+	for code := range codes {
+		data.Semesters = append(data.Semesters, code)
+	}
+	sort.Slice(data.Semesters, func(i, j int) bool {
+		a, _ := semesterIndex(data.Semesters[i])
+		b, _ := semesterIndex(data.Semesters[j])
+		if a != b {
+			return a < b
+		}
+		return data.Semesters[i] < data.Semesters[j]
+	})
+
+	keys := make([]uint, 0, len(points))
+	for key := range points {
+		keys = append(keys, key)
+	}
+	names, err := s.courseNames(keys)
+	if err != nil {
+		return nil, err
+	}
+	if !f.ByCourse && len(keys) == 0 {
+		keys = append(keys, 0)
+	}
+	for _, key := range keys {
+		series := TrendSeries{CourseID: key, Course: names[key], Points: make([]TrendPoint, len(data.Semesters))}
+		for i, code := range data.Semesters {
+			if p := points[key][code]; p != nil {
+				p.fillPercents()
+				series.Points[i] = *p
+			} else {
+				series.Points[i] = TrendPoint{Semester: code}
+			}
+		}
+		data.Series = append(data.Series, series)
+	}
+	sort.Slice(data.Series, func(i, j int) bool { return data.Series[i].Course < data.Series[j].Course })
+	return &data, nil
+}
+
+// Dimensões do recorte de indicadores (Breakdown).
+const (
+	BreakdownByCourse = "course"
+	BreakdownByQuota  = "quota"
+	BreakdownByCohort = "cohort"
+)
+
+// noQuota rotula os alunos sem tipo de cota na planilha.
+const noQuota = "Não informado"
+
+// BreakdownData são os indicadores do semestre recortados por uma
+// dimensão (curso, tipo de cota ou coorte de ingresso), com o conjunto
+// do escopo em Overall para comparação.
+type BreakdownData struct {
+	By      string           `json:"by"`
+	Groups  []BreakdownGroup `json:"groups"`
+	Overall BreakdownGroup   `json:"overall"`
+}
+
+// BreakdownGroup é um grupo do recorte. IntegralizationPct é a média,
+// entre os alunos do grupo, da razão IntegralizedHours/TotalHours (em
+// porcentagem); alunos sem carga horária total ficam fora da média. No
+// recorte por curso, Key é o nome e CourseID identifica o curso — dois
+// cursos de mesmo nome continuam em grupos separados.
+type BreakdownGroup struct {
+	Key      string `json:"key"`
+	CourseID uint   `json:"course_id,omitempty"`
+	StatusCounts
+	IntegralizationPct float64 `json:"integralization_pct"`
+
+	ratioSum float64
+	ratioN   int64
+}
+
+func (g *BreakdownGroup) add(r breakdownRow) {
+	g.StatusCounts.add(r.Status, r.N)
+	g.ratioSum += r.RatioSum
+	g.ratioN += r.RatioN
+}
+
+func (g *BreakdownGroup) fill() {
+	g.fillPercents()
+	if g.ratioN > 0 {
+		g.IntegralizationPct = math.Round(g.ratioSum*10000/float64(g.ratioN)) / 100
+	}
+}
+
+// breakdownRow é uma linha agregada por grupo e enquadramento.
+type breakdownRow struct {
+	CourseID    uint
+	Course      string
+	QuotaType   string
+	EntryYear   int
+	EntryPeriod string
+	Status      string
+	N           int64
+	RatioSum    float64
+	RatioN      int64
+}
+
+// key devolve o rótulo do grupo da linha na dimensão informada.
+func (r breakdownRow) key(by string) string {
+	switch by {
+	case BreakdownByCourse:
+		return r.Course
+	case BreakdownByQuota:
+		if strings.TrimSpace(r.QuotaType) == "" {
+			return noQuota
+		}
+		return r.QuotaType
+	default:
+		return fmt.Sprintf("%d/%s", r.EntryYear, r.EntryPeriod)
+	}
+}
+
+// Breakdown recorta os indicadores do semestre pela dimensão informada,
+// para os cursos do escopo — base dos relatórios de equidade entre
+// cotistas e ampla concorrência.
+func (s *IndicatorsService) Breakdown(semesterID, by string, scope CourseScope) (*BreakdownData, error) {
+	if semesterID == "" {
+		return nil, Invalid("semester_id é obrigatório")
+	}
+	var columns string
+	switch by {
+	case BreakdownByCourse:
+		// Agrupa pelo curso; o nome é só o rótulo.
+		columns = "students.course_id, courses.name AS course"
+	case BreakdownByQuota:
+		columns = "students.quota_type"
+	case BreakdownByCohort:
+		columns = "students.entry_year, students.entry_period"
+	default:
+		return nil, Invalid("by inválido: use course, quota ou cohort")
+	}
+	group := strings.ReplaceAll(columns, " AS course", "")
+
+	q := s.db.Table("academic_records").
+		Select(columns+", academic_records.status, COUNT(*) AS n, "+
+			"SUM(CASE WHEN academic_records.total_hours > 0 THEN 1.0 * academic_records.integralized_hours / academic_records.total_hours ELSE 0 END) AS ratio_sum, "+
+			"SUM(CASE WHEN academic_records.total_hours > 0 THEN 1 ELSE 0 END) AS ratio_n").
+		Joins("JOIN students ON students.id = academic_records.student_id").
+		Joins("JOIN courses ON courses.id = students.course_id").
+		Where("academic_records.semester_id = ?", semesterID).
+		Where("academic_records.deleted_at IS NULL")
+	var rows []breakdownRow
+	if err := scope.apply(q, "students.course_id").
+		Group(group + ", academic_records.status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	data := BreakdownData{By: by, Groups: []BreakdownGroup{}, Overall: BreakdownGroup{Key: "Total"}}
+	type groupID struct {
+		courseID uint
+		key      string
+	}
+	index := map[groupID]int{}
+	for _, r := range rows {
+		key := r.key(by)
+		id := groupID{r.CourseID, key}
+		i, ok := index[id]
+		if !ok {
+			i = len(data.Groups)
+			index[id] = i
+			data.Groups = append(data.Groups, BreakdownGroup{Key: key, CourseID: r.CourseID})
+		}
+		data.Groups[i].add(r)
+		data.Overall.add(r)
+	}
+	for i := range data.Groups {
+		data.Groups[i].fill()
+	}
+	data.Overall.fill()
+	sort.Slice(data.Groups, func(i, j int) bool {
+		a, b := data.Groups[i], data.Groups[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.CourseID < b.CourseID
+	})
+	return &data, nil
+}
+
+// CohortsFilter restringe a tabela de retenção a um curso e/ou a um ano
+// de ingresso.
+type CohortsFilter struct {
+	CourseID  *uint
+	EntryYear *int
+	Scope     CourseScope
+}
+
+// CohortRetention acompanha uma coorte de ingresso (ano/período) de um
+// curso em cada semestre importado a partir do ingresso.
+type CohortRetention struct {
+	CourseID  uint             `json:"course_id"`
+	Course    string           `json:"course"`
+	Cohort    string           `json:"cohort"`
+	Size      int64            `json:"size"`
+	Semesters []RetentionPoint `json:"semesters"`
+}
+
+// RetentionPoint é a situação da coorte em um semestre. Os percentuais
+// são sobre o tamanho da coorte; Other conta os presentes fora de
+// regularidade, PAE e PIC (bloqueio, desligamento) e Gone os que não têm
+// registro no semestre.
+type RetentionPoint struct {
+	Semester   string  `json:"semester"`
+	Present    int64   `json:"present"`
+	Regular    int64   `json:"regular"`
+	PAE        int64   `json:"pae"`
+	PIC        int64   `json:"pic"`
+	Other      int64   `json:"other"`
+	Gone       int64   `json:"gone"`
+	PresentPct float64 `json:"present_pct"`
+	RegularPct float64 `json:"regular_pct"`
+	PAEPct     float64 `json:"pae_pct"`
+	PICPct     float64 `json:"pic_pct"`
+	OtherPct   float64 `json:"other_pct"`
+	GonePct    float64 `json:"gone_pct"`
+}
+
+// cohortKey identifica a coorte de um curso.
+type cohortKey struct {
+	courseID    uint
+	entryYear   int
+	entryPeriod string
+}
+
+// cohortRow é uma linha das consultas da retenção: a coorte e, na
+// contagem por semestre, o código e o enquadramento.
+type cohortRow struct {
+	CourseID    uint
+	Course      string
+	EntryYear   int
+	EntryPeriod string
+	Code        string
+	Status      string
+	N           int64
+}
+
+func (r cohortRow) key() cohortKey { return cohortKey{r.CourseID, r.EntryYear, r.EntryPeriod} }
+
+// Cohorts monta a tabela de sobrevivência das coortes de ingresso (RN34):
+// para cada curso e coorte, a situação dos alunos em cada semestre
+// importado a partir do semestre de ingresso. Alunos sem ano de ingresso
+// ficam de fora.
+func (s *IndicatorsService) Cohorts(f CohortsFilter) ([]CohortRetention, error) {
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = q.Where("students.entry_year > 0")
+		if f.CourseID != nil {
+			q = q.Where("students.course_id = ?", *f.CourseID)
+		}
+		if f.EntryYear != nil {
+			q = q.Where("students.entry_year = ?", *f.EntryYear)
+		}
+		return f.Scope.apply(q, "students.course_id")
+	}
+
+	var sizes []cohortRow
+	if err := filter(s.db.Table("students").
+		Select("students.course_id, courses.name AS course, students.entry_year, students.entry_period, COUNT(*) AS n").
+		Joins("JOIN courses ON courses.id = students.course_id").
+		Where("students.deleted_at IS NULL")).
+		Group("students.course_id, courses.name, students.entry_year, students.entry_period").
+		Scan(&sizes).Error; err != nil {
+		return nil, err
+	}
+	if len(sizes) == 0 {
+		return []CohortRetention{}, nil
+	}
+
+	var counts []cohortRow
+	if err := filter(s.db.Table("academic_records").
+		Select("students.course_id, students.entry_year, students.entry_period, semesters.code, academic_records.status, COUNT(*) AS n").
+		Joins("JOIN students ON students.id = academic_records.student_id").
+		Joins("JOIN semesters ON semesters.id = academic_records.semester_id").
+		Where("academic_records.deleted_at IS NULL")).
+		Group("students.course_id, students.entry_year, students.entry_period, semesters.code, academic_records.status").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	var codes []string
+	if err := s.db.Model(&models.Semester{}).Pluck("code", &codes).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		a, _ := semesterIndex(codes[i])
+		b, _ := semesterIndex(codes[j])
+		return a < b
+	})
+
+	type cell struct {
+		key  cohortKey
+		code string
+	}
+	byCell := map[cell]*RetentionPoint{}
+	for _, c := range counts {
+		p := byCell[cell{c.key(), c.Code}]
+		if p == nil {
+			p = &RetentionPoint{Semester: c.Code}
+			byCell[cell{c.key(), c.Code}] = p
+		}
+		p.Present += c.N
+		switch c.Status {
+		case models.StatusRegular:
+			p.Regular += c.N
+		case models.StatusPAE:
+			p.PAE += c.N
+		case models.StatusPIC:
+			p.PIC += c.N
+		default:
+			p.Other += c.N
+		}
+	}
+
+	out := make([]CohortRetention, 0, len(sizes))
+	for _, sz := range sizes {
+		cohort := fmt.Sprintf("%d/%s", sz.EntryYear, sz.EntryPeriod)
+		r := CohortRetention{CourseID: sz.CourseID, Course: sz.Course, Cohort: cohort, Size: sz.N, Semesters: []RetentionPoint{}}
+		for _, code := range codes {
+			// semestersBetween é -1 para códigos fora do formato "2025/1"
+			// e negativo para os anteriores ao ingresso.
+			if semestersBetween(sz.EntryYear, sz.EntryPeriod, code) < 0 {
+				continue
+			}
+			p := RetentionPoint{Semester: code}
+			if found := byCell[cell{sz.key(), code}]; found != nil {
+				p = *found
+			}
+			p.Gone = max(sz.N-p.Present, 0)
+			p.PresentPct = percent(p.Present, sz.N)
+			p.RegularPct = percent(p.Regular, sz.N)
+			p.PAEPct = percent(p.PAE, sz.N)
+			p.PICPct = percent(p.PIC, sz.N)
+			p.OtherPct = percent(p.Other, sz.N)
+			p.GonePct = percent(p.Gone, sz.N)
+			r.Semesters = append(r.Semesters, p)
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Course != out[j].Course {
+			return out[i].Course < out[j].Course
+		}
+		return out[i].Cohort < out[j].Cohort
+	})
+	return out, nil
+}
+
+// CohortsTable achata a tabela de retenção para exportação: uma linha por
+// coorte e semestre.
+func CohortsTable(cohorts []CohortRetention) Table {
+	t := Table{
+		Sheet: "Retenção por coorte",
+		Columns: []string{
+			"Curso", "Coorte", "Tamanho", "Semestre",
+			"Presentes", "% Presentes", "Em regularidade", "% Em regularidade",
+			"PAE", "% PAE", "PIC", "% PIC", "Outros", "% Outros", "Ausentes", "% Ausentes",
+		},
+	}
+	for _, c := range cohorts {
+		for _, p := range c.Semesters {
+			t.Rows = append(t.Rows, []any{
+				c.Course, c.Cohort, c.Size, p.Semester,
+				p.Present, p.PresentPct, p.Regular, p.RegularPct,
+				p.PAE, p.PAEPct, p.PIC, p.PICPct, p.Other, p.OtherPct, p.Gone, p.GonePct,
+			})
+		}
+	}
+	return t
+}
+
+// BreakdownTable achata o recorte para exportação: uma linha por grupo e
+// a linha do conjunto ao final.
+func BreakdownTable(data *BreakdownData) Table {
+	t := Table{
+		Sheet: "Indicadores",
+		Columns: []string{
+			"Grupo", "Alunos", "Em regularidade", "% Em regularidade",
+			"PAE", "% PAE", "PIC", "% PIC", "% Integralização média",
+		},
+	}
+	for _, g := range append(data.Groups, data.Overall) {
+		t.Rows = append(t.Rows, []any{
+			g.Key, g.Total, g.Regular, g.RegularPct, g.PAE, g.PAEPct, g.PIC, g.PICPct, g.IntegralizationPct,
+		})
+	}
+	return t
+}
+
+// courseNames devolve o nome de cada curso pelo ID (o ID 0 é ignorado).
+func (s *IndicatorsService) courseNames(ids []uint) (map[uint]string, error) {
+	names := map[uint]string{}
+	var courses []models.Course
+	if err := s.db.Select("id", "name").Where("id IN ?", append(ids, 0)).Find(&courses).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range courses {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+// percent é n sobre total em porcentagem, com duas casas decimais.
+func percent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*10000/float64(total)) / 100
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestTrendsSeries(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewIndicatorsService(db)
+
+	seedStudentWithStatus(t, db, "1", "2024/1", models.StatusRegular)
+	seedStudentWithStatus(t, db, "2", "2024/1", models.StatusPAE)
+	crit := seedStudentWithStatus(t, db, "3", "2024/2", models.StatusRegular)
+	seedStudentWithStatus(t, db, "4", "2024/2", models.StatusPIC)
+	db.Model(&models.AcademicRecord{}).Where("student_id = ?", crit.ID).
+		Updates(map[string]any{"locks": 2, "pending_obligatory": 10})
+	seedStudentWithStatus(t, db, "5", "2023/2", models.StatusRegular)
+
+	// Segundo curso, com um aluno em 2024/2.
+	other := models.Course{Code: 2, Name: "Outro Curso"}
+	db.Create(&other)
+	var sem models.Semester
+	db.Where("code = ?", "2024/2").First(&sem)
+	student := models.Student{Registration: "6", Name: "Aluno 6", CourseID: other.ID}
+	db.Create(&student)
+	db.Create(&models.AcademicRecord{StudentID: student.ID, SemesterID: sem.ID, Status: models.StatusRegular, PendingObligatory: 3})
+
+	data, err := svc.Trends(TrendsFilter{From: "2024/1", To: "2024/2"})
+	if err != nil {
+		t.Fatalf("Trends: %v", err)
+	}
+	if len(data.Semesters) != 2 || data.Semesters[0] != "2024/1" || data.Semesters[1] != "2024/2" {
+		t.Fatalf("eixo: %v; esperado [2024/1 2024/2]", data.Semesters)
+	}
+	if len(data.Series) != 1 {
+		t.Fatalf("séries consolidadas: %d; esperado 1", len(data.Series))
+	}
+	first, second := data.Series[0].Points[0], data.Series[0].Points[1]
+	if first.Total != 2 || first.Regular != 1 || first.PAE != 1 || first.RegularPct != 50 {
+		t.Errorf("2024/1: %+v", first)
+	}
+	// Em 2024/2 só o aluno do outro curso tem até 6 obrigatórias pendentes.
+	if second.Total != 3 || second.PIC != 1 || second.Critical != 1 || second.NearGraduation != 1 || second.PICPct != 33.33 {
+		t.Errorf("2024/2: %+v", second)
+	}
+
+	byCourse, err := svc.Trends(TrendsFilter{From: "2024/1", To: "2024/2", ByCourse: true})
+	if err != nil {
+		t.Fatalf("Trends por curso: %v", err)
+	}
+	if len(byCourse.Series) != 2 || byCourse.Series[0].Course != "Curso Teste" || byCourse.Series[1].Course != "Outro Curso" {
+		t.Fatalf("séries por curso: %+v", byCourse.Series)
+	}
+	if p := byCourse.Series[1].Points; p[0].Total != 0 || p[1].Total != 1 || p[1].NearGraduation != 1 {
+		t.Errorf("série do outro curso deveria ter zero em 2024/1 e um aluno em 2024/2: %+v", p)
+	}
+
+	scoped, err := svc.Trends(TrendsFilter{ByCourse: true, Scope: RestrictTo([]uint{other.ID})})
+	if err != nil {
+		t.Fatalf("Trends com escopo: %v", err)
+	}
+	if len(scoped.Series) != 1 || scoped.Series[0].CourseID != other.ID {
+		t.Errorf("escopo deveria limitar às séries do curso vinculado: %+v", scoped.Series)
+	}
+
+	if _, err := svc.Trends(TrendsFilter{From: "2025/1", To: "2024/1"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("intervalo invertido deveria ser inválido; obtive %v", err)
+	}
+	for _, f := range []TrendsFilter{{From: "2024-1"}, {To: "2024/3"}, {From: "2024/1", To: "x"}} {
+		if _, err := svc.Trends(f); !errors.Is(err, ErrInvalid) {
+			t.Errorf("código malformado %+v deveria ser inválido; obtive %v", f, err)
+		}
+	}
+
+	// O intervalo segue a ordem do calendário: 2023/2 fica fora de
+	// from=2024/1 e o eixo sai em ordem cronológica.
+	open, err := svc.Trends(TrendsFilter{From: "2024/1"})
+	if err != nil || len(open.Semesters) != 2 || open.Semesters[0] != "2024/1" {
+		t.Errorf("from aberto: %+v, %v", open, err)
+	}
+	if none, err := svc.Trends(TrendsFilter{From: "2030/1"}); err != nil || len(none.Semesters) != 0 {
+		t.Errorf("intervalo sem semestres deveria vir vazio: %+v, %v", none, err)
+	}
+}
+
+func TestBreakdownByQuotaAndCohort(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewIndicatorsService(db)
+
+	seed := func(registration, quota string, year int, status string, integralized, total int) {
+		student := seedStudentWithStatus(t, db, registration, "2024/1", status)
+		db.Model(student).Updates(map[string]any{"quota_type": quota, "entry_year": year, "entry_period": "1"})
+		db.Model(&models.AcademicRecord{}).Where("student_id = ?", student.ID).
+			Updates(map[string]any{"integralized_hours": integralized, "total_hours": total})
+	}
+	seed("1", "L1", 2020, models.StatusRegular, 1500, 3000)
+	seed("2", "L1", 2021, models.StatusPAE, 500, 3000)
+	seed("3", "", 2021, models.StatusRegular, 3000, 3000)
+	seed("4", "", 2021, models.StatusPIC, 0, 0)
+
+	var sem models.Semester
+	db.Where("code = ?", "2024/1").First(&sem)
+	semesterID := strconv.FormatUint(uint64(sem.ID), 10)
+
+	quota, err := svc.Breakdown(semesterID, BreakdownByQuota, AllCourses())
+	if err != nil {
+		t.Fatalf("Breakdown: %v", err)
+	}
+	if len(quota.Groups) != 2 || quota.Groups[0].Key != "L1" || quota.Groups[1].Key != noQuota {
+		t.Fatalf("grupos por cota: %+v", quota.Groups)
+	}
+	l1, none := quota.Groups[0], quota.Groups[1]
+	if l1.Total != 2 || l1.PAEPct != 50 || l1.IntegralizationPct != 33.33 {
+		t.Errorf("cota L1: %+v", l1)
+	}
+	// O aluno sem carga horária total não entra na média de integralização.
+	if none.Total != 2 || none.PIC != 1 || none.IntegralizationPct != 100 {
+		t.Errorf("sem cota: %+v", none)
+	}
+	if quota.Overall.Total != 4 || quota.Overall.RegularPct != 50 {
+		t.Errorf("conjunto: %+v", quota.Overall)
+	}
+
+	cohort, err := svc.Breakdown(semesterID, BreakdownByCohort, AllCourses())
+	if err != nil {
+		t.Fatalf("Breakdown por coorte: %v", err)
+	}
+	if len(cohort.Groups) != 2 || cohort.Groups[0].Key != "2020/1" || cohort.Groups[1].Total != 3 {
+		t.Errorf("coortes: %+v", cohort.Groups)
+	}
+
+	// Cursos homônimos ficam em grupos separados, identificados pelo ID.
+	twin := models.Course{Code: 2, Name: "Curso Teste"}
+	db.Create(&twin)
+	db.Model(&models.Student{}).Where("registration = ?", "4").Update("course_id", twin.ID)
+	byCourse, err := svc.Breakdown(semesterID, BreakdownByCourse, AllCourses())
+	if err != nil {
+		t.Fatalf("Breakdown por curso: %v", err)
+	}
+	if g := byCourse.Groups; len(g) != 2 || g[0].Key != g[1].Key || g[0].CourseID == g[1].CourseID ||
+		g[0].Total != 3 || g[1].Total != 1 || g[1].CourseID != twin.ID {
+		t.Errorf("cursos homônimos: %+v", g)
+	}
+
+	if _, err := svc.Breakdown(semesterID, "gender", AllCourses()); !errors.Is(err, ErrInvalid) {
+		t.Errorf("dimensão desconhecida deveria ser inválida; obtive %v", err)
+	}
+}
+
+func TestCohortRetention(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewIndicatorsService(db)
+
+	// Coorte 2024/1 com quatro alunos: em 2024/2 um passou a PAE, um foi
+	// desligado e outro não aparece mais.
+	var ids []uint
+	for _, reg := range []string{"1", "2", "3", "4"} {
+		s := seedStudentWithStatus(t, db, reg, "2024/1", models.StatusRegular)
+		db.Model(s).Updates(map[string]any{"entry_year": 2024, "entry_period": "1"})
+		ids = append(ids, s.ID)
+	}
+	var sem models.Semester
+	db.FirstOrCreate(&sem, models.Semester{Code: "2024/2"})
+	for i, status := range []string{models.StatusRegular, models.StatusPAE, "Desligamento"} {
+		db.Create(&models.AcademicRecord{StudentID: ids[i], SemesterID: sem.ID, Status: status})
+	}
+	// Semestre anterior ao ingresso fica fora da tabela; aluno sem ano de
+	// ingresso fica fora das coortes.
+	seedStudentWithStatus(t, db, "5", "2023/2", models.StatusRegular)
+
+	cohorts, err := svc.Cohorts(CohortsFilter{})
+	if err != nil {
+		t.Fatalf("Cohorts: %v", err)
+	}
+	if len(cohorts) != 1 || cohorts[0].Cohort != "2024/1" || cohorts[0].Size != 4 || len(cohorts[0].Semesters) != 2 {
+		t.Fatalf("coortes: %+v", cohorts)
+	}
+	first, second := cohorts[0].Semesters[0], cohorts[0].Semesters[1]
+	if first.Semester != "2024/1" || first.PresentPct != 100 || first.Gone != 0 {
+		t.Errorf("2024/1: %+v", first)
+	}
+	if second.Present != 3 || second.RegularPct != 25 || second.PAEPct != 25 || second.Other != 1 || second.Gone != 1 || second.GonePct != 25 {
+		t.Errorf("2024/2: %+v", second)
+	}
+
+	year := 2023
+	if none, _ := svc.Cohorts(CohortsFilter{EntryYear: &year}); len(none) != 0 {
+		t.Errorf("nenhuma coorte de 2023: %+v", none)
+	}
+	if scoped, _ := svc.Cohorts(CohortsFilter{Scope: RestrictTo(nil)}); len(scoped) != 0 {
+		t.Errorf("escopo vazio não deveria ver coortes: %+v", scoped)
+	}
+
+	table := CohortsTable(cohorts)
+	if len(table.Rows) != 2 || len(table.Rows[0]) != len(table.Columns) {
+		t.Fatalf("tabela exportada: %+v", table)
+	}
+	var csv strings.Builder
+	if err := table.Write(&csv, ExportCSV); err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if !strings.Contains(csv.String(), "Curso Teste;2024/1;4;2024/2;3;75,00;") {
+		t.Errorf("linha do CSV: %q", csv.String())
+	}
+	if err := table.Write(io.Discard, ExportXLSX); err != nil {
+		t.Errorf("XLSX: %v", err)
+	}
+	if err := table.Write(io.Discard, "pdf"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("formato desconhecido deveria ser inválido; obtive %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// InvitationTTL é a validade do link de ativação; reenviar o convite gera
+// um link novo, com validade renovada.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Ações de auditoria do ciclo do convite.
+const (
+	AuditInvitationCreated  = "user.invitation.created"
+	AuditInvitationResent   = "user.invitation.resent"
+	AuditInvitationRevoked  = "user.invitation.revoked"
+	AuditInvitationAccepted = "user.invitation.accepted"
+)
+
+// Situações de um convite no filtro da listagem.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// errInvalidInvitation é a resposta única a link desconhecido, usado,
+// revogado ou expirado.
+var errInvalidInvitation = Unauthorized("convite inválido ou expirado")
+
+// InvitationService convida usuários da coordenação e ativa as contas:
+// o administrador informa nome, e-mail e papel, e o convidado define a
+// própria senha pelo link enviado por e-mail.
+type InvitationService struct {
+	db *gorm.DB
+	// appURL é o endereço do frontend, base do link de ativação.
+	appURL string
+}
+
+func NewInvitationService(db *gorm.DB, appURL string) *InvitationService {
+	return &InvitationService{db: db, appURL: strings.TrimSuffix(appURL, "/")}
+}
+
+type InviteInput struct {
+	Name  string
+	Email string
+	Role  string
+}
+
+// InvitationLink é o convite com o link de ativação em claro, devolvido
+// apenas a quem convidou ou reenviou — útil quando o e-mail não chega.
+type InvitationLink struct {
+	Invitation models.UserInvitation
+	URL        string
+}
+
+// Invite cria o usuário pendente e o convite, e põe o e-mail com o link
+// na outbox, tudo na mesma transação. Só se convida para papéis cujas
+// permissões estejam todas no papel de quem convida.
+func (s *InvitationService) Invite(in InviteInput, actor Actor) (*InvitationLink, error) {
+	name := strings.TrimSpace(in.Name)
+	email := strings.TrimSpace(in.Email)
+	if name == "" || email == "" {
+		return nil, Invalid("nome e e-mail são obrigatórios")
+	}
+	role := in.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	if err := ensureRole(s.db, role); err != nil {
+		return nil, err
+	}
+	if err := coversRole(s.db, actor.Role, role); err != nil {
+		return nil, err
+	}
+
+	var link *InvitationLink
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user := models.User{Name: name, Email: email, Role: role, InvitationPending: true}
+		if err := tx.Create(&user).Error; err != nil {
+			if isUniqueViolation(err) {
+				return Conflict("Este e-mail já está cadastrado no sistema")
+			}
+			return err
+		}
+		inv := models.UserInvitation{
+			UserID:          user.ID,
+			Name:            name,
+			Email:           email,
+			Role:            role,
+			CreatedByUserID: actor.UserID,
+		}
+		var err error
+		if link, err = s.send(tx, &inv); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditInvitationCreated, userTarget(email), "papel: "+role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// List devolve os convites na situação pedida (pendentes, por padrão; "all"
+// traz todos), dos mais recentes para os mais antigos.
+func (s *InvitationService) List(status string) ([]models.UserInvitation, error) {
+	now := time.Now()
+	q := s.db.Model(&models.UserInvitation{})
+	switch status {
+	case "", InvitationPending:
+		q = q.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case InvitationAccepted:
+		q = q.Where("accepted_at IS NOT NULL")
+	case InvitationRevoked:
+		q = q.Where("revoked_at IS NOT NULL")
+	case InvitationExpired:
+		q = q.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	case "all":
+	default:
+		return nil, Invalid("status inválido: use pending, accepted, revoked, expired ou all")
+	}
+
+	var items []models.UserInvitation
+	if err := q.Order("id desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Resend gera um link novo (o anterior deixa de valer), renova a validade
+// e reenvia o e-mail. Vale também para convites expirados.
+func (s *InvitationService) Resend(id uint, actor Actor) (*InvitationLink, error) {
+	inv, err := s.findOpen(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var link *InvitationLink
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if link, err = s.send(tx, inv); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditInvitationResent, userTarget(inv.Email), "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// Revoke cancela o convite e remove o usuário pendente, liberando o
+// e-mail para um novo convite. O convite fica na listagem como revogado.
+func (s *InvitationService) Revoke(id uint, actor Actor) error {
+	inv, err := s.findOpen(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(inv).Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		user := models.User{}
+		user.ID = inv.UserID
+		if err := tx.Model(&user).Association("Courses").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("invitation_pending = ?", true).Delete(&user).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditInvitationRevoked, userTarget(inv.Email), "")
+	})
+}
+
+// Accept ativa a conta do convidado com a senha escolhida por ele. O link
+// é de uso único.
+func (s *InvitationService) Accept(token, password string, actor Actor) error {
+	if len(password) < 6 {
+		return Invalid("a senha deve ter pelo menos 6 caracteres")
+	}
+
+	var inv models.UserInvitation
+	if err := s.db.Where("token_hash = ?", hashToken(strings.TrimSpace(token))).First(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errInvalidInvitation
+		}
+		return err
+	}
+	now := time.Now()
+	if inv.AcceptedAt != nil || inv.RevokedAt != nil || !now.Before(inv.ExpiresAt) {
+		return errInvalidInvitation
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND invitation_pending = ?", inv.UserID, true).
+			Updates(map[string]any{"password": string(hash), "invitation_pending": false, "password_changed_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvalidInvitation
+		}
+		if err := tx.Model(&inv).Update("accepted_at", now).Error; err != nil {
+			return err
+		}
+		actor.UserID = inv.UserID
+		return audit(tx, actor, AuditInvitationAccepted, userTarget(inv.Email), "")
+	})
+}
+
+// send gera o token, grava o convite com a validade renovada e põe o
+// e-mail na outbox.
+func (s *InvitationService) send(tx *gorm.DB, inv *models.UserInvitation) (*InvitationLink, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	inv.TokenHash = hashToken(token)
+	inv.ExpiresAt = now.Add(InvitationTTL)
+	inv.SentCount++
+	inv.LastSentAt = now
+	if err := tx.Save(inv).Error; err != nil {
+		return nil, err
+	}
+
+	link := s.appURL + "/ativar?token=" + url.QueryEscape(token)
+	if err := enqueueEmail(tx, EmailUserInvitation, inv.Email, map[string]any{
+		"Name":      inv.Name,
+		"Link":      link,
+		"ExpiresAt": inv.ExpiresAt.Format(dateTimeLayout),
+	}); err != nil {
+		return nil, err
+	}
+	return &InvitationLink{Invitation: *inv, URL: link}, nil
+}
+
+// findOpen carrega um convite ainda não aceito nem revogado.
+func (s *InvitationService) findOpen(id uint) (*models.UserInvitation, error) {
+	var inv models.UserInvitation
+	if err := s.db.First(&inv, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Convite não encontrado")
+		}
+		return nil, err
+	}
+	if inv.AcceptedAt != nil {
+		return nil, Conflict("O convite já foi aceito")
+	}
+	if inv.RevokedAt != nil {
+		return nil, Conflict("O convite foi revogado")
+	}
+	return &inv, nil
+}
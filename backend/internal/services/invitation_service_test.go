@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+// activationToken extrai o token do link de ativação.
+func activationToken(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("link inválido %q: %v", link, err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestInvitationActivation(t *testing.T) {
+	db := newTestDB(t)
+	invitations := NewInvitationService(db, "https://ada.ufes.br/")
+	auth := NewAuthService(db, testSecret)
+	admin := Actor{UserID: 1, Role: models.RoleAdmin}
+
+	if _, err := invitations.Invite(InviteInput{Name: "Ana", Email: "ana@ufes.br", Role: "inexistente"}, admin); !errors.Is(err, ErrInvalid) {
+		t.Errorf("papel inexistente deveria ser recusado; obtive %v", err)
+	}
+	link, err := invitations.Invite(InviteInput{Name: "Ana", Email: "ana@ufes.br", Role: models.RoleViewer}, admin)
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	if _, err := invitations.Invite(InviteInput{Name: "Ana", Email: "ana@ufes.br"}, admin); err == nil {
+		t.Error("e-mail já cadastrado não pode ser convidado de novo")
+	}
+
+	var mails int64
+	db.Model(&models.OutboxEmail{}).Where("event = ? AND recipient = ?", EmailUserInvitation, "ana@ufes.br").Count(&mails)
+	if mails != 1 {
+		t.Errorf("e-mail do convite: %d na outbox; esperado 1", mails)
+	}
+
+	// Sem ativar, o convidado não entra.
+	if _, err := auth.Login("ana@ufes.br", "", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("conta pendente não deveria logar; obtive %v", err)
+	}
+
+	pending, _ := invitations.List("")
+	if len(pending) != 1 {
+		t.Fatalf("pendentes: %d; esperado 1", len(pending))
+	}
+
+	// Reenviar invalida o link anterior.
+	resent, err := invitations.Resend(link.Invitation.ID, admin)
+	if err != nil || resent.Invitation.SentCount != 2 {
+		t.Fatalf("Resend: %+v, %v", resent, err)
+	}
+	if err := invitations.Accept(activationToken(t, link.URL), "segredo", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("link substituído deveria ser recusado; obtive %v", err)
+	}
+	if err := invitations.Accept(activationToken(t, resent.URL), "123", Actor{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("senha curta deveria ser recusada; obtive %v", err)
+	}
+	if err := invitations.Accept(activationToken(t, resent.URL), "segredo", Actor{}); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if err := invitations.Accept(activationToken(t, resent.URL), "outra123", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("link é de uso único; obtive %v", err)
+	}
+	if res, err := auth.Login("ana@ufes.br", "segredo", Actor{}); err != nil || res.User.Role != models.RoleViewer {
+		t.Errorf("login após ativar: %v", err)
+	}
+	if err := invitations.Revoke(link.Invitation.ID, admin); !errors.Is(err, ErrConflict) {
+		t.Errorf("convite aceito não pode ser revogado; obtive %v", err)
+	}
+}
+
+func TestInvitationRevokeAndExpiry(t *testing.T) {
+	db := newTestDB(t)
+	invitations := NewInvitationService(db, "https://ada.ufes.br")
+	admin := Actor{UserID: 1, Role: models.RoleAdmin}
+
+	link, err := invitations.Invite(InviteInput{Name: "Bia", Email: "bia@ufes.br"}, admin)
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	if err := invitations.Revoke(link.Invitation.ID, admin); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := invitations.Accept(activationToken(t, link.URL), "segredo", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("convite revogado deveria ser recusado; obtive %v", err)
+	}
+	if _, err := invitations.Resend(link.Invitation.ID, admin); !errors.Is(err, ErrConflict) {
+		t.Errorf("convite revogado não pode ser reenviado; obtive %v", err)
+	}
+	var users int64
+	db.Model(&models.User{}).Where("email = ?", "bia@ufes.br").Count(&users)
+	if users != 0 {
+		t.Error("revogar deveria remover o usuário pendente")
+	}
+
+	// O e-mail fica livre para um novo convite, que expira sem uso.
+	again, err := invitations.Invite(InviteInput{Name: "Bia", Email: "bia@ufes.br"}, admin)
+	if err != nil {
+		t.Fatalf("novo convite após revogar: %v", err)
+	}
+	db.Model(&models.UserInvitation{}).Where("id = ?", again.Invitation.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	if err := invitations.Accept(activationToken(t, again.URL), "segredo", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("convite expirado deveria ser recusado; obtive %v", err)
+	}
+	if expired, _ := invitations.List(InvitationExpired); len(expired) != 1 {
+		t.Errorf("expirados: %d; esperado 1", len(expired))
+	}
+	if revoked, _ := invitations.List(InvitationRevoked); len(revoked) != 1 {
+		t.Errorf("revogados: %d; esperado 1", len(revoked))
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Ordenação e paginação das listagens. A ordenação vem em sort como chaves
+// separadas por vírgula, cada uma opcionalmente precedida de "-"
+// (decrescente): "course,-risk_score". Só as chaves da lista branca de
+// cada listagem são aceitas, e o ID entra sempre como desempate, de modo
+// que a ordem é total. Isso permite a paginação por cursor (keyset): o
+// cursor guarda os valores da última linha da página, e a seguinte
+// começa logo depois deles, sem OFFSET nem COUNT.
+
+// maxSortKeys limita as chaves de uma ordenação.
+const maxSortKeys = 4
+
+// Page é a paginação pedida: Limit > 0 pagina; a página seguinte é pedida
+// por Offset ou, de preferência, por Cursor (o NextCursor da anterior).
+// Count pede o total de linhas, que custa uma consulta a mais.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Count  bool
+}
+
+// PageInfo acompanha a página devolvida: Total é -1 sem Count e
+// NextCursor fica vazio na última página (ou sem Limit).
+type PageInfo struct {
+	Total      int64
+	NextCursor string
+}
+
+// sortKey é uma chave ordenável: o nome aceito em sort, a expressão SQL
+// (não nula) e o valor correspondente na linha carregada, usado no cursor.
+type sortKey[T any] struct {
+	name  string
+	expr  string
+	value func(T) any
+}
+
+type sortTerm[T any] struct {
+	sortKey[T]
+	desc bool
+}
+
+// parseSort interpreta sort (vazio: fallback) contra a lista branca keys,
+// que deve conter a chave "id", acrescentada ao final como desempate.
+func parseSort[T any](raw, fallback string, keys []sortKey[T]) ([]sortTerm[T], error) {
+	if strings.TrimSpace(raw) == "" {
+		raw = fallback
+	}
+	var terms []sortTerm[T]
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		name, desc := strings.CutPrefix(item, "-")
+		i := slices.IndexFunc(keys, func(k sortKey[T]) bool { return k.name == name })
+		if i < 0 {
+			return nil, Invalid("sort inválido: " + item + " (use " + sortKeyNames(keys) + ")")
+		}
+		if slices.ContainsFunc(terms, func(t sortTerm[T]) bool { return t.name == name }) {
+			return nil, Invalid("sort repetido: " + name)
+		}
+		terms = append(terms, sortTerm[T]{keys[i], desc})
+	}
+	if len(terms) > maxSortKeys {
+		return nil, Invalid("sort aceita no máximo 4 chaves")
+	}
+	if !slices.ContainsFunc(terms, func(t sortTerm[T]) bool { return t.name == "id" }) {
+		i := slices.IndexFunc(keys, func(k sortKey[T]) bool { return k.name == "id" })
+		terms = append(terms, sortTerm[T]{sortKey: keys[i]})
+	}
+	return terms, nil
+}
+
+func sortKeyNames[T any](keys []sortKey[T]) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// sortSignature identifica a ordenação no cursor: um cursor só vale para a
+// ordenação em que foi emitido.
+func sortSignature[T any](terms []sortTerm[T]) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t.name
+		if t.desc {
+			parts[i] = "-" + t.name
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+type cursorPayload struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func encodeCursor[T any](terms []sortTerm[T], last T) string {
+	p := cursorPayload{Sort: sortSignature(terms)}
+	for _, t := range terms {
+		p.Values = append(p.Values, t.value(last))
+	}
+	raw, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor devolve os valores do cursor, com números inteiros como
+// int64 (e não float64) para comparar com colunas inteiras.
+func decodeCursor[T any](cursor string, terms []sortTerm[T]) ([]any, error) {
+	invalid := Invalid("cursor inválido")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var p cursorPayload
+	if err := dec.Decode(&p); err != nil || len(p.Values) != len(terms) {
+		return nil, invalid
+	}
+	if p.Sort != sortSignature(terms) {
+		return nil, Invalid("cursor emitido para outra ordenação")
+	}
+	for i, v := range p.Values {
+		if n, ok := v.(json.Number); ok {
+			if p.Values[i], err = n.Int64(); err != nil {
+				if p.Values[i], err = n.Float64(); err != nil {
+					return nil, invalid
+				}
+			}
+		}
+	}
+	return p.Values, nil
+}
+
+// keysetWhere monta a condição "depois da linha com values" na ordenação:
+// (a > va) OR (a = va AND b > vb) OR …, com < nas chaves decrescentes.
+func keysetWhere[T any](terms []sortTerm[T], values []any) (string, []any) {
+	var ors []string
+	var args []any
+	for i, t := range terms {
+		var ands []string
+		for j := range i {
+			ands = append(ands, terms[j].expr+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if t.desc {
+			op = " < ?"
+		}
+		ands = append(ands, t.expr+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// paginate ordena q pelos termos e carrega a página pedida. O total, se
+// pedido, é contado antes do cursor; uma linha a mais é lida para saber
+// se há próxima página.
+func paginate[T any](q *gorm.DB, terms []sortTerm[T], page Page) ([]T, PageInfo, error) {
+	info := PageInfo{Total: -1}
+	if page.Count {
+		if err := q.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+			return nil, info, err
+		}
+	}
+	if page.Cursor != "" {
+		if page.Offset > 0 {
+			return nil, info, Invalid("use cursor ou offset, não os dois")
+		}
+		values, err := decodeCursor(page.Cursor, terms)
+		if err != nil {
+			return nil, info, err
+		}
+		where, args := keysetWhere(terms, values)
+		q = q.Where(where, args...)
+	}
+	for _, t := range terms {
+		dir := " ASC"
+		if t.desc {
+			dir = " DESC"
+		}
+		q = q.Order(t.expr + dir)
+	}
+	if page.Limit > 0 {
+		q = q.Limit(page.Limit + 1).Offset(page.Offset)
+	}
+
+	var rows []T
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, info, err
+	}
+	if page.Limit > 0 && len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		info.NextCursor = encodeCursor(terms, rows[len(rows)-1])
+	}
+	return rows, info, nil
+}
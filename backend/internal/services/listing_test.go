@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestStudentsSortAndCursorPagination(t *testing.T) {
+	db := newTestDB(t)
+	reports := NewReportService(db)
+	for i := 1; i <= 7; i++ {
+		s := seedStudentWithStatus(t, db, fmt.Sprintf("%03d", i), "2025/1", models.StatusRegular)
+		db.Model(s).Update("entry_year", 2020+i%3) // anos repetidos: o desempate pelo ID entra em jogo
+	}
+
+	all, info, err := reports.Students(StudentsFilter{Sort: "-entry_year,registration"})
+	if err != nil || info.Total != -1 || info.NextCursor != "" {
+		t.Fatalf("Students sem paginação: %v %+v", err, info)
+	}
+	var want []string
+	for _, s := range all {
+		want = append(want, s.Registration)
+	}
+	if !slices.Equal(want, []string{"002", "005", "001", "004", "007", "003", "006"}) {
+		t.Fatalf("ordenação multicoluna: %v", want)
+	}
+
+	// Percorrer por cursor devolve a mesma sequência, sem repetir linhas.
+	var got []string
+	page := Page{Limit: 3, Count: true}
+	for {
+		students, info, err := reports.Students(StudentsFilter{Sort: "-entry_year,registration", Page: page})
+		if err != nil {
+			t.Fatalf("página %v: %v", got, err)
+		}
+		if info.Total != 7 {
+			t.Errorf("total pedido com count: %d", info.Total)
+		}
+		for _, s := range students {
+			got = append(got, s.Registration)
+		}
+		if info.NextCursor == "" {
+			break
+		}
+		page.Cursor = info.NextCursor
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paginação por cursor %v, esperava %v", got, want)
+	}
+
+	// Sem count, nenhuma contagem; o cursor só vale para a sua ordenação.
+	_, info, _ = reports.Students(StudentsFilter{Sort: "name", Page: Page{Limit: 2}})
+	if info.Total != -1 || info.NextCursor == "" {
+		t.Fatalf("página sem count: %+v", info)
+	}
+	for _, f := range []StudentsFilter{
+		{Sort: "-name", Page: Page{Limit: 2, Cursor: info.NextCursor}},
+		{Sort: "name", Page: Page{Limit: 2, Cursor: info.NextCursor, Offset: 2}},
+		{Page: Page{Cursor: "não-é-cursor"}},
+		{Sort: "email"},
+		{Sort: "name,-name"},
+	} {
+		if _, _, err := reports.Students(f); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v deveria ser inválido; obtive %v", f, err)
+		}
+	}
+
+	// Chaves de tipos diferentes no cursor: risco (real) e curso (texto).
+	records, info, err := reports.Records(RecordsFilter{Sort: "course,-risk_score", Page: Page{Limit: 4}})
+	if err != nil || len(records) != 4 {
+		t.Fatalf("Records: %v %d", err, len(records))
+	}
+	rest, _, err := reports.Records(RecordsFilter{Sort: "course,-risk_score", Page: Page{Cursor: info.NextCursor}})
+	if err != nil || len(rest) != 3 {
+		t.Errorf("segunda página de registros: %v %d", err, len(rest))
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Política de tentativas de login. Falhas contam dentro de uma janela
+// deslizante: passada LoginFailureWindow sem erros, o contador recomeça.
+// Por conta, a partir de LoginDelayAfter falhas cada nova tentativa espera
+// um intervalo que dobra a cada erro (até LoginMaxDelay); em
+// AccountLockAfter falhas a conta fica bloqueada por LoginLockDuration.
+// Por IP não há atraso — um laboratório inteiro pode sair pelo mesmo
+// endereço —, só o bloqueio, com limite bem mais alto.
+const (
+	LoginFailureWindow = 15 * time.Minute
+	LoginDelayAfter    = 3
+	LoginMaxDelay      = 30 * time.Second
+	AccountLockAfter   = 10
+	IPLockAfter        = 50
+	LoginLockDuration  = 15 * time.Minute
+)
+
+const (
+	AuditLoginFailed   = "auth.login.failed"
+	AuditLoginLocked   = "auth.login.locked"
+	AuditLoginUnlocked = "auth.login.unlocked"
+)
+
+// Chaves dos contadores, também usadas como alvo na auditoria. E-mail é
+// normalizado para que variações de caixa não escapem do limite.
+func StaffAccountKey(email string) string {
+	return truncate("staff:"+strings.ToLower(strings.TrimSpace(email)), 120)
+}
+
+func StudentAccountKey(registration string) string {
+	return truncate(studentTarget(strings.TrimSpace(registration)), 120)
+}
+
+func IPKey(ip string) string { return truncate("ip:"+ip, 120) }
+
+func isIPKey(key string) bool { return strings.HasPrefix(key, "ip:") }
+
+// LoginGuard guarda os contadores de falhas de autenticação no banco e
+// decide quando uma chave deve esperar ou está bloqueada. É usado pelos
+// services de login (chave da conta) e pelo middleware de IP.
+type LoginGuard struct {
+	db *gorm.DB
+}
+
+func NewLoginGuard(db *gorm.DB) *LoginGuard { return &LoginGuard{db: db} }
+
+// Check recusa com ErrTooMany uma tentativa de uma chave bloqueada ou
+// ainda dentro do atraso progressivo. Não conta como falha.
+func (g *LoginGuard) Check(key string) error {
+	var t models.LoginThrottle
+	if err := g.db.Where("key = ?", key).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	if t.LockedUntil != nil && t.LockedUntil.After(now) {
+		wait := t.LockedUntil.Sub(now)
+		return TooMany(fmt.Sprintf("Muitas tentativas malsucedidas. Tente novamente em %d minuto(s).",
+			int(math.Ceil(wait.Minutes()))), wait)
+	}
+	if isIPKey(key) || now.Sub(t.LastFailureAt) >= LoginFailureWindow {
+		return nil
+	}
+	if next := t.LastFailureAt.Add(loginDelay(t.Failures)); next.After(now) {
+		wait := next.Sub(now)
+		return TooMany(fmt.Sprintf("Aguarde %d segundo(s) antes de tentar novamente.",
+			int(math.Ceil(wait.Seconds()))), wait)
+	}
+	return nil
+}
+
+// loginDelay é a espera imposta após failures falhas seguidas da conta.
+func loginDelay(failures int) time.Duration {
+	if failures < LoginDelayAfter {
+		return 0
+	}
+	delay := time.Second << min(failures-LoginDelayAfter, 10)
+	return min(delay, LoginMaxDelay)
+}
+
+// Fail registra uma falha da chave e, ao atingir o limite, bloqueia-a e
+// audita o bloqueio. A linha é travada durante a atualização para que
+// tentativas simultâneas não se percam.
+func (g *LoginGuard) Fail(key string, actor Actor) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		seed := models.LoginThrottle{Key: key, LastFailureAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+			return err
+		}
+
+		q := tx.Where("key = ?", key)
+		if tx.Dialector.Name() == "postgres" {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var t models.LoginThrottle
+		if err := q.First(&t).Error; err != nil {
+			return err
+		}
+
+		if t.Failures > 0 && now.Sub(t.LastFailureAt) >= LoginFailureWindow {
+			t.Failures = 0
+		}
+		t.Failures++
+		t.LastFailureAt = now
+
+		limit := AccountLockAfter
+		if isIPKey(key) {
+			limit = IPLockAfter
+		}
+		locked := t.Failures >= limit && (t.LockedUntil == nil || !t.LockedUntil.After(now))
+		if locked {
+			until := now.Add(LoginLockDuration)
+			t.LockedUntil = &until
+		}
+		if err := tx.Save(&t).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		slog.Warn("login bloqueado por excesso de falhas", "key", key, "failures", t.Failures, "ip", actor.IP)
+		return audit(tx, actor, AuditLoginLocked, key,
+			fmt.Sprintf("%d falhas; bloqueado até %s", t.Failures, t.LockedUntil.Format(time.RFC3339)))
+	})
+}
+
+// Reset zera o contador da chave (login bem-sucedido, senha redefinida).
+func (g *LoginGuard) Reset(key string) error {
+	return g.db.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+// As chaves são listadas e liberadas em dois grupos. As de alunos ficam
+// com a coordenação, restritas aos cursos do escopo; as da coordenação
+// (que expõem e-mails) e as de IP, só com a gestão de usuários — liberar
+// o bloqueio de um admin anularia a proteção contra força bruta.
+
+// studentKeys restringe q às chaves de alunos dos cursos do escopo.
+func (g *LoginGuard) studentKeys(scope CourseScope) *gorm.DB {
+	q := g.db.Where("key LIKE ?", studentTarget("")+"%")
+	if !scope.restricted {
+		return q
+	}
+	registrations := scope.apply(g.db.Model(&models.Student{}).
+		Select("'"+studentTarget("")+"' || students.registration"), "students.course_id")
+	return q.Where("key IN (?)", registrations)
+}
+
+// accountKeys restringe às chaves de contas da coordenação e de IPs.
+func (g *LoginGuard) accountKeys() *gorm.DB {
+	return g.db.Where("key LIKE ? OR key LIKE ?", "staff:%", "ip:%")
+}
+
+// ActiveStudents lista os alunos do escopo bloqueados ou com falhas
+// dentro da janela, os bloqueados primeiro.
+func (g *LoginGuard) ActiveStudents(scope CourseScope) ([]models.LoginThrottle, error) {
+	return active(g.studentKeys(scope))
+}
+
+// ActiveAccounts é ActiveStudents para as contas da coordenação e os IPs.
+func (g *LoginGuard) ActiveAccounts() ([]models.LoginThrottle, error) {
+	return active(g.accountKeys())
+}
+
+func active(q *gorm.DB) ([]models.LoginThrottle, error) {
+	now := time.Now()
+	var items []models.LoginThrottle
+	if err := q.
+		Where("locked_until > ? OR last_failure_at > ?", now, now.Add(-LoginFailureWindow)).
+		Order("locked_until IS NULL, locked_until DESC").
+		Order("last_failure_at DESC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UnlockStudent libera a chave de um aluno do escopo; chaves de outro
+// grupo ou de alunos fora do escopo respondem NotFound.
+func (g *LoginGuard) UnlockStudent(id uint, scope CourseScope, actor Actor) error {
+	return g.unlock(g.studentKeys(scope), id, actor)
+}
+
+// UnlockAccount libera a chave de uma conta da coordenação ou de um IP.
+func (g *LoginGuard) UnlockAccount(id uint, actor Actor) error {
+	return g.unlock(g.accountKeys(), id, actor)
+}
+
+// unlock libera uma chave de q e audita quem liberou.
+func (g *LoginGuard) unlock(q *gorm.DB, id uint, actor Actor) error {
+	var t models.LoginThrottle
+	if err := q.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NotFound("Bloqueio não encontrado")
+		}
+		return err
+	}
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&t).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditLoginUnlocked, t.Key, fmt.Sprintf("%d falhas", t.Failures))
+	})
+}
+
+// Purge remove contadores sem bloqueio vigente e sem falhas recentes, para
+// que a tabela não cresça com cada IP que já errou uma senha.
+func (g *LoginGuard) Purge(now time.Time) (int64, error) {
+	res := g.db.
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-LoginFailureWindow), now).
+		Delete(&models.LoginThrottle{})
+	return res.RowsAffected, res.Error
+}
+
+// loginFailed registra a falha de credenciais da conta: contador, log e
+// trilha de auditoria. err é o erro genérico a devolver ao cliente.
+func (g *LoginGuard) loginFailed(key string, actor Actor, reason string, err error) error {
+	slog.Warn("falha de login", "key", key, "ip", actor.IP, "reason", reason)
+	if e := audit(g.db, actor, AuditLoginFailed, key, reason); e != nil {
+		return e
+	}
+	if e := g.Fail(key, actor); e != nil {
+		return e
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+// ageFailures simula a passagem do tempo recuando a última falha da chave.
+func ageFailures(t *testing.T, guard *LoginGuard, key string, by time.Duration) {
+	t.Helper()
+	if err := guard.db.Model(&models.LoginThrottle{}).Where("key = ?", key).
+		Update("last_failure_at", time.Now().Add(-by)).Error; err != nil {
+		t.Fatalf("ageFailures: %v", err)
+	}
+}
+
+func TestStudentLoginProgressiveDelay(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentAuthService(db, testSecret)
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	if err := svc.Register("2022001", "senha123"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	attacker := Actor{IP: "10.0.0.9"}
+	for i := 0; i < LoginDelayAfter; i++ {
+		if _, _, err := svc.Login("2022001", "errada", attacker); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("tentativa %d: esperava ErrUnauthorized; obtive %v", i+1, err)
+		}
+	}
+
+	// Dentro do atraso, nem a senha correta é conferida.
+	_, _, err := svc.Login("2022001", "senha123", attacker)
+	if !errors.Is(err, ErrTooMany) {
+		t.Fatalf("esperava ErrTooMany durante o atraso; obtive %v", err)
+	}
+	if wait, ok := RetryAfter(err); !ok || wait <= 0 || wait > time.Second {
+		t.Errorf("espera após %d falhas deveria ser de até 1s; obtive %v", LoginDelayAfter, wait)
+	}
+
+	var failed int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND target = ?", AuditLoginFailed, "student:2022001").Count(&failed)
+	if failed != LoginDelayAfter {
+		t.Errorf("esperava %d falhas na auditoria; obtive %d", LoginDelayAfter, failed)
+	}
+
+	key := StudentAccountKey("2022001")
+	ageFailures(t, svc.guard, key, 2*time.Second)
+	if _, _, err := svc.Login("2022001", "senha123", attacker); err != nil {
+		t.Fatalf("passado o atraso, o login correto deveria funcionar: %v", err)
+	}
+	if err := svc.guard.Check(key); err != nil {
+		t.Errorf("login bem-sucedido deveria zerar o contador; obtive %v", err)
+	}
+}
+
+func TestAccountLockoutAndStaffUnlock(t *testing.T) {
+	db := newTestDB(t)
+	guard := NewLoginGuard(db)
+	key := StaffAccountKey(" Ana@UFES.br ")
+	if key != "staff:ana@ufes.br" {
+		t.Fatalf("chave deveria normalizar o e-mail; obtive %q", key)
+	}
+
+	for i := 0; i < AccountLockAfter; i++ {
+		if err := guard.Fail(key, Actor{IP: "10.0.0.9"}); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+	}
+	// Mesmo depois do maior atraso progressivo, a conta segue bloqueada.
+	ageFailures(t, guard, key, LoginMaxDelay)
+	err := guard.Check(key)
+	if wait, _ := RetryAfter(err); !errors.Is(err, ErrTooMany) || wait < LoginLockDuration-time.Minute {
+		t.Fatalf("esperava bloqueio de %v; obtive %v (espera %v)", LoginLockDuration, err, wait)
+	}
+
+	// A coordenação de curso não vê nem libera contas da coordenação ou
+	// IPs, só alunos do seu escopo.
+	guard.Fail(IPKey("10.0.0.9"), Actor{})
+	coordinator := RestrictTo(nil)
+	if mine, _ := guard.ActiveStudents(coordinator); len(mine) != 0 {
+		t.Errorf("coordenação não deveria ver chaves de conta ou IP: %+v", mine)
+	}
+	active, err := guard.ActiveAccounts()
+	if err != nil || len(active) != 2 || active[0].Key != key || active[0].LockedUntil == nil {
+		t.Fatalf("ActiveAccounts deveria listar o bloqueio e o IP: %+v, %v", active, err)
+	}
+	for _, a := range active {
+		if err := guard.UnlockStudent(a.ID, coordinator, Actor{UserID: 2}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("coordenação liberou %s: %v", a.Key, err)
+		}
+		if err := guard.UnlockStudent(a.ID, AllCourses(), Actor{UserID: 2}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("rota de alunos liberou %s: %v", a.Key, err)
+		}
+	}
+	if err := guard.UnlockAccount(active[0].ID, Actor{UserID: 1}); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := guard.Check(key); err != nil {
+		t.Errorf("conta desbloqueada deveria passar; obtive %v", err)
+	}
+
+	for _, action := range []string{AuditLoginLocked, AuditLoginUnlocked} {
+		var n int64
+		db.Model(&models.AuditLog{}).Where("action = ? AND target = ?", action, key).Count(&n)
+		if n != 1 {
+			t.Errorf("esperava 1 registro %s; obtive %d", action, n)
+		}
+	}
+	if err := guard.UnlockAccount(active[0].ID, Actor{UserID: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("desbloquear de novo deveria dar ErrNotFound; obtive %v", err)
+	}
+}
+
+func TestIPKeyHasNoDelayAndOldFailuresExpire(t *testing.T) {
+	db := newTestDB(t)
+	guard := NewLoginGuard(db)
+	ip := IPKey("10.0.0.9")
+
+	for i := 0; i < IPLockAfter-1; i++ {
+		if err := guard.Fail(ip, Actor{}); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+	}
+	if err := guard.Check(ip); err != nil {
+		t.Fatalf("IP abaixo do limite não deveria esperar; obtive %v", err)
+	}
+
+	// Fora da janela o contador recomeça: a próxima falha não bloqueia.
+	ageFailures(t, guard, ip, LoginFailureWindow)
+	if err := guard.Fail(ip, Actor{}); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if err := guard.Check(ip); err != nil {
+		t.Errorf("falhas antigas não deveriam contar; obtive %v", err)
+	}
+
+	ageFailures(t, guard, ip, 2*LoginFailureWindow)
+	if n, err := guard.Purge(time.Now()); err != nil || n != 1 {
+		t.Errorf("Purge deveria remover o contador ocioso: n=%d err=%v", n, err)
+	}
+}
+
+func TestStudentLocksFollowCourseScope(t *testing.T) {
+	db := newTestDB(t)
+	guard := NewLoginGuard(db)
+	student := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusRegular)
+	if err := guard.Fail(StudentAccountKey("2022001"), Actor{}); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+
+	other := RestrictTo([]uint{student.CourseID + 1})
+	if items, _ := guard.ActiveStudents(other); len(items) != 0 {
+		t.Errorf("aluno de outro curso listado: %+v", items)
+	}
+	mine := RestrictTo([]uint{student.CourseID})
+	items, err := guard.ActiveStudents(mine)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("ActiveStudents: %+v %v", items, err)
+	}
+	if err := guard.UnlockStudent(items[0].ID, other, Actor{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("desbloqueio fora do escopo: %v", err)
+	}
+	if err := guard.UnlockAccount(items[0].ID, Actor{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("chave de aluno pela rota de contas: %v", err)
+	}
+	if err := guard.UnlockStudent(items[0].ID, mine, Actor{}); err != nil {
+		t.Errorf("UnlockStudent: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Tipos de notificação, gravados em Notification.Kind. Os avisos ao aluno
+// usam os mesmos nomes dos e-mails correspondentes.
+const (
+	NotifyActionCreated   = EmailActionCreated
+	NotifyRoundOpened     = EmailRoundOpened
+	NotifyRoundClosing    = EmailRoundClosing
+	NotifyPlanReturned    = EmailPlanReturned
+	NotifyImportCompleted = "import_completed"
+)
+
+// Recipient identifica o dono de uma notificação: um usuário da
+// coordenação (UserID) ou um aluno (StudentID).
+type Recipient struct {
+	UserID    uint
+	StudentID uint
+}
+
+// UserRecipient e StudentRecipient montam o destinatário a partir da
+// identidade do token.
+func UserRecipient(id uint) Recipient    { return Recipient{UserID: id} }
+func StudentRecipient(id uint) Recipient { return Recipient{StudentID: id} }
+
+func (r Recipient) valid() bool { return (r.UserID == 0) != (r.StudentID == 0) }
+
+// scope restringe a consulta às notificações do destinatário.
+func (r Recipient) scope(db *gorm.DB) *gorm.DB {
+	if r.StudentID != 0 {
+		return db.Where("student_id = ?", r.StudentID)
+	}
+	return db.Where("user_id = ?", r.UserID)
+}
+
+// notify grava a notificação na transação tx do evento que a origina,
+// como a outbox de e-mails: sem o commit do evento, não há aviso.
+func notify(tx *gorm.DB, to Recipient, kind, title, body string) error {
+	n := models.Notification{Kind: kind, Title: title, Body: truncate(body, 500)}
+	if to.StudentID != 0 {
+		n.StudentID = &to.StudentID
+	} else {
+		n.UserID = &to.UserID
+	}
+	return tx.Create(&n).Error
+}
+
+// truncate corta s em até max bytes sem partir um caractere UTF-8.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && s[max]&0xC0 == 0x80 {
+		max--
+	}
+	return s[:max]
+}
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// List devolve as notificações do destinatário, mais recentes primeiro.
+// Com unreadOnly, apenas as não lidas. limit <= 0 devolve todas.
+func (s *NotificationService) List(to Recipient, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	if !to.valid() {
+		return nil, 0, Unauthorized("sessão inválida")
+	}
+
+	q := to.scope(s.db.Model(&models.Notification{}))
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	q = q.Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit).Offset(offset)
+	}
+	var items []models.Notification
+	if err := q.Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// UnreadCount conta as notificações ainda não lidas (contador do /me).
+func (s *NotificationService) UnreadCount(to Recipient) (int64, error) {
+	if !to.valid() {
+		return 0, Unauthorized("sessão inválida")
+	}
+	var count int64
+	err := to.scope(s.db.Model(&models.Notification{})).Where("read_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// MarkRead marca uma notificação como lida. Notificação de outro
+// destinatário é tratada como inexistente.
+func (s *NotificationService) MarkRead(to Recipient, id uint) (*models.Notification, error) {
+	if !to.valid() {
+		return nil, Unauthorized("sessão inválida")
+	}
+
+	var n models.Notification
+	if err := to.scope(s.db).First(&n, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Notificação não encontrada")
+		}
+		return nil, err
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&n).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		n.ReadAt = &now
+	}
+	return &n, nil
+}
+
+// MarkAllRead marca como lidas todas as notificações pendentes do
+// destinatário e devolve quantas foram alteradas.
+func (s *NotificationService) MarkAllRead(to Recipient) (int64, error) {
+	if !to.valid() {
+		return 0, Unauthorized("sessão inválida")
+	}
+	res := to.scope(s.db.Model(&models.Notification{})).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// LatestID devolve o maior ID de notificação do destinatário (0 se não
+// houver): é o ponto de partida do stream quando o cliente não informa
+// o último evento recebido.
+func (s *NotificationService) LatestID(to Recipient) (uint, error) {
+	if !to.valid() {
+		return 0, Unauthorized("sessão inválida")
+	}
+	var id uint
+	err := to.scope(s.db.Model(&models.Notification{})).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error
+	return id, err
+}
+
+// Since devolve, em ordem crescente, as notificações do destinatário
+// criadas depois de afterID.
+func (s *NotificationService) Since(to Recipient, afterID uint) ([]models.Notification, error) {
+	if !to.valid() {
+		return nil, Unauthorized("sessão inválida")
+	}
+	var items []models.Notification
+	err := to.scope(s.db).Where("id > ?", afterID).Order("id ASC").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestCohortNotifiedOnRoundOpenEvenWithoutEmail(t *testing.T) {
+	db := newTestDB(t)
+	pae := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	regular := seedStudentWithStatus(t, db, "2022002", "2025/2", models.StatusRegular)
+
+	if _, err := NewPlanRoundService(db).Open("2026/1", "2026/2", nil, 1); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	svc := NewNotificationService(db)
+	items, total, err := svc.List(StudentRecipient(pae.ID), false, 0, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 1 || items[0].Kind != NotifyRoundOpened || items[0].Title != "Rodada de planos 2026/1–2026/2 aberta" {
+		t.Fatalf("aluno PAE sem e-mail deveria receber o aviso no app: total=%d %+v", total, items)
+	}
+	if n, _ := svc.UnreadCount(StudentRecipient(regular.ID)); n != 0 {
+		t.Errorf("aluno regular não é do grupo da rodada; obtive %d avisos", n)
+	}
+}
+
+func TestNotificationsAreScopedAndMarkedRead(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewNotificationService(db)
+
+	staff, other, student := UserRecipient(1), UserRecipient(2), StudentRecipient(1)
+	for _, to := range []Recipient{staff, staff, other, student} {
+		if err := notify(db, to, NotifyImportCompleted, "Importação concluída", ""); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+
+	items, _, err := svc.List(staff, true, 0, 0)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("staff deveria ver só as próprias 2 notificações; obtive %d (err=%v)", len(items), err)
+	}
+
+	// Aluno com o mesmo ID numérico não enxerga a notificação do staff.
+	if _, err := svc.MarkRead(student, items[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("notificação alheia deveria ser 404; obtive %v", err)
+	}
+	if n, err := svc.MarkRead(staff, items[0].ID); err != nil || n.ReadAt == nil {
+		t.Fatalf("MarkRead: %+v, %v", n, err)
+	}
+	if count, _ := svc.UnreadCount(staff); count != 1 {
+		t.Errorf("esperava 1 não lida após marcar uma; obtive %d", count)
+	}
+
+	if updated, err := svc.MarkAllRead(staff); err != nil || updated != 1 {
+		t.Fatalf("MarkAllRead: updated=%d err=%v", updated, err)
+	}
+	if count, _ := svc.UnreadCount(other); count != 1 {
+		t.Errorf("marcar tudo não deveria afetar outro usuário; obtive %d", count)
+	}
+}
+
+func TestSinceReturnsOnlyNewerNotifications(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewNotificationService(db)
+	student := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPIC)
+	to := StudentRecipient(student.ID)
+
+	latest, err := svc.LatestID(to)
+	if err != nil || latest != 0 {
+		t.Fatalf("LatestID sem notificações: %d, %v", latest, err)
+	}
+
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+	if _, err := NewActionService(db).Create("2022001", ActionInput{
+		SemesterID: sem.ID, ActionDate: time.Now(), Description: "Reunião com a coordenação",
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	items, err := svc.Since(to, latest)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("esperava a notificação da ação; obtive %d (err=%v)", len(items), err)
+	}
+	if items[0].Kind != NotifyActionCreated || items[0].Body != "Reunião com a coordenação" {
+		t.Errorf("notificação incorreta: %+v", items[0])
+	}
+	if more, _ := svc.Since(to, items[0].ID); len(more) != 0 {
+		t.Errorf("nada deveria vir após o último ID; obtive %d", len(more))
+	}
+}
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// OIDCStateTTL é o prazo para o usuário voltar do provedor de identidade.
+const OIDCStateTTL = 10 * time.Minute
+
+const (
+	AuditSSOLogin  = "auth.sso.login"
+	AuditSSODenied = "auth.sso.denied"
+)
+
+var errSSOFailed = Unauthorized("falha no login institucional: tente novamente")
+
+// OIDCConfig descreve o cliente registrado no provedor OpenID Connect da
+// universidade. RegistrationClaim é a claim do ID token com a matrícula.
+type OIDCConfig struct {
+	Issuer            string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string
+	RegistrationClaim string
+	Scopes            []string
+}
+
+// OIDCLogin é o resultado do login único: a sessão e a identidade local
+// (User da coordenação ou Student) a que o provedor foi associado.
+type OIDCLogin struct {
+	Tokens  *TokenPair
+	User    *models.User
+	Student *models.Student
+}
+
+// OIDCService implementa o login único por OpenID Connect (authorization
+// code + PKCE). A identidade do provedor é associada a uma conta já
+// existente — aluno pela claim de matrícula, coordenação pelo e-mail — e a
+// sessão emitida é a mesma do login por senha. A verificação em duas
+// etapas local não se aplica: o segundo fator fica a cargo do provedor.
+type OIDCService struct {
+	db       *gorm.DB
+	sessions *SessionService
+	cfg      OIDCConfig
+	client   *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+func NewOIDCService(db *gorm.DB, jwtSecret string, cfg OIDCConfig) *OIDCService {
+	return &OIDCService{
+		db:       db,
+		sessions: NewSessionService(db, jwtSecret),
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled indica se o SSO foi configurado.
+func (s *OIDCService) Enabled() bool { return s.cfg.Issuer != "" }
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// AuthorizationURL inicia o login: grava state, nonce e code_verifier e
+// devolve o endereço do provedor para onde o navegador deve ir e o
+// vínculo (binding) que o chamador guarda em cookie no navegador. Sem o
+// vínculo, o state não é aceito no retorno: um link de callback obtido
+// por outra pessoa não abre a sessão dela no navegador da vítima.
+func (s *OIDCService) AuthorizationURL(ctx context.Context) (authURL, binding string, err error) {
+	if !s.Enabled() {
+		return "", "", NotFound("Login institucional não configurado")
+	}
+	disc, err := s.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomHex(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomHex(32)
+	if err != nil {
+		return "", "", err
+	}
+	if binding, err = randomHex(32); err != nil {
+		return "", "", err
+	}
+	if err := s.db.Create(&models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		BindingHash:  hashToken(binding),
+		ExpiresAt:    time.Now().Add(OIDCStateTTL),
+	}).Error; err != nil {
+		return "", "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", s.cfg.ClientID)
+	q.Set("redirect_uri", s.cfg.RedirectURL)
+	q.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", pkceChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(disc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return disc.AuthorizationEndpoint + sep + q.Encode(), binding, nil
+}
+
+// Callback conclui o login com o code e o state devolvidos pelo provedor e
+// o vínculo do cookie do navegador: troca o code (com o code_verifier),
+// valida o ID token e abre a sessão da conta associada.
+func (s *OIDCService) Callback(ctx context.Context, code, state, binding string, actor Actor) (*OIDCLogin, error) {
+	if !s.Enabled() {
+		return nil, NotFound("Login institucional não configurado")
+	}
+	pending, err := s.consumeState(state, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := s.exchange(ctx, code, pending.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.verifyIDToken(ctx, rawIDToken, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return s.login(claims, actor)
+}
+
+// consumeState apaga o state apresentado e devolve o que foi guardado com
+// ele. State desconhecido, expirado, já usado ou apresentado sem o vínculo
+// do navegador que o criou é recusado — e, apresentado, deixa de valer.
+func (s *OIDCService) consumeState(state, binding string) (*models.OIDCLoginState, error) {
+	var pending models.OIDCLoginState
+	if err := s.db.Where("state_hash = ?", hashToken(state)).First(&pending).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errSSOFailed
+		}
+		return nil, err
+	}
+	res := s.db.Delete(&pending)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 || !pending.ExpiresAt.After(time.Now()) {
+		return nil, errSSOFailed
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(hashToken(binding)), []byte(pending.BindingHash)) != 1 {
+		return nil, errSSOFailed
+	}
+	return &pending, nil
+}
+
+// login associa as claims do ID token a uma conta local. A claim de
+// matrícula tem precedência; sem aluno correspondente, vale o e-mail
+// (verificado) de um usuário da coordenação. Não há criação automática de
+// contas: quem não está na base é recusado.
+func (s *OIDCService) login(claims jwt.MapClaims, actor Actor) (*OIDCLogin, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	registration := claimString(claims[s.cfg.RegistrationClaim])
+
+	if registration != "" {
+		var student models.Student
+		err := s.db.Preload("Course").Where("registration = ?", registration).First(&student).Error
+		if err == nil {
+			pair, err := s.sessions.Start(Claims{
+				Role:         models.RoleStudent,
+				StudentID:    student.ID,
+				Registration: student.Registration,
+			})
+			if err != nil {
+				return nil, err
+			}
+			actor.StudentID = student.ID
+			if err := audit(s.db, actor, AuditSSOLogin, studentTarget(student.Registration), "sub: "+subject); err != nil {
+				return nil, err
+			}
+			return &OIDCLogin{Tokens: pair, Student: &student}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if email != "" {
+		// Sem email_verified = true o provedor não garante o e-mail, e a
+		// conta da coordenação não é associada.
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return nil, s.deny(actor, userTarget(email), "e-mail não verificado no provedor")
+		}
+		var user models.User
+		err := s.db.Where("LOWER(email) = ?", strings.ToLower(email)).First(&user).Error
+		if err == nil && user.InvitationPending {
+			return nil, s.deny(actor, userTarget(user.Email), "convite ainda não aceito")
+		}
+		if err == nil && user.DeactivatedAt != nil {
+			return nil, s.deny(actor, userTarget(user.Email), "conta desativada")
+		}
+		if err == nil {
+			pair, err := s.sessions.Start(Claims{UserID: user.ID, Role: user.Role})
+			if err != nil {
+				return nil, err
+			}
+			actor.UserID = user.ID
+			if err := audit(s.db, actor, AuditSSOLogin, userTarget(user.Email), "sub: "+subject); err != nil {
+				return nil, err
+			}
+			return &OIDCLogin{Tokens: pair, User: &user}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	target := "sso:" + subject
+	if registration != "" {
+		target = studentTarget(registration)
+	} else if email != "" {
+		target = userTarget(email)
+	}
+	return nil, s.deny(actor, target, "identidade sem conta no sistema")
+}
+
+// deny audita a recusa e devolve 403 com orientação ao usuário.
+func (s *OIDCService) deny(actor Actor, target, reason string) error {
+	slog.Warn("login institucional recusado", "target", target, "reason", reason, "ip", actor.IP)
+	if err := audit(s.db, actor, AuditSSODenied, truncate(target, 120), reason); err != nil {
+		return err
+	}
+	return Forbidden("Sua conta institucional não tem acesso a este sistema. Procure a coordenação.")
+}
+
+// claimString aceita a matrícula como texto ou número no ID token.
+func claimString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return fmt.Sprintf("%.0f", t)
+	case json.Number:
+		return t.String()
+	}
+	return ""
+}
+
+// exchange troca o authorization code pelo ID token no token endpoint.
+func (s *OIDCService) exchange(ctx context.Context, code, verifier string) (string, error) {
+	disc, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", s.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, disc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token endpoint do provedor: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// code inválido ou expirado é erro do usuário, não do servidor.
+		slog.Warn("troca de code recusada pelo provedor", "status", resp.StatusCode, "body", truncate(string(body), 300))
+		return "", errSSOFailed
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.IDToken == "" {
+		return "", errors.New("resposta do token endpoint sem id_token")
+	}
+	return token.IDToken, nil
+}
+
+// verifyIDToken valida assinatura (RS256, chaves do JWKS), emissor,
+// audiência, validade e nonce do ID token.
+func (s *OIDCService) verifyIDToken(ctx context.Context, raw, nonce string) (jwt.MapClaims, error) {
+	disc, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(disc.Issuer),
+		jwt.WithAudience(s.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		slog.Warn("ID token recusado", "error", err)
+		return nil, errSSOFailed
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		slog.Warn("ID token com nonce divergente")
+		return nil, errSSOFailed
+	}
+	return claims, nil
+}
+
+// discover lê (uma vez) o documento de descoberta do provedor.
+func (s *OIDCService) discover(ctx context.Context) (*oidcDiscovery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.discovery != nil {
+		return s.discovery, nil
+	}
+
+	var disc oidcDiscovery
+	if err := s.getJSON(ctx, s.cfg.Issuer+"/.well-known/openid-configuration", &disc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(disc.Issuer, "/") != s.cfg.Issuer {
+		return nil, fmt.Errorf("issuer da descoberta (%q) difere de OIDC_ISSUER", disc.Issuer)
+	}
+	if disc.AuthorizationEndpoint == "" || disc.TokenEndpoint == "" || disc.JWKSURI == "" {
+		return nil, errors.New("documento de descoberta OIDC incompleto")
+	}
+	s.discovery = &disc
+	return s.discovery, nil
+}
+
+// key devolve a chave pública do kid. Kid desconhecido força uma nova
+// leitura do JWKS, cobrindo a rotação de chaves do provedor.
+func (s *OIDCService) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := s.keys[kid]
+	jwksURI := ""
+	if s.discovery != nil {
+		jwksURI = s.discovery.JWKSURI
+	}
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := s.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave %q ausente no JWKS do provedor", kid)
+}
+
+func (s *OIDCService) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("provedor OIDC: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("provedor OIDC: %s respondeu %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// PurgeOIDCStates remove states de logins não concluídos. Não depende da
+// configuração do provedor: a limpeza roda mesmo com o SSO desligado.
+func PurgeOIDCStates(db *gorm.DB, now time.Time) (int64, error) {
+	res := db.Where("expires_at < ?", now).Delete(&models.OIDCLoginState{})
+	return res.RowsAffected, res.Error
+}
+
+// pkceChallenge é o code_challenge S256 do code_verifier (RFC 7636).
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
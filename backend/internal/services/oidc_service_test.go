@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"adamanagement/backend/internal/models"
+	"adamanagement/backend/internal/oidctest"
+)
+
+// newOIDCTest sobe o provedor de teste e um OIDCService apontado para ele.
+func newOIDCTest(t *testing.T) (*OIDCService, *oidctest.Provider) {
+	t.Helper()
+	provider, err := oidctest.New()
+	if err != nil {
+		t.Fatalf("oidctest.New: %v", err)
+	}
+	srv := httptest.NewServer(provider)
+	t.Cleanup(srv.Close)
+	provider.Issuer = srv.URL
+
+	svc := NewOIDCService(newTestDB(t), testSecret, OIDCConfig{
+		Issuer:            srv.URL,
+		ClientID:          "ada",
+		RedirectURL:       "http://localhost:5173/auth/callback",
+		RegistrationClaim: "registration",
+		Scopes:            []string{"openid", "email"},
+	})
+	return svc, provider
+}
+
+// ssoLogin percorre o fluxo completo como o navegador faria.
+func ssoLogin(t *testing.T, svc *OIDCService, provider *oidctest.Provider, claims map[string]any) (*OIDCLogin, error) {
+	t.Helper()
+	authURL, binding, err := svc.AuthorizationURL(context.Background())
+	if err != nil {
+		t.Fatalf("AuthorizationURL: %v", err)
+	}
+	back, err := provider.Authorize(authURL, claims)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	u, _ := url.Parse(back)
+	return svc.Callback(context.Background(), u.Query().Get("code"), u.Query().Get("state"), binding, Actor{IP: "10.0.0.1"})
+}
+
+func TestOIDCLoginMapsStaffAndStudent(t *testing.T) {
+	svc, provider := newOIDCTest(t)
+	ana, _ := NewAuthService(svc.db, testSecret).CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser)
+	seedStudentWithStatus(t, svc.db, "2022001", "2025/2", models.StatusPAE)
+
+	staff, err := ssoLogin(t, svc, provider, map[string]any{"email": "Ana@UFES.br", "email_verified": true})
+	if err != nil || staff.User == nil || staff.User.ID != ana.ID {
+		t.Fatalf("e-mail do provedor deveria associar a Ana: %+v, %v", staff, err)
+	}
+	claims := parseAccess(t, staff.Tokens.AccessToken)
+	if claims.UserID != ana.ID || claims.Role != models.RoleUser || claims.SessionID == "" {
+		t.Errorf("claims de staff incorretas: %+v", claims)
+	}
+
+	// A matrícula tem precedência sobre o e-mail.
+	student, err := ssoLogin(t, svc, provider, map[string]any{"email": "aluno@ufes.br", "registration": "2022001"})
+	if err != nil || student.Student == nil || student.Student.Registration != "2022001" {
+		t.Fatalf("claim de matrícula deveria associar o aluno: %+v, %v", student, err)
+	}
+	if claims := parseAccess(t, student.Tokens.AccessToken); claims.Role != models.RoleStudent || claims.Registration != "2022001" {
+		t.Errorf("claims de aluno incorretas: %+v", claims)
+	}
+
+	if _, err := ssoLogin(t, svc, provider, map[string]any{"email": "estranho@ufes.br", "email_verified": true}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("identidade sem conta deveria ser recusada; obtive %v", err)
+	}
+	// Só email_verified = true associa a conta: false, ausente ou em
+	// outro formato são recusados.
+	for _, verified := range []any{false, nil, "true"} {
+		if _, err := ssoLogin(t, svc, provider, map[string]any{"email": "ana@ufes.br", "email_verified": verified}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("email_verified = %v deveria ser recusado; obtive %v", verified, err)
+		}
+	}
+}
+
+func TestOIDCRejectsReplayedStateAndForgedTokens(t *testing.T) {
+	svc, provider := newOIDCTest(t)
+	if _, err := NewAuthService(svc.db, testSecret).CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	authURL, binding, _ := svc.AuthorizationURL(context.Background())
+	back, _ := provider.Authorize(authURL, map[string]any{"email": "ana@ufes.br", "email_verified": true})
+	u, _ := url.Parse(back)
+	code, state := u.Query().Get("code"), u.Query().Get("state")
+	if _, err := svc.Callback(context.Background(), code, state, binding, Actor{}); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if _, err := svc.Callback(context.Background(), code, state, binding, Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("state reapresentado deveria ser recusado; obtive %v", err)
+	}
+
+	// Callback levado a outro navegador (login CSRF): sem o cookie do
+	// vínculo, ou com o de outro login, o state é recusado.
+	_, victim, _ := svc.AuthorizationURL(context.Background())
+	for _, other := range []string{"", victim} {
+		authURL, _, _ = svc.AuthorizationURL(context.Background())
+		back, _ = provider.Authorize(authURL, map[string]any{"email": "ana@ufes.br", "email_verified": true})
+		u, _ = url.Parse(back)
+		if _, err := svc.Callback(context.Background(), u.Query().Get("code"), u.Query().Get("state"), other, Actor{}); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("vínculo %q deveria ser recusado; obtive %v", other, err)
+		}
+	}
+
+	// ID token de outro cliente (audiência errada) ou com nonce trocado.
+	authURL, _, _ = svc.AuthorizationURL(context.Background())
+	nonce, _ := url.Parse(authURL)
+	base := jwt.MapClaims{
+		"iss": provider.Issuer, "sub": "x", "email": "ana@ufes.br", "email_verified": true,
+		"exp": time.Now().Add(time.Minute).Unix(), "nonce": nonce.Query().Get("nonce"),
+	}
+	for name, patch := range map[string]jwt.MapClaims{
+		"audiência": {"aud": "outro-cliente"},
+		"nonce":     {"aud": "ada", "nonce": "forjado"},
+	} {
+		claims := jwt.MapClaims{}
+		for k, v := range base {
+			claims[k] = v
+		}
+		for k, v := range patch {
+			claims[k] = v
+		}
+		raw, _ := provider.Sign(claims)
+		if _, err := svc.verifyIDToken(context.Background(), raw, nonce.Query().Get("nonce")); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: ID token deveria ser recusado; obtive %v", name, err)
+		}
+	}
+}
+
+func TestPasswordLoginCanBeDisabled(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	if _, err := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	auth.SetPasswordLogin(false)
+	if _, err := auth.Login("ana@ufes.br", "segredo", Actor{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("login por senha desligado deveria ser recusado; obtive %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adamanagement/backend/internal/mail"
+	"adamanagement/backend/internal/models"
+)
+
+// Eventos de domínio que geram e-mail, gravados em OutboxEmail.Event.
+const (
+	EmailActionCreated  = "action_created"
+	EmailRoundOpened    = "round_opened"
+	EmailRoundClosing   = "round_closing"
+	EmailPlanReturned   = "plan_returned"
+	EmailPasswordReset  = "password_reset"
+	EmailUserInvitation = "user_invitation"
+	EmailReportDelivery = "report_delivery"
+)
+
+// Política de reenvio da outbox: até outboxMaxAttempts tentativas, com
+// espera exponencial entre elas.
+const (
+	outboxMaxAttempts = 8
+	outboxBatchSize   = 20
+
+	// outboxLease reserva a mensagem para quem a retirou da fila, evitando
+	// envio duplicado se outra instância consultar durante o envio.
+	outboxLease = 5 * time.Minute
+)
+
+// dateLayout formata datas nos textos enviados aos usuários.
+const (
+	dateLayout     = "02/01/2006"
+	dateTimeLayout = "02/01/2006 15:04"
+)
+
+// enqueueEmail renderiza o template e grava a mensagem na outbox dentro da
+// transação tx do evento. Destinatário vazio (aluno sem e-mail de contato)
+// não é erro: a mensagem simplesmente não é gerada.
+func enqueueEmail(tx *gorm.DB, event, to string, data any) error {
+	return enqueueEmailWithAttachment(tx, event, to, data, nil)
+}
+
+// storeAttachment grava o anexo uma vez na transação tx, para ser
+// referenciado por todas as mensagens de uma entrega.
+func storeAttachment(tx *gorm.DB, attachment mail.Attachment) (*models.OutboxAttachment, error) {
+	stored := models.OutboxAttachment{Name: attachment.Name, ContentType: attachment.ContentType, Data: attachment.Data}
+	if err := tx.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// enqueueEmailWithAttachment é enqueueEmail com um anexo já gravado por
+// storeAttachment.
+func enqueueEmailWithAttachment(tx *gorm.DB, event, to string, data any, attachment *models.OutboxAttachment) error {
+	if to == "" {
+		return nil
+	}
+	msg, err := mail.Render(event, data)
+	if err != nil {
+		return err
+	}
+	email := models.OutboxEmail{
+		Event:         event,
+		Recipient:     to,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+	if attachment != nil {
+		email.AttachmentID = &attachment.ID
+	}
+	return tx.Omit("Attachment").Create(&email).Error
+}
+
+// retryBackoff devolve a espera antes da próxima tentativa após attempts
+// falhas: 1min, 2min, 4min… limitada a 6h.
+func retryBackoff(attempts int) time.Duration {
+	const base, ceiling = time.Minute, 6 * time.Hour
+	if attempts < 1 {
+		return base
+	}
+	d := base << min(attempts-1, 16)
+	return min(d, ceiling)
+}
+
+// OutboxDispatcher entrega as mensagens pendentes da outbox pelo Sender
+// configurado. Não guarda estado além das dependências: a fila é a tabela.
+type OutboxDispatcher struct {
+	db     *gorm.DB
+	sender mail.Sender
+}
+
+func NewOutboxDispatcher(db *gorm.DB, sender mail.Sender) *OutboxDispatcher {
+	return &OutboxDispatcher{db: db, sender: sender}
+}
+
+// Dispatch envia um lote de mensagens vencidas e devolve quantas foram
+// entregas. Falhas de envio não interrompem o lote: cada mensagem tem a
+// tentativa registrada e é reagendada (ou marcada como falha definitiva).
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	batch, err := d.claim()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range batch {
+		if ctx.Err() != nil {
+			break
+		}
+		msg := &batch[i]
+		out := mail.Message{
+			To:      msg.Recipient,
+			Subject: msg.Subject,
+			Text:    msg.TextBody,
+			HTML:    msg.HTMLBody,
+		}
+		if a := msg.Attachment; a != nil {
+			out.Attachments = []mail.Attachment{{Name: a.Name, ContentType: a.ContentType, Data: a.Data}}
+		}
+		sendErr := d.sender.Send(ctx, out)
+		if err := d.record(msg, sendErr); err != nil {
+			return sent, err
+		}
+		if sendErr == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// claim retira da fila o próximo lote de e-mails e carrega, numa única
+// consulta, os anexos que ele referencia.
+func (d *OutboxDispatcher) claim() ([]models.OutboxEmail, error) {
+	var batch []models.OutboxEmail
+	if err := claimDue(d.db, &models.OutboxEmail{}, models.OutboxPending, &batch); err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, msg := range batch {
+		if msg.AttachmentID != nil && !slices.Contains(ids, *msg.AttachmentID) {
+			ids = append(ids, *msg.AttachmentID)
+		}
+	}
+	if len(ids) == 0 {
+		return batch, nil
+	}
+	var attachments []models.OutboxAttachment
+	if err := d.db.Where("id IN ?", ids).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.OutboxAttachment, len(attachments))
+	for i := range attachments {
+		byID[attachments[i].ID] = &attachments[i]
+	}
+	for i := range batch {
+		if id := batch[i].AttachmentID; id != nil {
+			batch[i].Attachment = byID[*id]
+		}
+	}
+	return batch, nil
+}
+
+// claimDue retira da fila de model (outbox ou entregas de webhook) até
+// outboxBatchSize linhas pendentes e vencidas, carregando-as em dest e
+// adiando o próximo horário delas pelo lease. No PostgreSQL, SKIP LOCKED
+// permite várias instâncias consumindo a mesma fila.
+func claimDue(db *gorm.DB, model any, pendingStatus string, dest any) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(model).
+			Where("status = ? AND next_attempt_at <= ?", pendingStatus, time.Now()).
+			Order("next_attempt_at asc").
+			Limit(outboxBatchSize)
+		if tx.Dialector.Name() == "postgres" {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		var ids []uint
+		if err := q.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(model).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(outboxLease)).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("id asc").Find(dest).Error
+	})
+}
+
+// record grava o resultado de uma tentativa de envio.
+func (d *OutboxDispatcher) record(msg *models.OutboxEmail, sendErr error) error {
+	now := time.Now()
+	attempts := msg.Attempts + 1
+	updates := map[string]any{"attempts": attempts}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.OutboxSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case attempts >= outboxMaxAttempts:
+		updates["status"] = models.OutboxFailed
+		updates["last_error"] = sendErr.Error()
+		slog.Error("e-mail descartado após tentativas", "id", msg.ID, "to", msg.Recipient, "error", sendErr)
+	default:
+		updates["next_attempt_at"] = now.Add(retryBackoff(attempts))
+		updates["last_error"] = sendErr.Error()
+		slog.Warn("falha no envio de e-mail; nova tentativa agendada", "id", msg.ID, "attempts", attempts, "error", sendErr)
+	}
+
+	return d.db.Model(&models.OutboxEmail{}).Where("id = ?", msg.ID).Updates(updates).Error
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/mail"
+	"adamanagement/backend/internal/models"
+)
+
+// stubSender registra as mensagens e falha enquanto failing for true.
+type stubSender struct {
+	failing bool
+	sent    []mail.Message
+}
+
+func (s *stubSender) Send(_ context.Context, msg mail.Message) error {
+	if s.failing {
+		return errors.New("smtp fora do ar")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestActionCreateEnqueuesEmailInSameTransaction(t *testing.T) {
+	db := newTestDB(t)
+	actions := NewActionService(db)
+
+	withEmail := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	db.Model(withEmail).Update("email", "aluno@ufes.br")
+	seedStudentWithStatus(t, db, "2022002", "2025/2", models.StatusPAE) // sem e-mail
+
+	var sem models.Semester
+	db.Where("code = ?", "2025/2").First(&sem)
+	for _, reg := range []string{"2022001", "2022002"} {
+		if _, err := actions.Create(reg, ActionInput{
+			SemesterID: sem.ID, ActionDate: time.Now(), Description: "Reunião",
+		}); err != nil {
+			t.Fatalf("Create(%s): %v", reg, err)
+		}
+	}
+
+	var outbox []models.OutboxEmail
+	db.Find(&outbox)
+	if len(outbox) != 1 {
+		t.Fatalf("esperava 1 e-mail (só o aluno com contato); obtive %d", len(outbox))
+	}
+	if outbox[0].Recipient != "aluno@ufes.br" || outbox[0].Event != EmailActionCreated ||
+		outbox[0].Status != models.OutboxPending {
+		t.Errorf("e-mail gravado incorretamente: %+v", outbox[0])
+	}
+}
+
+func TestDispatchRetriesWithBackoffThenSends(t *testing.T) {
+	db := newTestDB(t)
+	if err := enqueueEmail(db, EmailPlanReturned, "aluno@ufes.br", map[string]any{
+		"StudentName": "Aluno", "SemesterCode": "2026/1", "Note": "Ajustar",
+	}); err != nil {
+		t.Fatalf("enqueueEmail: %v", err)
+	}
+
+	sender := &stubSender{failing: true}
+	dispatcher := NewOutboxDispatcher(db, sender)
+
+	if n, err := dispatcher.Dispatch(context.Background()); err != nil || n != 0 {
+		t.Fatalf("envio com falha: n=%d err=%v", n, err)
+	}
+	var msg models.OutboxEmail
+	db.First(&msg)
+	if msg.Attempts != 1 || msg.Status != models.OutboxPending || msg.LastError == "" {
+		t.Fatalf("falha deveria contar tentativa e manter pendente: %+v", msg)
+	}
+	if !msg.NextAttemptAt.After(time.Now()) {
+		t.Errorf("próxima tentativa deveria ser futura; obtive %v", msg.NextAttemptAt)
+	}
+
+	// Ainda dentro do backoff: nada é retirado da fila.
+	sender.failing = false
+	if n, _ := dispatcher.Dispatch(context.Background()); n != 0 {
+		t.Fatalf("mensagem em backoff não deveria ser enviada; enviadas %d", n)
+	}
+
+	db.Model(&msg).Update("next_attempt_at", time.Now().Add(-time.Second))
+	if n, err := dispatcher.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("reenvio: n=%d err=%v", n, err)
+	}
+	db.First(&msg, msg.ID)
+	if msg.Status != models.OutboxSent || msg.SentAt == nil || msg.Attempts != 2 {
+		t.Errorf("mensagem deveria estar enviada após a 2ª tentativa: %+v", msg)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].To != "aluno@ufes.br" {
+		t.Errorf("sender recebeu %+v", sender.sent)
+	}
+}
+
+func TestRetryBackoffGrowsAndCaps(t *testing.T) {
+	if retryBackoff(1) != time.Minute || retryBackoff(2) != 2*time.Minute || retryBackoff(4) != 8*time.Minute {
+		t.Errorf("backoff não é exponencial: %v %v %v", retryBackoff(1), retryBackoff(2), retryBackoff(4))
+	}
+	if retryBackoff(30) != 6*time.Hour {
+		t.Errorf("backoff deveria limitar em 6h; obtive %v", retryBackoff(30))
+	}
+}
+
+func TestClosingRemindersAreSentOnce(t *testing.T) {
+	db := newTestDB(t)
+	rounds := NewPlanRoundService(db)
+	student := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	db.Model(student).Update("email", "aluno@ufes.br")
+
+	closesAt := time.Now().Add(72 * time.Hour)
+	if _, err := rounds.Open("2026/1", "2026/2", &closesAt, 1); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// Fora da janela de 48h: nenhum lembrete.
+	if n, err := rounds.EnqueueClosingReminders(time.Now()); err != nil || n != 0 {
+		t.Fatalf("fora da janela: n=%d err=%v", n, err)
+	}
+
+	inWindow := time.Now().Add(30 * time.Hour)
+	if n, err := rounds.EnqueueClosingReminders(inWindow); err != nil || n != 1 {
+		t.Fatalf("dentro da janela: n=%d err=%v", n, err)
+	}
+	if n, _ := rounds.EnqueueClosingReminders(inWindow); n != 0 {
+		t.Errorf("lembrete não deveria se repetir; obtive %d", n)
+	}
+
+	byEvent := map[string]int64{}
+	for _, ev := range []string{EmailRoundOpened, EmailRoundClosing} {
+		var n int64
+		db.Model(&models.OutboxEmail{}).Where("event = ?", ev).Count(&n)
+		byEvent[ev] = n
+	}
+	if byEvent[EmailRoundOpened] != 1 || byEvent[EmailRoundClosing] != 1 {
+		t.Errorf("esperava 1 aviso de abertura e 1 lembrete; obtive %v", byEvent)
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Política do código de redefinição de senha do aluno.
+const (
+	ResetCodeTTL         = 24 * time.Hour
+	ResetCodeMaxAttempts = 5
+
+	// resetCodeAlphabet exclui caracteres confundíveis (0/O, 1/I/L) — o
+	// código pode ser impresso e digitado à mão.
+	resetCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	resetCodeLength   = 8
+)
+
+// errInvalidResetCode é a resposta única a qualquer falha de resgate, para
+// não revelar se a matrícula existe ou se há código ativo.
+var errInvalidResetCode = Unauthorized("código inválido ou expirado")
+
+// ResetCode é o resultado da emissão. Code só vem preenchido na entrega
+// impressa; por e-mail, o código segue apenas na mensagem.
+type ResetCode struct {
+	Code      string
+	Delivery  string
+	ExpiresAt time.Time
+}
+
+// IssueResetCode gera um código de redefinição para o aluno, invalidando
+// qualquer código anterior ainda ativo. Com delivery "email", a mensagem
+// entra na outbox na mesma transação; com "print", o código é devolvido
+// para ser entregue em mãos.
+func (s *StudentAuthService) IssueResetCode(registration, delivery string, actor Actor) (*ResetCode, error) {
+	if delivery == "" {
+		delivery = models.ResetDeliveryPrint
+	}
+	if delivery != models.ResetDeliveryPrint && delivery != models.ResetDeliveryEmail {
+		return nil, Invalid("entrega inválida: use 'print' ou 'email'")
+	}
+
+	var student models.Student
+	if err := s.db.Where("registration = ?", strings.TrimSpace(registration)).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Aluno não encontrado")
+		}
+		return nil, err
+	}
+	if delivery == models.ResetDeliveryEmail && student.Email == "" {
+		return nil, Invalid("o aluno não tem e-mail de contato cadastrado")
+	}
+
+	code, err := newResetCode()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := &ResetCode{Code: code, Delivery: delivery, ExpiresAt: now.Add(ResetCodeTTL)}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetCode{}).
+			Where("student_id = ? AND used_at IS NULL AND invalidated_at IS NULL", student.ID).
+			Update("invalidated_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PasswordResetCode{
+			StudentID:       student.ID,
+			CodeHash:        hashToken(normalizeResetCode(code)),
+			Delivery:        delivery,
+			CreatedByUserID: actor.UserID,
+			ExpiresAt:       result.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		if delivery == models.ResetDeliveryEmail {
+			if err := enqueueEmail(tx, EmailPasswordReset, student.Email, map[string]any{
+				"StudentName": student.Name,
+				"Code":        code,
+				"ExpiresAt":   result.ExpiresAt.Format(dateTimeLayout),
+			}); err != nil {
+				return err
+			}
+		}
+		return audit(tx, actor, AuditResetIssued, studentTarget(student.Registration), "entrega: "+delivery)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == models.ResetDeliveryEmail {
+		result.Code = ""
+	}
+	return result, nil
+}
+
+// RedeemResetCode define a nova senha do aluno a partir de um código
+// válido. Cada código admite ResetCodeMaxAttempts tentativas erradas antes
+// de ser invalidado; falhas e sucesso ficam na trilha de auditoria. A
+// troca encerra as sessões abertas do aluno.
+func (s *StudentAuthService) RedeemResetCode(registration, code, password string, actor Actor) error {
+	if len(password) < 6 {
+		return Invalid("a senha deve ter pelo menos 6 caracteres")
+	}
+	registration = strings.TrimSpace(registration)
+	target := studentTarget(registration)
+
+	var student models.Student
+	if err := s.db.Where("registration = ?", registration).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.resetFailure(actor, target, "matrícula inexistente")
+		}
+		return err
+	}
+
+	now := time.Now()
+	var active models.PasswordResetCode
+	if err := s.db.
+		Where("student_id = ? AND used_at IS NULL AND invalidated_at IS NULL AND expires_at > ?", student.ID, now).
+		Order("id DESC").
+		First(&active).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.resetFailure(actor, target, "nenhum código ativo")
+		}
+		return err
+	}
+
+	given := hashToken(normalizeResetCode(code))
+	if subtle.ConstantTimeCompare([]byte(given), []byte(active.CodeHash)) != 1 {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			// Contagem no próprio UPDATE: tentativas simultâneas não leem
+			// o mesmo valor antigo, e a que atinge o limite invalida o
+			// código no mesmo comando.
+			res := tx.Model(&models.PasswordResetCode{}).
+				Where("id = ? AND used_at IS NULL AND invalidated_at IS NULL AND attempts < ?", active.ID, ResetCodeMaxAttempts).
+				Updates(map[string]any{
+					"attempts":       gorm.Expr("attempts + 1"),
+					"invalidated_at": gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE NULL END", ResetCodeMaxAttempts, now),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return audit(tx, actor, AuditResetFailed, target, "código já invalidado")
+			}
+			var attempts int
+			if err := tx.Model(&models.PasswordResetCode{}).Where("id = ?", active.ID).
+				Select("attempts").Scan(&attempts).Error; err != nil {
+				return err
+			}
+			return audit(tx, actor, AuditResetFailed, target,
+				fmt.Sprintf("código incorreto (tentativa %d de %d)", attempts, ResetCodeMaxAttempts))
+		}); err != nil {
+			return err
+		}
+		return errInvalidResetCode
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// used_at IS NULL garante uso único mesmo com resgates simultâneos;
+		// invalidated_at IS NULL, que um palpite errado concorrente que
+		// esgotou as tentativas não seja ignorado.
+		res := tx.Model(&active).Where("used_at IS NULL AND invalidated_at IS NULL").Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvalidResetCode
+		}
+		if err := tx.Model(&student).Updates(map[string]any{
+			"password":            string(hash),
+			"password_changed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, 0, student.ID, now); err != nil {
+			return err
+		}
+		// Nova senha definida: a matrícula sai de um eventual bloqueio.
+		if err := NewLoginGuard(tx).Reset(StudentAccountKey(registration)); err != nil {
+			return err
+		}
+		actor.StudentID = student.ID
+		return audit(tx, actor, AuditResetRedeemed, target, "")
+	})
+}
+
+// resetFailure audita uma tentativa de resgate sem código a consumir e
+// devolve o erro genérico.
+func (s *StudentAuthService) resetFailure(actor Actor, target, detail string) error {
+	if err := audit(s.db, actor, AuditResetFailed, target, detail); err != nil {
+		return err
+	}
+	return errInvalidResetCode
+}
+
+// newResetCode sorteia um código no formato XXXX-XXXX.
+func newResetCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(resetCodeAlphabet)))
+	for i := 0; i < resetCodeLength; i++ {
+		if i == resetCodeLength/2 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(resetCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeResetCode aceita o código digitado com minúsculas, espaços ou
+// sem o hífen.
+func normalizeResetCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestResetCodeRedeemsOnceAndEndsSessions(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentAuthService(db, testSecret)
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	if err := svc.Register("2022001", "esquecida"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	session, _, _ := svc.Login("2022001", "esquecida", Actor{})
+
+	staff := Actor{UserID: 1, IP: "10.0.0.1"}
+	reset, err := svc.IssueResetCode("2022001", models.ResetDeliveryPrint, staff)
+	if err != nil {
+		t.Fatalf("IssueResetCode: %v", err)
+	}
+	if len(reset.Code) != 9 || reset.Code[4] != '-' {
+		t.Fatalf("código deveria ter o formato XXXX-XXXX; obtive %q", reset.Code)
+	}
+
+	// Código digitado em minúsculas e sem hífen também vale.
+	typed := strings.ToLower(strings.ReplaceAll(reset.Code, "-", ""))
+	if err := svc.RedeemResetCode("2022001", typed, "nova-senha", Actor{IP: "10.0.0.2"}); err != nil {
+		t.Fatalf("RedeemResetCode: %v", err)
+	}
+	if _, _, err := svc.Login("2022001", "nova-senha", Actor{}); err != nil {
+		t.Errorf("login com a nova senha: %v", err)
+	}
+	if _, err := NewSessionService(db, testSecret).Refresh(session.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("sessões anteriores deveriam ser encerradas; obtive %v", err)
+	}
+	if err := svc.RedeemResetCode("2022001", reset.Code, "outra-senha", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("código já usado deveria ser recusado; obtive %v", err)
+	}
+
+	entries, _, _ := NewAuditService(db).List(AuditFilter{Target: "student:2022001"})
+	actions := map[string]int{}
+	for _, e := range entries {
+		actions[e.Action]++
+	}
+	if actions[AuditResetIssued] != 1 || actions[AuditResetRedeemed] != 1 || actions[AuditResetFailed] != 1 {
+		t.Errorf("trilha de auditoria incompleta: %v", actions)
+	}
+}
+
+func TestResetCodeInvalidatedAfterMaxAttempts(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentAuthService(db, testSecret)
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+
+	reset, err := svc.IssueResetCode("2022001", "", Actor{UserID: 1})
+	if err != nil {
+		t.Fatalf("IssueResetCode: %v", err)
+	}
+	for i := 0; i < ResetCodeMaxAttempts; i++ {
+		if err := svc.RedeemResetCode("2022001", "ZZZZ-ZZZZ", "nova-senha", Actor{}); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("tentativa %d com código errado: %v", i+1, err)
+		}
+	}
+	if err := svc.RedeemResetCode("2022001", reset.Code, "nova-senha", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("código deveria estar invalidado após %d erros; obtive %v", ResetCodeMaxAttempts, err)
+	}
+	var stored models.PasswordResetCode
+	db.First(&stored)
+	if stored.Attempts != ResetCodeMaxAttempts || stored.InvalidatedAt == nil {
+		t.Errorf("contagem gravada incorreta: %d tentativas, invalidado em %v", stored.Attempts, stored.InvalidatedAt)
+	}
+}
+
+func TestResetCodeByEmailIsNotReturned(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentAuthService(db, testSecret)
+	student := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+
+	if _, err := svc.IssueResetCode("2022001", models.ResetDeliveryEmail, Actor{UserID: 1}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("aluno sem e-mail deveria ser recusado; obtive %v", err)
+	}
+
+	db.Model(student).Update("email", "aluno@ufes.br")
+	first, _ := svc.IssueResetCode("2022001", models.ResetDeliveryPrint, Actor{UserID: 1})
+	reset, err := svc.IssueResetCode("2022001", models.ResetDeliveryEmail, Actor{UserID: 1})
+	if err != nil {
+		t.Fatalf("IssueResetCode: %v", err)
+	}
+	if reset.Code != "" {
+		t.Error("na entrega por e-mail o código não deve voltar na resposta")
+	}
+
+	var msg models.OutboxEmail
+	if err := db.Where("event = ?", EmailPasswordReset).First(&msg).Error; err != nil {
+		t.Fatalf("e-mail de redefinição não entrou na outbox: %v", err)
+	}
+	if err := svc.RedeemResetCode("2022001", first.Code, "nova-senha", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("novo código deveria invalidar o anterior; obtive %v", err)
+	}
+}
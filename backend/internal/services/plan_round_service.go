@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -35,8 +36,10 @@ type StudentRoundEntry struct {
 // Open abre uma rodada para os dois períodos informados (RN17). O
 // semestre-base é o último com dados no momento da abertura (RN21) e fica
 // gravado como snapshot. Fecha qualquer rodada aberta e cria a nova aberta
-// na mesma transação — invariante de no máximo uma aberta (RN19).
-func (s *PlanRoundService) Open(period1Code, period2Code string, userID uint) (*models.PlanRound, error) {
+// na mesma transação — invariante de no máximo uma aberta (RN19). O prazo
+// closesAt é opcional; os avisos de abertura aos alunos do grupo (app e
+// e-mail) são gravados na mesma transação.
+func (s *PlanRoundService) Open(period1Code, period2Code string, closesAt *time.Time, userID uint) (*models.PlanRound, error) {
 	period1Code = strings.TrimSpace(period1Code)
 	period2Code = strings.TrimSpace(period2Code)
 
@@ -46,6 +49,9 @@ func (s *PlanRoundService) Open(period1Code, period2Code string, userID uint) (*
 	if period1Code == period2Code {
 		return nil, Invalid("os dois períodos devem ser diferentes")
 	}
+	if closesAt != nil && !closesAt.After(time.Now()) {
+		return nil, Invalid("o prazo da rodada deve ser uma data futura")
+	}
 
 	var round models.PlanRound
 	err := s.db.Transaction(func(tx *gorm.DB) error {
@@ -79,8 +85,7 @@ func (s *PlanRoundService) Open(period1Code, period2Code string, userID uint) (*
 			return Invalid("um dos períodos informados já pertence a outra rodada")
 		}
 
-		if err := tx.Model(&models.PlanRound{}).Where("open = ?", true).
-			Update("open", false).Error; err != nil {
+		if err := closeOpenRounds(tx); err != nil {
 			return err
 		}
 
@@ -90,8 +95,17 @@ func (s *PlanRoundService) Open(period1Code, period2Code string, userID uint) (*
 			Period2SemesterID: sem2.ID,
 			Open:              true,
 			OpenedByUserID:    userID,
+			ClosesAt:          closesAt,
+		}
+		if err := tx.Create(&round).Error; err != nil {
+			return err
 		}
-		return tx.Create(&round).Error
+
+		round.BaseSemester, round.Period1, round.Period2 = *base, *sem1, *sem2
+		if err := publishEvent(tx, EventRoundOpened, roundEventData(&round)); err != nil {
+			return err
+		}
+		return notifyCohort(tx, &round, EmailRoundOpened)
 	})
 	if err != nil {
 		return nil, err
@@ -103,14 +117,19 @@ func (s *PlanRoundService) Open(period1Code, period2Code string, userID uint) (*
 // Close encerra a rodada, impedindo novos registros/edições de plano
 // (RN22: rodada fechada é somente leitura).
 func (s *PlanRoundService) Close(id uint) error {
-	var round models.PlanRound
-	if err := s.db.First(&round, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return NotFound("Rodada não encontrada")
-		}
+	round, err := s.Get(id)
+	if err != nil {
 		return err
 	}
-	return s.db.Model(&round).Update("open", false).Error
+	if !round.Open {
+		return nil
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(round).Update("open", false).Error; err != nil {
+			return err
+		}
+		return publishEvent(tx, EventRoundClosed, roundEventData(round))
+	})
 }
 
 // Reopen reabre uma rodada encerrada para permitir edições novamente,
@@ -118,17 +137,23 @@ func (s *PlanRoundService) Close(id uint) error {
 func (s *PlanRoundService) Reopen(id uint) (*models.PlanRound, error) {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var round models.PlanRound
-		if err := tx.First(&round, id).Error; err != nil {
+		if err := tx.Preload("BaseSemester").Preload("Period1").Preload("Period2").
+			First(&round, id).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return NotFound("Rodada não encontrada")
 			}
 			return err
 		}
-		if err := tx.Model(&models.PlanRound{}).Where("open = ?", true).
-			Update("open", false).Error; err != nil {
+		if round.Open {
+			return nil
+		}
+		if err := closeOpenRounds(tx); err != nil {
+			return err
+		}
+		if err := tx.Model(&round).Update("open", true).Error; err != nil {
 			return err
 		}
-		return tx.Model(&round).Update("open", true).Error
+		return publishEvent(tx, EventRoundOpened, roundEventData(&round))
 	})
 	if err != nil {
 		return nil, err
@@ -203,30 +228,44 @@ func (s *PlanRoundService) Get(id uint) (*models.PlanRound, error) {
 	return &round, nil
 }
 
-func (s *PlanRoundService) List() ([]models.PlanRound, error) {
-	var rounds []models.PlanRound
-	if err := s.preloaded().Order("id desc").Find(&rounds).Error; err != nil {
-		return nil, err
+var roundSortKeys = []sortKey[models.PlanRound]{
+	{"open", "open", func(r models.PlanRound) any { return r.Open }},
+	{"id", "id", func(r models.PlanRound) any { return r.ID }},
+}
+
+// List lista as rodadas ordenadas por sort (chaves de roundSortKeys;
+// padrão: mais recentes primeiro).
+func (s *PlanRoundService) List(sort string, page Page) ([]models.PlanRound, PageInfo, error) {
+	terms, err := parseSort(sort, "-id", roundSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
-	return rounds, nil
+	return paginate(s.preloaded().Model(&models.PlanRound{}), terms, page)
 }
 
 // Cohort devolve a rodada e os alunos em PAE/PIC no semestre-base dela.
-// A lista independe do seletor global — usa o snapshot da rodada.
-func (s *PlanRoundService) Cohort(roundID uint) (*models.PlanRound, []CohortStudent, error) {
+// A lista independe do seletor global — usa o snapshot da rodada. Com
+// advisorID > 0, apenas os alunos atribuídos a esse orientador no
+// semestre-base; sempre restrita aos cursos do escopo.
+func (s *PlanRoundService) Cohort(roundID, advisorID uint, scope CourseScope) (*models.PlanRound, []CohortStudent, error) {
 	round, err := s.Get(roundID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var students []CohortStudent
-	if err := s.db.Table("academic_records").
+	q := s.db.Table("academic_records").
 		Select("students.registration, students.name, academic_records.status").
 		Joins("JOIN students ON students.id = academic_records.student_id").
 		Where("academic_records.semester_id = ?", round.BaseSemesterID).
 		Where("academic_records.status IN ?", []string{models.StatusPAE, models.StatusPIC}).
-		Order("students.name asc").
-		Scan(&students).Error; err != nil {
+		Where("academic_records.deleted_at IS NULL")
+	if advisorID != 0 {
+		q = advisedBy(q, advisorID)
+	}
+	q = scope.apply(q, "students.course_id")
+
+	var students []CohortStudent
+	if err := q.Order("students.name asc").Scan(&students).Error; err != nil {
 		return nil, nil, err
 	}
 	return round, students, nil
@@ -244,7 +283,7 @@ func (s *PlanRoundService) StudentRounds(registration string) ([]StudentRoundEnt
 		return nil, err
 	}
 
-	rounds, err := s.List()
+	rounds, _, err := s.List("", Page{})
 	if err != nil {
 		return nil, err
 	}
@@ -279,8 +318,87 @@ func (s *PlanRoundService) StudentRounds(registration string) ([]StudentRoundEnt
 	return entries, nil
 }
 
+// ClosingReminderWindow é a antecedência do lembrete de prazo da rodada.
+const ClosingReminderWindow = 48 * time.Hour
+
+// EnqueueClosingReminders avisa os alunos das rodadas abertas cujo prazo
+// vence nas próximas 48h. Cada rodada é lembrada uma única vez: a marca
+// ClosingReminderSentAt é gravada na mesma transação dos avisos. Devolve
+// quantas rodadas foram lembradas.
+func (s *PlanRoundService) EnqueueClosingReminders(now time.Time) (int, error) {
+	var rounds []models.PlanRound
+	if err := s.preloaded().
+		Where("open = ? AND closing_reminder_sent_at IS NULL", true).
+		Where("closes_at > ? AND closes_at <= ?", now, now.Add(ClosingReminderWindow)).
+		Find(&rounds).Error; err != nil {
+		return 0, err
+	}
+
+	for i := range rounds {
+		round := &rounds[i]
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := notifyCohort(tx, round, EmailRoundClosing); err != nil {
+				return err
+			}
+			return tx.Model(round).Update("closing_reminder_sent_at", now).Error
+		}); err != nil {
+			return i, err
+		}
+	}
+	return len(rounds), nil
+}
+
+// notifyCohort avisa, na transação tx, cada aluno do grupo da rodada
+// (PAE/PIC no semestre-base): notificação no app para todos e e-mail para
+// quem tem contato cadastrado. A rodada precisa vir com os dois períodos
+// carregados.
+func notifyCohort(tx *gorm.DB, round *models.PlanRound, event string) error {
+	var students []models.Student
+	if err := tx.Model(&models.Student{}).
+		Joins("JOIN academic_records ON academic_records.student_id = students.id AND academic_records.deleted_at IS NULL").
+		Where("academic_records.semester_id = ?", round.BaseSemesterID).
+		Where("academic_records.status IN ?", []string{models.StatusPAE, models.StatusPIC}).
+		Find(&students).Error; err != nil {
+		return err
+	}
+
+	closesAt := ""
+	if round.ClosesAt != nil {
+		closesAt = round.ClosesAt.Format(dateTimeLayout)
+	}
+	title, body := cohortNotification(round, event, closesAt)
+	for _, st := range students {
+		if err := notify(tx, StudentRecipient(st.ID), event, title, body); err != nil {
+			return err
+		}
+		if err := enqueueEmail(tx, event, st.Email, map[string]any{
+			"StudentName": st.Name,
+			"Period1":     round.Period1.Code,
+			"Period2":     round.Period2.Code,
+			"ClosesAt":    closesAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cohortNotification monta o texto da notificação de rodada no app.
+func cohortNotification(round *models.PlanRound, event, closesAt string) (title, body string) {
+	periods := round.Period1.Code + "–" + round.Period2.Code
+	if event == EmailRoundClosing {
+		return "Rodada de planos " + periods + " encerra em breve",
+			"O prazo para cadastrar o seu plano de estudos termina em " + closesAt + "."
+	}
+	body = "Cadastre o seu plano de estudos para os períodos " + periods
+	if closesAt != "" {
+		body += " até " + closesAt
+	}
+	return "Rodada de planos " + periods + " aberta", body + "."
+}
+
 func (s *PlanRoundService) preloaded() *gorm.DB {
-	return s.db.Preload("BaseSemester").Preload("Period1").Preload("Period2")
+	return s.db.Preload("BaseSemester").Preload("Period1").Preload("Period2").Preload("OpenedBy", authorName)
 }
 
 func (s *PlanRoundService) load(id uint) (*models.PlanRound, error) {
@@ -291,6 +409,25 @@ func (s *PlanRoundService) load(id uint) (*models.PlanRound, error) {
 	return &round, nil
 }
 
+// closeOpenRounds encerra, na transação tx, a rodada que estiver aberta
+// (RN19), publicando round.closed para ela.
+func closeOpenRounds(tx *gorm.DB) error {
+	var open []models.PlanRound
+	if err := tx.Preload("BaseSemester").Preload("Period1").Preload("Period2").
+		Where("open = ?", true).Find(&open).Error; err != nil {
+		return err
+	}
+	for i := range open {
+		if err := tx.Model(&open[i]).Update("open", false).Error; err != nil {
+			return err
+		}
+		if err := publishEvent(tx, EventRoundClosed, roundEventData(&open[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ensureSemester localiza ou cria o semestre pelo código. Os períodos-alvo
 // são futuros e ainda não importados; quando os dados reais chegarem, a
 // importação casa pelo mesmo código (FirstOrCreate) — sem duplicação.
@@ -11,7 +11,7 @@ func TestOpenRequiresImportedData(t *testing.T) {
 	db := newTestDB(t)
 	rounds := NewPlanRoundService(db)
 
-	if _, err := rounds.Open("2026/1", "2026/2", 1); !errors.Is(err, ErrInvalid) {
+	if _, err := rounds.Open("2026/1", "2026/2", nil, 1); !errors.Is(err, ErrInvalid) {
 		t.Fatalf("abrir sem dados deve dar ErrInvalid; obtive %v", err)
 	}
 }
@@ -42,7 +42,7 @@ func TestCohortReturnsOnlyPaePicOfBaseSemester(t *testing.T) {
 	seedStudentWithStatus(t, db, "D", "2024/2", models.StatusPAE)     // outro semestre
 
 	round := openRoundFor(t, rounds, "2026/1", "2026/2") // base = 2025/2
-	_, students, err := rounds.Cohort(round.ID)
+	_, students, err := rounds.Cohort(round.ID, 0, AllCourses())
 	if err != nil {
 		t.Fatalf("Cohort: %v", err)
 	}
@@ -93,12 +93,12 @@ func TestOpenRejectsOverlappingPeriods(t *testing.T) {
 	openRoundFor(t, rounds, "2026/1", "2026/2")
 
 	// 2026/2 já pertence à primeira rodada → deve barrar.
-	if _, err := rounds.Open("2026/2", "2027/1", 1); !errors.Is(err, ErrInvalid) {
+	if _, err := rounds.Open("2026/2", "2027/1", nil, 1); !errors.Is(err, ErrInvalid) {
 		t.Fatalf("período sobreposto deve dar ErrInvalid; obtive %v", err)
 	}
 
 	// Períodos totalmente novos → deve permitir.
-	if _, err := rounds.Open("2027/1", "2027/2", 1); err != nil {
+	if _, err := rounds.Open("2027/1", "2027/2", nil, 1); err != nil {
 		t.Fatalf("períodos novos devem permitir; obtive %v", err)
 	}
 }
@@ -129,7 +129,7 @@ func TestDeleteRoundRemovesPlansAndFreesPeriods(t *testing.T) {
 		t.Errorf("planos do período deveriam ser removidos; restaram %d", planCount)
 	}
 	// Período liberado: abrir nova rodada reutilizando 2026/1 deve funcionar.
-	if _, err := rounds.Open("2026/1", "2028/1", 1); err != nil {
+	if _, err := rounds.Open("2026/1", "2028/1", nil, 1); err != nil {
 		t.Errorf("período liberado deveria permitir nova rodada; obtive %v", err)
 	}
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Projeção do semestre de formatura (RN33): a carga horária que falta
+// integralizar dividida pelo ritmo histórico do aluno, comparada com o
+// prazo máximo do curso contado a partir do ingresso.
+
+// GraduationProjection é a estimativa feita a partir do registro mais
+// recente (BasedOn). Pace é a média de horas integralizadas por semestre;
+// SemestersRemaining é -1 e ExpectedSemester fica vazio quando não há como
+// projetar. DeadlineSemester é o último semestre dentro do prazo máximo
+// (vazio se o curso não tem prazo configurado ou o ingresso é desconhecido).
+type GraduationProjection struct {
+	BasedOn              string  `json:"based_on"`
+	IntegralizedHours    int     `json:"integralized_hours"`
+	TotalHours           int     `json:"total_hours"`
+	RemainingHours       int     `json:"remaining_hours"`
+	Pace                 float64 `json:"pace"`
+	SemestersRemaining   int     `json:"semesters_remaining"`
+	ExpectedSemester     string  `json:"expected_semester"`
+	MaxDurationSemesters int     `json:"max_duration_semesters"`
+	DeadlineSemester     string  `json:"deadline_semester"`
+	WillExceed           bool    `json:"will_exceed"`
+}
+
+// projectionPoint é a carga horária de um registro no seu semestre.
+type projectionPoint struct {
+	Code              string
+	IntegralizedHours int
+	TotalHours        int
+}
+
+// semesterIndex converte "2025/1" em um número sequencial de semestres
+// (dois por ano); ok é falso para códigos fora do formato.
+func semesterIndex(code string) (int, bool) {
+	year, period, found := strings.Cut(code, "/")
+	y, err1 := strconv.Atoi(year)
+	p, err2 := strconv.Atoi(period)
+	if !found || err1 != nil || err2 != nil || p < 1 || p > 2 {
+		return 0, false
+	}
+	return y*2 + p - 1, true
+}
+
+// semesterStart é a data de referência do semestre: 1º de janeiro para o
+// primeiro e 1º de julho para o segundo, no fuso do servidor.
+func semesterStart(code string) (time.Time, bool) {
+	i, ok := semesterIndex(code)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Date(i/2, time.Month(1+6*(i%2)), 1, 0, 0, 0, 0, time.Local), true
+}
+
+func semesterCode(index int) string {
+	return strconv.Itoa(index/2) + "/" + strconv.Itoa(index%2+1)
+}
+
+// projectGraduation estima a formatura a partir do primeiro registro com
+// carga horária (first) e do mais recente (last). O ritmo é o avanço entre
+// os dois; com um único semestre de histórico, a média desde o ingresso.
+// Sem avanço nenhum o aluno não chega a se formar e, havendo prazo, o
+// excede.
+func projectGraduation(entryYear int, entryPeriod string, maxSemesters int, first, last projectionPoint) GraduationProjection {
+	p := GraduationProjection{
+		BasedOn:              last.Code,
+		IntegralizedHours:    last.IntegralizedHours,
+		TotalHours:           last.TotalHours,
+		RemainingHours:       max(last.TotalHours-last.IntegralizedHours, 0),
+		SemestersRemaining:   -1,
+		MaxDurationSemesters: maxSemesters,
+	}
+	lastIndex, ok := semesterIndex(last.Code)
+	if !ok || last.TotalHours <= 0 {
+		return p
+	}
+
+	deadline := -1
+	if maxSemesters > 0 {
+		if elapsed := semestersBetween(entryYear, entryPeriod, last.Code); elapsed >= 0 {
+			deadline = lastIndex - elapsed + maxSemesters - 1
+			p.DeadlineSemester = semesterCode(deadline)
+		}
+	}
+
+	known := false
+	if firstIndex, ok := semesterIndex(first.Code); ok && firstIndex < lastIndex {
+		p.Pace, known = float64(last.IntegralizedHours-first.IntegralizedHours)/float64(lastIndex-firstIndex), true
+	} else if elapsed := semestersBetween(entryYear, entryPeriod, last.Code); elapsed > 0 {
+		p.Pace, known = float64(last.IntegralizedHours)/float64(elapsed), true
+	}
+	p.Pace = round2(math.Max(p.Pace, 0))
+
+	switch {
+	case p.RemainingHours == 0:
+		p.SemestersRemaining, p.ExpectedSemester = 0, last.Code
+	case p.Pace > 0:
+		p.SemestersRemaining = int(math.Ceil(float64(p.RemainingHours) / p.Pace))
+		p.ExpectedSemester = semesterCode(lastIndex + p.SemestersRemaining)
+	case known:
+		// Ritmo nulo: sem projeção, mas fora do prazo se houver um.
+		p.WillExceed = deadline >= 0
+		return p
+	}
+	if deadline >= 0 && p.ExpectedSemester != "" {
+		p.WillExceed = lastIndex+p.SemestersRemaining > deadline
+	}
+	return p
+}
+
+// ProjectGraduation projeta a formatura do aluno a partir do histórico em
+// ordem de semestre (com Semester carregado) e do prazo do curso (Course
+// carregado); nil se não há registros.
+func ProjectGraduation(student models.Student, records []models.AcademicRecord) *GraduationProjection {
+	if len(records) == 0 {
+		return nil
+	}
+	first := -1
+	for i, r := range records {
+		if r.TotalHours > 0 {
+			first = i
+			break
+		}
+	}
+	last := records[len(records)-1]
+	firstPoint := projectionPoint{Code: last.Semester.Code}
+	if first >= 0 {
+		firstPoint = projectionPoint{Code: records[first].Semester.Code, IntegralizedHours: records[first].IntegralizedHours, TotalHours: records[first].TotalHours}
+	}
+	p := projectGraduation(student.EntryYear, student.EntryPeriod, student.Course.MaxDurationSemesters, firstPoint,
+		projectionPoint{Code: last.Semester.Code, IntegralizedHours: last.IntegralizedHours, TotalHours: last.TotalHours})
+	return &p
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestProjectGraduation(t *testing.T) {
+	// Ingresso em 2020/1, 1200 h em 2022/1 e 1800 h em 2024/1: 150 h por
+	// semestre; faltam 1200 h, oito semestres, formatura em 2028/1.
+	first := projectionPoint{Code: "2022/1", IntegralizedHours: 1200, TotalHours: 3000}
+	last := projectionPoint{Code: "2024/1", IntegralizedHours: 1800, TotalHours: 3000}
+	p := projectGraduation(2020, "1", 14, first, last)
+	if p.Pace != 150 || p.SemestersRemaining != 8 || p.ExpectedSemester != "2028/1" {
+		t.Errorf("projeção pelo ritmo histórico: %+v", p)
+	}
+	// Prazo de 14 semestres a partir de 2020/1: até 2026/2.
+	if p.DeadlineSemester != "2026/2" || !p.WillExceed {
+		t.Errorf("prazo máximo: %+v", p)
+	}
+	if p := projectGraduation(2020, "1", 20, first, last); p.WillExceed {
+		t.Errorf("dentro de um prazo de 20 semestres: %+v", p)
+	}
+
+	// Um único registro: a média desde o ingresso (1800 h em 8 semestres).
+	if p := projectGraduation(2020, "1", 0, last, last); p.Pace != 225 || p.ExpectedSemester != "2027/1" || p.WillExceed {
+		t.Errorf("projeção pela média desde o ingresso: %+v", p)
+	}
+	// Sem avanço entre os registros não há formatura à vista.
+	stalled := projectionPoint{Code: "2024/1", IntegralizedHours: 1200, TotalHours: 3000}
+	if p := projectGraduation(2020, "1", 14, first, stalled); p.ExpectedSemester != "" || p.SemestersRemaining != -1 || !p.WillExceed {
+		t.Errorf("ritmo nulo: %+v", p)
+	}
+	// Calouro sem histórico: nada a projetar nem a sinalizar.
+	freshman := projectionPoint{Code: "2024/1", TotalHours: 3000}
+	if p := projectGraduation(2024, "1", 14, freshman, freshman); p.ExpectedSemester != "" || p.WillExceed {
+		t.Errorf("calouro: %+v", p)
+	}
+}
+
+func TestExceedsMaxDurationFilter(t *testing.T) {
+	db := newTestDB(t)
+	courses := NewCourseService(db)
+	reports := NewReportService(db)
+	students := NewStudentService(db)
+
+	ana := seedStudentWithStatus(t, db, "ana", "2024/1", models.StatusRegular)
+	db.Model(ana).Updates(map[string]any{"entry_year": 2020, "entry_period": "1"})
+	db.Model(&models.AcademicRecord{}).Where("student_id = ?", ana.ID).
+		Updates(map[string]any{"integralized_hours": 1800, "total_hours": 3000})
+	var sem models.Semester
+	db.Where("code = ?", "2024/1").First(&sem)
+	semesterID := strconv.FormatUint(uint64(sem.ID), 10)
+
+	exceeding := func() int {
+		t.Helper()
+		records, _, err := reports.Records(RecordsFilter{SemesterID: semesterID, ExceedsMaxDuration: true})
+		if err != nil {
+			t.Fatalf("Records: %v", err)
+		}
+		return len(records)
+	}
+	if err := recomputeRecords(db, nil); err != nil {
+		t.Fatalf("recomputeRecords: %v", err)
+	}
+	if n := exceeding(); n != 0 {
+		t.Errorf("curso sem prazo configurado: %d acima do prazo; esperado 0", n)
+	}
+
+	// 225 h por semestre: formatura em 2027/1, depois do fim de 2025/2.
+	course, err := courses.SetMaxDuration(ana.CourseID, 12, Actor{UserID: 1})
+	if err != nil || course.MaxDurationSemesters != 12 {
+		t.Fatalf("SetMaxDuration: %v %+v", err, course)
+	}
+	if n := exceeding(); n != 1 {
+		t.Errorf("com prazo de 12 semestres: %d acima do prazo; esperado 1", n)
+	}
+
+	student, history, err := students.History("ana")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	p := ProjectGraduation(*student, history)
+	if p == nil || p.ExpectedSemester != "2027/1" || p.DeadlineSemester != "2025/2" || !p.WillExceed {
+		t.Errorf("projeção no histórico: %+v", p)
+	}
+	if history[0].ExpectedGraduation != "2027/1" {
+		t.Errorf("projeção gravada no registro: %q", history[0].ExpectedGraduation)
+	}
+
+	if _, err := courses.SetMaxDuration(ana.CourseID, -1, Actor{UserID: 1}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("prazo negativo deveria ser inválido; obtive %v", err)
+	}
+	if _, err := courses.SetMaxDuration(999, 12, Actor{UserID: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("curso inexistente: %v", err)
+	}
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adamanagement/backend/internal/mail"
+	"adamanagement/backend/internal/models"
+)
+
+// ReportScheduleIndicators é o resumo de indicadores do semestre por
+// curso, entregável além dos relatórios das visões salvas.
+const ReportScheduleIndicators = "indicators"
+
+// maxScheduleRecipients limita os destinatários de uma assinatura.
+const maxScheduleRecipients = 20
+
+// ReportScheduleService mantém as assinaturas de entrega de relatórios e
+// as executa: o relatório é gerado no escopo de cursos de quem criou a
+// assinatura e enviado como anexo pela outbox de e-mails (RN36).
+type ReportScheduleService struct {
+	db         *gorm.DB
+	views      *ReportViewService
+	indicators *IndicatorsService
+	scopes     *ScopeService
+}
+
+func NewReportScheduleService(db *gorm.DB, perms PermissionChecker) *ReportScheduleService {
+	return &ReportScheduleService{
+		db:         db,
+		views:      NewReportViewService(db),
+		indicators: NewIndicatorsService(db),
+		scopes:     NewScopeService(db, perms),
+	}
+}
+
+type ReportScheduleInput struct {
+	Name       string
+	Cron       string
+	Report     string
+	Filters    ReportViewFilters
+	Columns    []string
+	Sort       string
+	Format     string
+	Recipients []string
+	Active     *bool
+}
+
+func (s *ReportScheduleService) List() ([]models.ReportSchedule, error) {
+	var schedules []models.ReportSchedule
+	if err := s.db.Preload("User", authorName).Order("name asc").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *ReportScheduleService) Get(id uint) (*models.ReportSchedule, error) {
+	var schedule models.ReportSchedule
+	if err := s.db.Preload("User", authorName).First(&schedule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Assinatura não encontrada")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Create cadastra a assinatura (ativa por padrão) em nome de userID, cujo
+// escopo de cursos delimita os relatórios entregues.
+func (s *ReportScheduleService) Create(userID uint, in ReportScheduleInput) (*models.ReportSchedule, error) {
+	schedule := models.ReportSchedule{UserID: userID}
+	if err := s.save(&schedule, in, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.Get(schedule.ID)
+}
+
+// Update substitui a configuração da assinatura e recalcula a próxima
+// execução. A assinatura passa a ser de userID: quem edita responde pelo
+// conteúdo, e as entregas seguintes usam o escopo de cursos dele, não o
+// de quem a criou.
+func (s *ReportScheduleService) Update(id, userID uint, in ReportScheduleInput) (*models.ReportSchedule, error) {
+	schedule, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	schedule.UserID = userID
+	if err := s.save(schedule, in, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Delete remove a assinatura e o seu histórico.
+func (s *ReportScheduleService) Delete(id uint) error {
+	schedule, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", schedule.ID).Delete(&models.ReportScheduleRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(schedule).Error
+	})
+}
+
+// save valida a entrada e grava a assinatura; NextRunAt é o próximo
+// horário da agenda após now (nil se inativa).
+func (s *ReportScheduleService) save(schedule *models.ReportSchedule, in ReportScheduleInput, now time.Time) error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return Invalid("name é obrigatório (até 100 caracteres)")
+	}
+	cron, err := parseCron(in.Cron)
+	if err != nil {
+		return err
+	}
+	switch in.Report {
+	case ReportScheduleIndicators:
+		if len(in.Columns) > 0 || in.Sort != "" {
+			return Invalid("o resumo de indicadores não aceita columns nem sort")
+		}
+		if in.Filters != (ReportViewFilters{SemesterID: in.Filters.SemesterID}) {
+			return Invalid("o resumo de indicadores aceita apenas o filtro semester_id")
+		}
+	case ReportViewRecords, ReportViewStudents:
+		if err := validateViewQuery(in.Report, in.Sort, in.Filters, in.Columns); err != nil {
+			return err
+		}
+	default:
+		return Invalid("report inválido: use records, students ou indicators")
+	}
+	if !ValidExportFormat(in.Format) {
+		return Invalid("format inválido: use csv ou xlsx")
+	}
+	recipients, err := normalizeRecipients(in.Recipients)
+	if err != nil {
+		return err
+	}
+	filters, err := json.Marshal(in.Filters)
+	if err != nil {
+		return err
+	}
+
+	schedule.Name = name
+	schedule.Cron = strings.TrimSpace(in.Cron)
+	schedule.Report = in.Report
+	schedule.Filters = string(filters)
+	schedule.Columns = strings.Join(in.Columns, ",")
+	schedule.Sort = in.Sort
+	schedule.Format = in.Format
+	schedule.Recipients = strings.Join(recipients, ",")
+	schedule.Active = in.Active == nil || *in.Active
+	schedule.NextRunAt = nil
+	if schedule.Active {
+		next := cron.next(now)
+		if next.IsZero() {
+			return Invalid("cron não tem nenhuma ocorrência nos próximos anos")
+		}
+		schedule.NextRunAt = &next
+	}
+	// O responsável carregado por Get não é gravado: vale UserID.
+	return s.db.Omit(clause.Associations).Save(schedule).Error
+}
+
+// normalizeRecipients valida os e-mails, em minúsculas e sem repetição.
+func normalizeRecipients(in []string) ([]string, error) {
+	var out []string
+	for _, raw := range in {
+		addr, err := netmail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil || addr.Name != "" {
+			return nil, Invalid("destinatário inválido: " + raw)
+		}
+		email := strings.ToLower(addr.Address)
+		if !slices.Contains(out, email) {
+			out = append(out, email)
+		}
+	}
+	if len(out) == 0 || len(out) > maxScheduleRecipients {
+		return nil, Invalid(fmt.Sprintf("informe de 1 a %d destinatários", maxScheduleRecipients))
+	}
+	return out, nil
+}
+
+// Runs devolve o histórico de execuções da assinatura, mais recentes
+// primeiro. Quando limit > 0 a consulta é paginada e o total é calculado;
+// caso contrário total é -1.
+func (s *ReportScheduleService) Runs(scheduleID uint, limit, offset int) ([]models.ReportScheduleRun, int64, error) {
+	if _, err := s.Get(scheduleID); err != nil {
+		return nil, 0, err
+	}
+
+	q := s.db.Model(&models.ReportScheduleRun{}).Where("schedule_id = ?", scheduleID)
+	total := int64(-1)
+	if limit > 0 {
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+		q = q.Limit(limit).Offset(offset)
+	}
+
+	var runs []models.ReportScheduleRun
+	if err := q.Order("id desc").Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// RunNow executa a assinatura imediatamente, sem alterar a agenda.
+func (s *ReportScheduleService) RunNow(id uint) (*models.ReportScheduleRun, error) {
+	schedule, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(schedule, models.ScheduleTriggerManual)
+}
+
+// RunDue executa as assinaturas ativas vencidas em now e devolve quantas
+// foram executadas. Cada uma tem a próxima execução avançada antes de
+// rodar, na mesma transação que a retira da fila: outra instância não a
+// repete, e uma falha não a faz rodar de novo antes do próximo horário.
+func (s *ReportScheduleService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.claimDue(now)
+	if err != nil {
+		return 0, err
+	}
+	ran := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := s.run(&due[i], models.ScheduleTriggerCron); err != nil {
+			return ran, err
+		}
+		ran++
+	}
+	return ran, nil
+}
+
+func (s *ReportScheduleService) claimDue(now time.Time) ([]models.ReportSchedule, error) {
+	var due []models.ReportSchedule
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		q := tx.Where("active = ? AND next_run_at <= ?", true, now).
+			Order("next_run_at asc").
+			Limit(outboxBatchSize)
+		if tx.Dialector.Name() == "postgres" {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := q.Find(&due).Error; err != nil {
+			return err
+		}
+		for i := range due {
+			var next *time.Time
+			if cron, err := parseCron(due[i].Cron); err == nil {
+				if t := cron.next(now); !t.IsZero() {
+					next = &t
+				}
+			}
+			due[i].NextRunAt = next
+			if err := tx.Model(&due[i]).Update("next_run_at", next).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return due, err
+}
+
+// run gera o relatório e enfileira um e-mail por destinatário, gravando
+// a execução no histórico. Falhas na geração (semestre inexistente,
+// responsável desativado…) viram uma execução com status failed; o erro
+// devolvido é apenas o de banco ao gravar o histórico.
+func (s *ReportScheduleService) run(schedule *models.ReportSchedule, trigger string) (*models.ReportScheduleRun, error) {
+	run := models.ReportScheduleRun{
+		ScheduleID: schedule.ID,
+		Trigger:    trigger,
+		StartedAt:  time.Now(),
+	}
+	table, semester, buildErr := s.build(schedule)
+	run.SemesterCode = semester
+
+	var file bytes.Buffer
+	if buildErr == nil {
+		buildErr = table.Write(&file, schedule.Format)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if buildErr != nil {
+			run.Status = models.ScheduleRunFailed
+			run.Error = buildErr.Error()
+		} else {
+			// Um anexo para todos os destinatários.
+			attachment, err := storeAttachment(tx, mail.Attachment{
+				Name:        scheduleFileName(schedule.Name, semester, schedule.Format),
+				ContentType: ExportContentType(schedule.Format),
+				Data:        file.Bytes(),
+			})
+			if err != nil {
+				return err
+			}
+			data := map[string]any{"Name": schedule.Name, "SemesterCode": semester, "Rows": len(table.Rows)}
+			for _, to := range strings.Split(schedule.Recipients, ",") {
+				if err := enqueueEmailWithAttachment(tx, EmailReportDelivery, to, data, attachment); err != nil {
+					return err
+				}
+				run.Recipients++
+			}
+			run.Status = models.ScheduleRunSuccess
+			run.Rows = len(table.Rows)
+		}
+		run.FinishedAt = time.Now()
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		schedule.LastRunAt = &run.FinishedAt
+		return tx.Model(schedule).Update("last_run_at", run.FinishedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// build gera a tabela da assinatura no semestre dos filtros ou, sem ele,
+// no mais recente com dados; devolve também o código do semestre.
+func (s *ReportScheduleService) build(schedule *models.ReportSchedule) (Table, string, error) {
+	var owner models.User
+	if err := s.db.First(&owner, schedule.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Table{}, "", errors.New("o responsável pela assinatura foi removido")
+		}
+		return Table{}, "", err
+	}
+	if owner.DeactivatedAt != nil {
+		return Table{}, "", errors.New("o responsável pela assinatura está desativado")
+	}
+	scope, err := s.scopes.Resolve(&Claims{UserID: owner.ID, Role: owner.Role})
+	if err != nil {
+		return Table{}, "", err
+	}
+
+	view := models.ReportView{
+		UserID:  schedule.UserID,
+		Name:    schedule.Name,
+		Report:  schedule.Report,
+		Filters: schedule.Filters,
+		Columns: schedule.Columns,
+		Sort:    schedule.Sort,
+	}
+	f, err := ViewFilters(view)
+	if err != nil {
+		return Table{}, "", err
+	}
+	var semester models.Semester
+	if f.SemesterID == "" {
+		latest, err := latestDataSemester(s.db)
+		if err != nil {
+			return Table{}, "", err
+		}
+		if latest == nil {
+			return Table{}, "", errors.New("não há semestre com dados importados")
+		}
+		semester = *latest
+	} else if err := s.db.First(&semester, "id = ?", f.SemesterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Table{}, "", errors.New("o semestre dos filtros não existe mais")
+		}
+		return Table{}, "", err
+	}
+	semesterID := fmt.Sprint(semester.ID)
+
+	if schedule.Report == ReportScheduleIndicators {
+		data, err := s.indicators.Breakdown(semesterID, BreakdownByCourse, scope)
+		if err != nil {
+			return Table{}, semester.Code, err
+		}
+		return BreakdownTable(data), semester.Code, nil
+	}
+	table, _, err := s.views.execute(view, ReportViewRun{SemesterID: semesterID, Scope: scope})
+	return table, semester.Code, err
+}
+
+// scheduleFileName monta o nome do anexo: o nome da assinatura sem
+// caracteres problemáticos, seguido do semestre.
+func scheduleFileName(name, semester, format string) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, name)
+	return clean + " " + strings.ReplaceAll(semester, "/", "-") + "." + format
+}
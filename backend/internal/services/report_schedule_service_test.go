@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC) // quarta-feira
+	for _, tc := range []struct {
+		expr, want string
+	}{
+		{"0 7 * * 1", "2026-03-09 07:00"},    // próxima segunda
+		{"*/15 * * * *", "2026-03-04 10:45"}, // passo
+		{"30 10 * * *", "2026-03-05 10:30"},  // estritamente depois
+		{"0 8 1,15 * *", "2026-03-15 08:00"}, // lista de dias
+		{"0 8 1 * 7", "2026-03-08 08:00"},    // dia do mês OU domingo (7)
+		{"0 9-17/4 * * 1-5", "2026-03-04 13:00"},
+		{"@monthly", "2026-04-01 00:00"},
+	} {
+		c, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tc.expr, err)
+		}
+		if got := c.next(base).Format("2006-01-02 15:04"); got != tc.want {
+			t.Errorf("%q: próxima execução %s, esperava %s", tc.expr, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "0 0 0 * *", "*/0 * * * *", "0 0 * * 8", "a * * * *"} {
+		if _, err := parseCron(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q deveria ser inválida; obtive %v", bad, err)
+		}
+	}
+	if c, _ := parseCron("0 0 31 2 *"); !c.next(base).IsZero() {
+		t.Error("31 de fevereiro não deveria ter ocorrência")
+	}
+}
+
+func TestReportScheduleRunsDueAndKeepsHistory(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, "secret")
+	admin, err := auth.CreateUser("Chefia", "chefia@ufes.br", "segredo", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	// Críticos: em regularidade com trancamentos acima do limiar.
+	for _, s := range []*models.Student{
+		seedStudentWithStatus(t, db, "1", "2024/1", models.StatusRegular),
+		seedStudentWithStatus(t, db, "2", "2024/2", models.StatusRegular),
+	} {
+		db.Model(&models.AcademicRecord{}).Where("student_id = ?", s.ID).Update("locks", 9)
+	}
+	seedStudentWithStatus(t, db, "3", "2024/2", models.StatusRegular)
+
+	svc := NewReportScheduleService(db, NewRoleService(db))
+	schedule, err := svc.Create(admin.ID, ReportScheduleInput{
+		Name:       "Críticos da semana",
+		Cron:       "0 7 * * 1",
+		Report:     ReportViewRecords,
+		Filters:    ReportViewFilters{Mode: "critical"},
+		Columns:    []string{"registration", "status"},
+		Format:     ExportCSV,
+		Recipients: []string{"Chefia@ufes.br", "coord@ufes.br", "chefia@ufes.br"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if schedule.Recipients != "chefia@ufes.br,coord@ufes.br" || schedule.NextRunAt == nil || schedule.NextRunAt.Weekday() != time.Monday {
+		t.Fatalf("assinatura gravada incorretamente: %+v", schedule)
+	}
+
+	// Antes do horário nada roda; depois, roda uma única vez.
+	if n, _ := svc.RunDue(context.Background(), schedule.NextRunAt.Add(-time.Minute)); n != 0 {
+		t.Fatalf("executou antes do horário: %d", n)
+	}
+	due := *schedule.NextRunAt
+	if n, err := svc.RunDue(context.Background(), due); err != nil || n != 1 {
+		t.Fatalf("RunDue: %d %v", n, err)
+	}
+	if n, _ := svc.RunDue(context.Background(), due); n != 0 {
+		t.Errorf("a mesma ocorrência rodou de novo: %d", n)
+	}
+	reloaded, _ := svc.Get(schedule.ID)
+	if want := due.AddDate(0, 0, 7); reloaded.NextRunAt == nil || !reloaded.NextRunAt.Equal(want) || reloaded.LastRunAt == nil {
+		t.Errorf("agenda não avançou para %v: %+v", want, reloaded)
+	}
+
+	var outbox []models.OutboxEmail
+	db.Preload("Attachment").Order("id").Find(&outbox)
+	if len(outbox) != 2 || outbox[0].Event != EmailReportDelivery || outbox[1].Recipient != "coord@ufes.br" {
+		t.Fatalf("esperava um e-mail por destinatário: %+v", outbox)
+	}
+	// Sem semestre nos filtros, vale o mais recente com dados: só o aluno 2.
+	// O anexo é gravado uma vez e referenciado pelas duas mensagens.
+	if a := outbox[0].Attachment; a == nil || *outbox[0].AttachmentID != *outbox[1].AttachmentID ||
+		a.Name != "Críticos da semana 2024-2.csv" ||
+		string(a.Data) != "Matrícula;Status\n2;"+models.StatusRegular+"\n" {
+		t.Errorf("anexo incorreto: %+v", a)
+	}
+	var attachments int64
+	db.Model(&models.OutboxAttachment{}).Count(&attachments)
+	if attachments != 1 {
+		t.Errorf("esperava um anexo para todos os destinatários; obtive %d", attachments)
+	}
+	sender := &stubSender{}
+	if n, err := NewOutboxDispatcher(db, sender).Dispatch(context.Background()); err != nil || n != 2 {
+		t.Fatalf("Dispatch: %d %v", n, err)
+	}
+	for _, msg := range sender.sent {
+		if len(msg.Attachments) != 1 || msg.Attachments[0].Name != "Críticos da semana 2024-2.csv" {
+			t.Errorf("mensagem enviada sem o anexo: %+v", msg)
+		}
+	}
+
+	// Criador desativado: a execução manual falha e fica no histórico.
+	db.Model(admin).Update("deactivated_at", time.Now())
+	run, err := svc.RunNow(schedule.ID)
+	if err != nil || run.Status != models.ScheduleRunFailed || !strings.Contains(run.Error, "desativado") {
+		t.Errorf("execução com criador desativado: %+v %v", run, err)
+	}
+	runs, total, _ := svc.Runs(schedule.ID, 10, 0)
+	if total != 2 || runs[0].Trigger != models.ScheduleTriggerManual ||
+		runs[1].Status != models.ScheduleRunSuccess || runs[1].Rows != 1 || runs[1].Recipients != 2 || runs[1].SemesterCode != "2024/2" {
+		t.Errorf("histórico incorreto: %+v", runs)
+	}
+
+	// Resumo de indicadores por curso, com a linha do conjunto.
+	db.Model(admin).Update("deactivated_at", nil)
+	summary, err := svc.Create(admin.ID, ReportScheduleInput{
+		Name: "Indicadores", Cron: "@weekly", Report: ReportScheduleIndicators, Format: ExportXLSX, Recipients: []string{"chefia@ufes.br"},
+	})
+	if err != nil {
+		t.Fatalf("Create indicadores: %v", err)
+	}
+	if run, err := svc.RunNow(summary.ID); err != nil || run.Status != models.ScheduleRunSuccess || run.Rows != 2 {
+		t.Errorf("resumo de indicadores: %+v %v", run, err)
+	}
+
+	for _, bad := range []ReportScheduleInput{
+		{Name: "x", Cron: "toda segunda", Report: ReportViewRecords, Format: ExportCSV, Recipients: []string{"a@ufes.br"}},
+		{Name: "x", Cron: "@daily", Report: "grades", Format: ExportCSV, Recipients: []string{"a@ufes.br"}},
+		{Name: "x", Cron: "@daily", Report: ReportViewRecords, Format: "pdf", Recipients: []string{"a@ufes.br"}},
+		{Name: "x", Cron: "@daily", Report: ReportViewRecords, Format: ExportCSV},
+		{Name: "x", Cron: "@daily", Report: ReportViewRecords, Format: ExportCSV, Recipients: []string{"não é e-mail"}},
+		{Name: "x", Cron: "@daily", Report: ReportScheduleIndicators, Format: ExportCSV, Recipients: []string{"a@ufes.br"}, Sort: "risk"},
+	} {
+		if _, err := svc.Create(admin.ID, bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v deveria ser inválida; obtive %v", bad, err)
+		}
+	}
+
+	// Quem edita assume a assinatura: as entregas passam a usar o escopo
+	// dele, aqui sem nenhum curso vinculado: resta só a linha do total.
+	editor, _ := auth.CreateUser("Coordenação", "coord@ufes.br", "segredo", models.RoleUser)
+	edited, err := svc.Update(summary.ID, editor.ID, ReportScheduleInput{
+		Name: "Indicadores", Cron: "@weekly", Report: ReportScheduleIndicators, Format: ExportXLSX, Recipients: []string{"chefia@ufes.br"},
+	})
+	if err != nil || edited.UserID != editor.ID || edited.User == nil || edited.User.Name != editor.Name {
+		t.Fatalf("Update por outro usuário: %+v %v", edited, err)
+	}
+	if run, err := svc.RunNow(summary.ID); err != nil || run.Status != models.ScheduleRunSuccess || run.Rows != 1 {
+		t.Errorf("entrega deveria seguir o escopo de quem editou: %+v %v", run, err)
+	}
+
+	// Inativa não tem próxima execução.
+	inactive := false
+	if s, err := svc.Update(summary.ID, admin.ID, ReportScheduleInput{
+		Name: "Indicadores", Cron: "@weekly", Report: ReportScheduleIndicators, Format: ExportXLSX,
+		Recipients: []string{"chefia@ufes.br"}, Active: &inactive,
+	}); err != nil || s.NextRunAt != nil {
+		t.Errorf("assinatura desativada: %+v %v", s, err)
+	}
+
+	if err := svc.Delete(schedule.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := svc.Runs(schedule.ID, 0, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("histórico de assinatura removida: %v", err)
+	}
+}
@@ -23,18 +23,61 @@ func (s *ReportService) Semesters() ([]models.Semester, error) {
 type RecordsFilter struct {
 	SemesterID   string
 	Registration string
+	// StudentName e CourseName casam sem diferenciar maiúsculas nem
+	// acentos, palavra a palavra (matchFolded).
 	StudentName  string
 	CourseName   string
 	Status       string
 	CriticalOnly bool
-	MaxPending   *int
-	Limit        int
-	Offset       int
+	// NearGraduationOnly restringe aos próximos da formatura pelas regras
+	// de triagem vigentes; MaxPending é um limite explícito, independente
+	// delas.
+	NearGraduationOnly bool
+	MaxPending         *int
+	// ExceedsMaxDuration restringe aos alunos que, no ritmo atual, passam
+	// do prazo máximo do curso (RN33).
+	ExceedsMaxDuration bool
+	AdvisorID          uint // > 0: apenas alunos atribuídos a este orientador ("meus alunos")
+	// Sort ordena o relatório pelas chaves de recordSortKeys; vazio mantém
+	// a ordem de importação (ou, nos filtros de pendências, a de menos
+	// obrigatórias pendentes). "risk" e "risk_asc" seguem aceitos.
+	Sort  string
+	Scope CourseScope
+	Page  Page
 }
 
-// Records retorna o relatório acadêmico. Quando Limit > 0 a consulta é
-// paginada e o total de registros é calculado; caso contrário total é -1.
-func (s *ReportService) Records(f RecordsFilter) ([]models.AcademicRecord, int64, error) {
+var recordSortKeys = []sortKey[models.AcademicRecord]{
+	{"registration", "students.registration", func(r models.AcademicRecord) any { return r.Student.Registration }},
+	{"name", "students.name", func(r models.AcademicRecord) any { return r.Student.Name }},
+	{"course", "courses.name", func(r models.AcademicRecord) any { return r.Student.Course.Name }},
+	{"status", "academic_records.status", func(r models.AcademicRecord) any { return r.Status }},
+	{"integralized_hours", "academic_records.integralized_hours", func(r models.AcademicRecord) any { return r.IntegralizedHours }},
+	{"total_hours", "academic_records.total_hours", func(r models.AcademicRecord) any { return r.TotalHours }},
+	{"pending_obligatory", "academic_records.pending_obligatory", func(r models.AcademicRecord) any { return r.PendingObligatory }},
+	{"locks", "academic_records.locks", func(r models.AcademicRecord) any { return r.Locks }},
+	{"semesters_no_hours", "academic_records.semesters_no_hours", func(r models.AcademicRecord) any { return r.SemestersNoHours }},
+	{"risk_score", "academic_records.risk_score", func(r models.AcademicRecord) any { return r.RiskScore }},
+	{"expected_graduation", "academic_records.expected_graduation", func(r models.AcademicRecord) any { return r.ExpectedGraduation }},
+	{"id", "academic_records.id", func(r models.AcademicRecord) any { return r.ID }},
+}
+
+// legacyRecordSorts traduz os valores de sort anteriores à ordenação por
+// chaves, ainda gravados em visões salvas e links.
+var legacyRecordSorts = map[string]string{
+	"risk":     "-risk_score",
+	"risk_asc": "risk_score",
+}
+
+func recordSort(sort string) string {
+	if legacy, ok := legacyRecordSorts[sort]; ok {
+		return legacy
+	}
+	return sort
+}
+
+// Records retorna o relatório acadêmico, ordenado e paginado conforme
+// Sort e Page.
+func (s *ReportService) Records(f RecordsFilter) ([]models.AcademicRecord, PageInfo, error) {
 	q := s.db.Model(&models.AcademicRecord{}).
 		Joins("JOIN students ON students.id = academic_records.student_id").
 		Joins("JOIN courses ON courses.id = students.course_id").
@@ -45,60 +88,75 @@ func (s *ReportService) Records(f RecordsFilter) ([]models.AcademicRecord, int64
 	if f.SemesterID != "" {
 		q = q.Where("academic_records.semester_id = ?", f.SemesterID)
 	}
-	if f.CriticalOnly {
-		q = criticalScope(q)
+	byPending := false
+	if f.CriticalOnly || f.NearGraduationOnly {
+		triage, err := loadTriage(s.db)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		if f.CriticalOnly {
+			q = triage.criticalScope(q)
+		}
+		if f.NearGraduationOnly {
+			q = triage.nearGraduationScope(q)
+			byPending = true
+		}
 	}
 	if f.MaxPending != nil {
-		q = nearGraduationScope(q, *f.MaxPending).
-			Order("academic_records.pending_obligatory ASC")
+		q = maxPendingScope(q, *f.MaxPending)
+		byPending = true
 	}
-	if f.Registration != "" {
-		q = q.Where("students.registration LIKE ?", "%"+f.Registration+"%")
+	fallback := "id"
+	if byPending {
+		fallback = "pending_obligatory"
 	}
-	if f.StudentName != "" {
-		q = q.Where("students.name LIKE ?", "%"+f.StudentName+"%")
-	}
-	if f.CourseName != "" {
-		q = q.Where("courses.name LIKE ?", "%"+f.CourseName+"%")
+	terms, err := parseSort(recordSort(f.Sort), fallback, recordSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
+	q = matchContains(q, "students.registration", f.Registration)
+	q = matchFolded(q, "students.search_name", f.StudentName)
+	q = matchFolded(q, "courses.search_name", f.CourseName)
 	if f.Status != "" {
 		q = q.Where("academic_records.status = ?", f.Status)
 	}
-
-	total := int64(-1)
-	if f.Limit > 0 {
-		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
-			return nil, 0, err
-		}
-		q = q.Limit(f.Limit).Offset(f.Offset)
+	if f.ExceedsMaxDuration {
+		q = q.Where("academic_records.exceeds_max_duration = ?", true)
 	}
-
-	var records []models.AcademicRecord
-	if err := q.Find(&records).Error; err != nil {
-		return nil, 0, err
+	if f.AdvisorID != 0 {
+		q = advisedBy(q, f.AdvisorID)
 	}
-	return records, total, nil
+	q = f.Scope.apply(q, "students.course_id")
+	return paginate(q, terms, f.Page)
 }
 
 type CoursesFilter struct {
-	Code *int
-	Name string
+	Code  *int
+	Name  string
+	Scope CourseScope
+	Sort  string // chaves de courseSortKeys; padrão: nome
+	Page  Page
+}
+
+var courseSortKeys = []sortKey[models.Course]{
+	{"code", "code", func(c models.Course) any { return c.Code }},
+	{"name", "name", func(c models.Course) any { return c.Name }},
+	{"max_duration_semesters", "max_duration_semesters", func(c models.Course) any { return c.MaxDurationSemesters }},
+	{"id", "id", func(c models.Course) any { return c.ID }},
 }
 
-func (s *ReportService) Courses(f CoursesFilter) ([]models.Course, error) {
+func (s *ReportService) Courses(f CoursesFilter) ([]models.Course, PageInfo, error) {
+	terms, err := parseSort(f.Sort, "name", courseSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
 	q := s.db.Model(&models.Course{})
 	if f.Code != nil {
 		q = q.Where("code = ?", *f.Code)
 	}
-	if f.Name != "" {
-		q = q.Where("name LIKE ?", "%"+f.Name+"%")
-	}
-
-	var courses []models.Course
-	if err := q.Find(&courses).Error; err != nil {
-		return nil, err
-	}
-	return courses, nil
+	q = matchFolded(q, "search_name", f.Name)
+	q = f.Scope.apply(q, "id")
+	return paginate(q, terms, f.Page)
 }
 
 type StudentsFilter struct {
@@ -107,44 +165,44 @@ type StudentsFilter struct {
 	Name         string
 	EntryYear    *int
 	QuotaType    string
-	Limit        int
-	Offset       int
+	Scope        CourseScope
+	Sort         string // chaves de studentSortKeys; padrão: nome
+	Page         Page
+}
+
+var studentSortKeys = []sortKey[models.Student]{
+	{"registration", "students.registration", func(s models.Student) any { return s.Registration }},
+	{"name", "students.name", func(s models.Student) any { return s.Name }},
+	{"course", "courses.name", func(s models.Student) any { return s.Course.Name }},
+	{"entry_year", "students.entry_year", func(s models.Student) any { return s.EntryYear }},
+	{"quota_type", "students.quota_type", func(s models.Student) any { return s.QuotaType }},
+	{"id", "students.id", func(s models.Student) any { return s.ID }},
 }
 
 // Students lista a base de alunos; com SemesterID, restringe aos que têm
 // registro acadêmico no semestre. O índice único (student, semester)
 // garante no máximo uma linha por aluno no join — não há duplicação.
-func (s *ReportService) Students(f StudentsFilter) ([]models.Student, int64, error) {
-	q := s.db.Model(&models.Student{}).Preload("Course")
+func (s *ReportService) Students(f StudentsFilter) ([]models.Student, PageInfo, error) {
+	terms, err := parseSort(f.Sort, "name", studentSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	q := s.db.Model(&models.Student{}).
+		Joins("JOIN courses ON courses.id = students.course_id").
+		Preload("Course")
 
 	if f.SemesterID != "" {
 		q = q.Joins("JOIN academic_records ON academic_records.student_id = students.id AND academic_records.deleted_at IS NULL").
 			Where("academic_records.semester_id = ?", f.SemesterID)
 	}
-	if f.Registration != "" {
-		q = q.Where("students.registration LIKE ?", "%"+f.Registration+"%")
-	}
-	if f.Name != "" {
-		q = q.Where("students.name LIKE ?", "%"+f.Name+"%")
-	}
+	q = matchContains(q, "students.registration", f.Registration)
+	q = matchFolded(q, "students.search_name", f.Name)
 	if f.EntryYear != nil {
 		q = q.Where("students.entry_year = ?", *f.EntryYear)
 	}
 	if f.QuotaType != "" {
 		q = q.Where("students.quota_type = ?", f.QuotaType)
 	}
-
-	total := int64(-1)
-	if f.Limit > 0 {
-		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
-			return nil, 0, err
-		}
-		q = q.Limit(f.Limit).Offset(f.Offset)
-	}
-
-	var students []models.Student
-	if err := q.Find(&students).Error; err != nil {
-		return nil, 0, err
-	}
-	return students, total, nil
+	q = f.Scope.apply(q, "students.course_id")
+	return paginate(q, terms, f.Page)
 }
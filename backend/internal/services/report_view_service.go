@@ -0,0 +1,396 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Relatórios que podem ser salvos como visão.
+const (
+	ReportViewRecords  = "records"
+	ReportViewStudents = "students"
+)
+
+// ReportViewFilters são os filtros de uma visão salva, com os mesmos nomes
+// dos parâmetros de GET /reports/records e /reports/students. Mine
+// restringe aos alunos atribuídos ao dono da visão.
+type ReportViewFilters struct {
+	SemesterID         string `json:"semester_id,omitempty"`
+	Registration       string `json:"registration,omitempty"`
+	StudentName        string `json:"student_name,omitempty"`
+	CourseName         string `json:"course_name,omitempty"`
+	Status             string `json:"status,omitempty"`
+	Mode               string `json:"mode,omitempty"`
+	MaxPending         *int   `json:"max_pending,omitempty"`
+	ExceedsMaxDuration bool   `json:"exceeds_max_duration,omitempty"`
+	Mine               bool   `json:"mine,omitempty"`
+	Name               string `json:"name,omitempty"`
+	EntryYear          *int   `json:"entry_year,omitempty"`
+	QuotaType          string `json:"quota_type,omitempty"`
+}
+
+// ViewColumn é uma coluna selecionável de um relatório.
+type ViewColumn struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+func (c ViewColumn) key() string { return c.Key }
+
+// pickColumns devolve as colunas na ordem escolhida (keys nil: todas, na
+// ordem do catálogo).
+func pickColumns[C interface{ key() string }](all []C, keys []string) []C {
+	if keys == nil {
+		return all
+	}
+	out := make([]C, 0, len(keys))
+	for _, k := range keys {
+		if i := slices.IndexFunc(all, func(c C) bool { return c.key() == k }); i >= 0 {
+			out = append(out, all[i])
+		}
+	}
+	return out
+}
+
+type recordColumn struct {
+	ViewColumn
+	value func(models.AcademicRecord) any
+}
+
+type studentColumn struct {
+	ViewColumn
+	value func(models.Student) any
+}
+
+var recordColumns = []recordColumn{
+	{ViewColumn{"registration", "Matrícula"}, func(r models.AcademicRecord) any { return r.Student.Registration }},
+	{ViewColumn{"name", "Aluno"}, func(r models.AcademicRecord) any { return r.Student.Name }},
+	{ViewColumn{"course", "Curso"}, func(r models.AcademicRecord) any { return r.Student.Course.Name }},
+	{ViewColumn{"semester", "Semestre"}, func(r models.AcademicRecord) any { return r.Semester.Code }},
+	{ViewColumn{"status", "Status"}, func(r models.AcademicRecord) any { return r.Status }},
+	{ViewColumn{"status_detail", "Detalhe"}, func(r models.AcademicRecord) any { return r.StatusDetail }},
+	{ViewColumn{"integralized_pct", "% Concluído"}, func(r models.AcademicRecord) any {
+		if r.TotalHours == 0 {
+			return 0.0
+		}
+		return round2(float64(r.IntegralizedHours) * 100 / float64(r.TotalHours))
+	}},
+	{ViewColumn{"integralized_hours", "CH integralizada"}, func(r models.AcademicRecord) any { return r.IntegralizedHours }},
+	{ViewColumn{"total_hours", "CH total"}, func(r models.AcademicRecord) any { return r.TotalHours }},
+	{ViewColumn{"pending_obligatory", "Obrigatórias pendentes"}, func(r models.AcademicRecord) any { return r.PendingObligatory }},
+	{ViewColumn{"locks", "Trancamentos"}, func(r models.AcademicRecord) any { return r.Locks }},
+	{ViewColumn{"semesters_no_hours", "Semestres sem CH"}, func(r models.AcademicRecord) any { return r.SemestersNoHours }},
+	{ViewColumn{"risk_score", "Risco"}, func(r models.AcademicRecord) any { return r.RiskScore }},
+	{ViewColumn{"expected_graduation", "Formatura prevista"}, func(r models.AcademicRecord) any { return r.ExpectedGraduation }},
+	{ViewColumn{"exceeds_max_duration", "Excede o prazo"}, func(r models.AcademicRecord) any { return r.ExceedsMaxDuration }},
+}
+
+var studentColumns = []studentColumn{
+	{ViewColumn{"registration", "Matrícula"}, func(s models.Student) any { return s.Registration }},
+	{ViewColumn{"name", "Aluno"}, func(s models.Student) any { return s.Name }},
+	{ViewColumn{"course", "Curso"}, func(s models.Student) any { return s.Course.Name }},
+	{ViewColumn{"entry_year", "Ano de ingresso"}, func(s models.Student) any { return s.EntryYear }},
+	{ViewColumn{"entry_period", "Período de ingresso"}, func(s models.Student) any { return s.EntryPeriod }},
+	{ViewColumn{"quota_type", "Cota"}, func(s models.Student) any { return s.QuotaType }},
+	{ViewColumn{"email", "E-mail"}, func(s models.Student) any { return s.Email }},
+}
+
+// ReportViewColumns é o catálogo de colunas de cada relatório.
+func ReportViewColumns() map[string][]ViewColumn {
+	out := map[string][]ViewColumn{}
+	for _, c := range recordColumns {
+		out[ReportViewRecords] = append(out[ReportViewRecords], c.ViewColumn)
+	}
+	for _, c := range studentColumns {
+		out[ReportViewStudents] = append(out[ReportViewStudents], c.ViewColumn)
+	}
+	return out
+}
+
+// ReportViewService mantém as visões salvas dos relatórios e as executa.
+type ReportViewService struct {
+	db      *gorm.DB
+	reports *ReportService
+}
+
+func NewReportViewService(db *gorm.DB) *ReportViewService {
+	return &ReportViewService{db: db, reports: NewReportService(db)}
+}
+
+type ReportViewInput struct {
+	Name    string
+	Report  string
+	Filters ReportViewFilters
+	Columns []string
+	Sort    string
+	Shared  bool
+}
+
+// List devolve as visões do usuário e as compartilhadas pelos demais, em
+// ordem de nome; com report, apenas as daquele relatório.
+func (s *ReportViewService) List(userID uint, report string) ([]models.ReportView, error) {
+	q := s.db.Preload("User").Where("user_id = ? OR shared = ?", userID, true)
+	if report != "" {
+		q = q.Where("report = ?", report)
+	}
+	var views []models.ReportView
+	if err := q.Order("name, id").Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// Get devolve a visão se o usuário a enxerga (dona ou compartilhada); a
+// chave de API (UserID zero) só enxerga as compartilhadas.
+func (s *ReportViewService) Get(id uint, actor Actor) (*models.ReportView, error) {
+	var view models.ReportView
+	if err := s.db.Preload("User").First(&view, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Visão não encontrada")
+		}
+		return nil, err
+	}
+	if !view.Shared && (actor.UserID == 0 || view.UserID != actor.UserID) {
+		return nil, NotFound("Visão não encontrada")
+	}
+	return &view, nil
+}
+
+func (s *ReportViewService) Create(in ReportViewInput, actor Actor) (*models.ReportView, error) {
+	view := models.ReportView{UserID: actor.UserID}
+	if err := s.save(&view, in); err != nil {
+		return nil, err
+	}
+	return s.Get(view.ID, actor)
+}
+
+// Update substitui a visão; só o dono edita.
+func (s *ReportViewService) Update(id uint, in ReportViewInput, actor Actor) (*models.ReportView, error) {
+	view, err := s.owned(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(view, in); err != nil {
+		return nil, err
+	}
+	return s.Get(view.ID, actor)
+}
+
+// Delete remove a visão; só o dono remove.
+func (s *ReportViewService) Delete(id uint, actor Actor) error {
+	view, err := s.owned(id, actor)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(view).Error
+}
+
+func (s *ReportViewService) owned(id uint, actor Actor) (*models.ReportView, error) {
+	view, err := s.Get(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if view.UserID != actor.UserID {
+		return nil, Forbidden("Apenas o dono pode alterar a visão")
+	}
+	return view, nil
+}
+
+// save valida a entrada e grava a visão; o nome é único entre as visões
+// do mesmo dono.
+func (s *ReportViewService) save(view *models.ReportView, in ReportViewInput) error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return Invalid("name é obrigatório (até 100 caracteres)")
+	}
+	if err := validateViewQuery(in.Report, in.Sort, in.Filters, in.Columns); err != nil {
+		return err
+	}
+	filters, err := json.Marshal(in.Filters)
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	if err := s.db.Model(&models.ReportView{}).
+		Where("user_id = ? AND name = ? AND id <> ?", view.UserID, name, view.ID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return Conflict("Você já tem uma visão com este nome")
+	}
+
+	view.Name = name
+	view.Report = in.Report
+	view.Filters = string(filters)
+	view.Columns = strings.Join(in.Columns, ",")
+	view.Sort = in.Sort
+	view.Shared = in.Shared
+	return s.db.Omit("User").Save(view).Error
+}
+
+// validateViewQuery confere relatório, ordenação, filtros e colunas de uma
+// visão (ou de uma entrega agendada).
+func validateViewQuery(report, sort string, filters ReportViewFilters, columns []string) error {
+	var err error
+	switch report {
+	case ReportViewRecords:
+		_, err = parseSort(recordSort(sort), "id", recordSortKeys)
+	case ReportViewStudents:
+		_, err = parseSort(sort, "name", studentSortKeys)
+	default:
+		return Invalid("report inválido: use records ou students")
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateViewFilters(filters); err != nil {
+		return err
+	}
+	known := ReportViewColumns()[report]
+	for i, key := range columns {
+		if !slices.ContainsFunc(known, func(c ViewColumn) bool { return c.Key == key }) {
+			return Invalid("coluna desconhecida: " + key)
+		}
+		if slices.Contains(columns[:i], key) {
+			return Invalid("coluna repetida: " + key)
+		}
+	}
+	return nil
+}
+
+func validateViewFilters(f ReportViewFilters) error {
+	if f.Mode != "" && f.Mode != "critical" && f.Mode != "near_graduation" {
+		return Invalid("mode inválido: use critical ou near_graduation")
+	}
+	if f.MaxPending != nil && *f.MaxPending < 0 {
+		return Invalid("max_pending não pode ser negativo")
+	}
+	return nil
+}
+
+// ViewFilters decodifica os filtros gravados na visão.
+func ViewFilters(view models.ReportView) (ReportViewFilters, error) {
+	var f ReportViewFilters
+	if view.Filters == "" {
+		return f, nil
+	}
+	err := json.Unmarshal([]byte(view.Filters), &f)
+	return f, err
+}
+
+// ViewColumnKeys devolve as colunas escolhidas na visão (nil: todas).
+func ViewColumnKeys(view models.ReportView) []string {
+	if view.Columns == "" {
+		return nil
+	}
+	return strings.Split(view.Columns, ",")
+}
+
+// ReportViewRun são os ajustes de uma execução: SemesterID, se informado,
+// substitui o semestre salvo (a mesma visão serve a cada semestre).
+type ReportViewRun struct {
+	SemesterID string
+	Scope      CourseScope
+	Page       Page
+}
+
+// Run executa a visão com os filtros e a ordenação salvos, no escopo de
+// cursos de quem a executa, e devolve a tabela com as colunas escolhidas.
+func (s *ReportViewService) Run(id uint, actor Actor, run ReportViewRun) (*models.ReportView, Table, PageInfo, error) {
+	view, err := s.Get(id, actor)
+	if err != nil {
+		return nil, Table{}, PageInfo{}, err
+	}
+	table, info, err := s.execute(*view, run)
+	return view, table, info, err
+}
+
+// execute monta a tabela da visão; também usado pela entrega agendada.
+func (s *ReportViewService) execute(view models.ReportView, run ReportViewRun) (Table, PageInfo, error) {
+	f, err := ViewFilters(view)
+	if err != nil {
+		return Table{}, PageInfo{}, err
+	}
+	semesterID := f.SemesterID
+	if run.SemesterID != "" {
+		semesterID = run.SemesterID
+	}
+	keys := ViewColumnKeys(view)
+	table := Table{Sheet: view.Name}
+
+	switch view.Report {
+	case ReportViewRecords:
+		var advisor uint
+		if f.Mine {
+			advisor = view.UserID
+		}
+		records, info, err := s.reports.Records(RecordsFilter{
+			SemesterID:         semesterID,
+			Registration:       f.Registration,
+			StudentName:        f.StudentName,
+			CourseName:         f.CourseName,
+			Status:             f.Status,
+			CriticalOnly:       f.Mode == "critical",
+			NearGraduationOnly: f.Mode == "near_graduation",
+			MaxPending:         f.MaxPending,
+			ExceedsMaxDuration: f.ExceedsMaxDuration,
+			AdvisorID:          advisor,
+			Sort:               view.Sort,
+			Scope:              run.Scope,
+			Page:               run.Page,
+		})
+		if err != nil {
+			return Table{}, PageInfo{}, err
+		}
+		cols := pickColumns(recordColumns, keys)
+		for _, c := range cols {
+			table.Keys = append(table.Keys, c.Key)
+			table.Columns = append(table.Columns, c.Label)
+		}
+		for _, r := range records {
+			row := make([]any, len(cols))
+			for i, c := range cols {
+				row[i] = c.value(r)
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		return table, info, nil
+
+	case ReportViewStudents:
+		students, info, err := s.reports.Students(StudentsFilter{
+			SemesterID:   semesterID,
+			Registration: f.Registration,
+			Name:         f.Name,
+			EntryYear:    f.EntryYear,
+			QuotaType:    f.QuotaType,
+			Scope:        run.Scope,
+			Sort:         view.Sort,
+			Page:         run.Page,
+		})
+		if err != nil {
+			return Table{}, PageInfo{}, err
+		}
+		cols := pickColumns(studentColumns, keys)
+		for _, c := range cols {
+			table.Keys = append(table.Keys, c.Key)
+			table.Columns = append(table.Columns, c.Label)
+		}
+		for _, st := range students {
+			row := make([]any, len(cols))
+			for i, c := range cols {
+				row[i] = c.value(st)
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		return table, info, nil
+	}
+	return Table{}, PageInfo{}, Invalid("relatório desconhecido: " + view.Report)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestReportViewsSharingAndRun(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, "secret")
+	views := NewReportViewService(db)
+	ana, bia := seedAdvisor(t, auth, "ana@ufes.br"), seedAdvisor(t, auth, "bia@ufes.br")
+	asAna, asBia := Actor{UserID: ana.ID}, Actor{UserID: bia.ID}
+
+	seedStudentWithStatus(t, db, "1", "2024/1", models.StatusPAE)
+	seedStudentWithStatus(t, db, "2", "2024/1", models.StatusRegular)
+	seedStudentWithStatus(t, db, "3", "2024/2", models.StatusPAE)
+	var sem models.Semester
+	db.Where("code = ?", "2024/1").First(&sem)
+	semesterID := strconv.FormatUint(uint64(sem.ID), 10)
+
+	view, err := views.Create(ReportViewInput{
+		Name:    "PAE da semana",
+		Report:  ReportViewRecords,
+		Filters: ReportViewFilters{SemesterID: semesterID, Status: models.StatusPAE},
+		Columns: []string{"status", "registration"},
+		Sort:    "risk",
+	}, asAna)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Privada: Bia e a chave de API não a enxergam.
+	if _, err := views.Get(view.ID, asBia); !errors.Is(err, ErrNotFound) {
+		t.Errorf("visão privada vista por outro usuário: %v", err)
+	}
+	if _, _, _, err := views.Run(view.ID, Actor{}, ReportViewRun{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("visão privada executada por chave de API: %v", err)
+	}
+
+	_, table, _, err := views.Run(view.ID, asAna, ReportViewRun{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(table.Keys) != 2 || table.Keys[0] != "status" || len(table.Rows) != 1 || table.Rows[0][1] != "1" {
+		t.Fatalf("execução com as colunas na ordem escolhida: %+v", table)
+	}
+	// O semestre informado na execução substitui o salvo.
+	if _, table, _, _ := views.Run(view.ID, asAna, ReportViewRun{SemesterID: strconv.FormatUint(uint64(sem.ID+1), 10)}); len(table.Rows) != 1 || table.Objects()[0]["registration"] != "3" {
+		t.Errorf("execução em outro semestre: %+v", table)
+	}
+
+	// Compartilhada: Bia vê e executa, mas não edita.
+	in := ReportViewInput{Name: "PAE da semana", Report: ReportViewRecords, Filters: ReportViewFilters{Status: models.StatusPAE}, Shared: true}
+	if _, err := views.Update(view.ID, in, asAna); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if list, _ := views.List(bia.ID, ReportViewRecords); len(list) != 1 || list[0].User == nil || list[0].User.ID != ana.ID {
+		t.Errorf("lista de Bia deveria ter a visão compartilhada: %+v", list)
+	}
+	if _, table, _, err := views.Run(view.ID, Actor{}, ReportViewRun{Scope: AllCourses()}); err != nil || len(table.Rows) != 2 || len(table.Keys) != len(recordColumns) {
+		t.Errorf("visão compartilhada pela chave de API, todas as colunas: %v %+v", err, table)
+	}
+	if _, table, _, err := views.Run(view.ID, asBia, ReportViewRun{Scope: RestrictTo(nil)}); err != nil || len(table.Rows) != 0 {
+		t.Errorf("a execução respeita o escopo de quem executa: %v %+v", err, table.Rows)
+	}
+	if _, err := views.Update(view.ID, in, asBia); !errors.Is(err, ErrForbidden) {
+		t.Errorf("edição por quem não é dono: %v", err)
+	}
+	if err := views.Delete(view.ID, asBia); !errors.Is(err, ErrForbidden) {
+		t.Errorf("remoção por quem não é dono: %v", err)
+	}
+
+	if _, err := views.Create(ReportViewInput{Name: "PAE da semana", Report: ReportViewRecords}, asAna); !errors.Is(err, ErrConflict) {
+		t.Errorf("nome repetido do mesmo dono: %v", err)
+	}
+	if _, err := views.Create(ReportViewInput{Name: "PAE da semana", Report: ReportViewStudents}, asBia); err != nil {
+		t.Errorf("o mesmo nome para outro dono: %v", err)
+	}
+	for _, bad := range []ReportViewInput{
+		{Name: "x", Report: "grades"},
+		{Name: "x", Report: ReportViewStudents, Sort: "risk"},
+		{Name: "x", Report: ReportViewRecords, Columns: []string{"quota_type"}},
+		{Name: "x", Report: ReportViewRecords, Filters: ReportViewFilters{Mode: "all"}},
+		{Name: " ", Report: ReportViewRecords},
+	} {
+		if _, err := views.Create(bad, asAna); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v deveria ser inválida; obtive %v", bad, err)
+		}
+	}
+
+	if err := views.Delete(view.ID, asAna); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := views.Get(view.ID, asAna); !errors.Is(err, ErrNotFound) {
+		t.Errorf("visão removida: %v", err)
+	}
+}
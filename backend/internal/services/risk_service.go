@@ -0,0 +1,475 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adamanagement/backend/internal/models"
+)
+
+// AuditRiskWeights registra a troca dos pesos do risco de evasão.
+const AuditRiskWeights = "risk.weights.updated"
+
+const settingRiskWeights = "risk.weights"
+
+// Fatores do risco de evasão.
+const (
+	RiskLocks             = "locks"
+	RiskSemestersNoHours  = "semesters_no_hours"
+	RiskIntegralization   = "integralization"
+	RiskPendingObligatory = "pending_obligatory"
+	RiskTimeSinceEntry    = "time_since_entry"
+	RiskStatusTrend       = "status_trend"
+)
+
+// Valores a partir dos quais um fator conta por inteiro: quatro
+// trancamentos, quatro semestres sem carga horária, 30 obrigatórias
+// pendentes ou 16 semestres desde o ingresso.
+const (
+	riskLocksCap     = 4
+	riskNoHoursCap   = 4
+	riskPendingCap   = 30
+	riskSemestersCap = 16
+)
+
+// RiskWeights são os pesos relativos de cada fator; o escore é a média
+// ponderada dos fatores normalizados (0 a 1), em escala de 0 a 100.
+type RiskWeights struct {
+	Locks             float64 `json:"locks"`
+	SemestersNoHours  float64 `json:"semesters_no_hours"`
+	Integralization   float64 `json:"integralization"`
+	PendingObligatory float64 `json:"pending_obligatory"`
+	TimeSinceEntry    float64 `json:"time_since_entry"`
+	StatusTrend       float64 `json:"status_trend"`
+}
+
+// DefaultRiskWeights vale enquanto o administrador não grava outros pesos.
+var DefaultRiskWeights = RiskWeights{
+	Locks:             20,
+	SemestersNoHours:  20,
+	Integralization:   15,
+	PendingObligatory: 10,
+	TimeSinceEntry:    15,
+	StatusTrend:       20,
+}
+
+func (w RiskWeights) total() float64 {
+	return w.Locks + w.SemestersNoHours + w.Integralization + w.PendingObligatory + w.TimeSinceEntry + w.StatusTrend
+}
+
+// RiskFactor explica a contribuição de um fator: Value é a medida bruta,
+// Normalized a fração considerada (0 a 1) e Points os pontos somados ao
+// escore.
+type RiskFactor struct {
+	Factor     string  `json:"factor"`
+	Label      string  `json:"label"`
+	Value      float64 `json:"value"`
+	Normalized float64 `json:"normalized"`
+	Weight     float64 `json:"weight"`
+	Points     float64 `json:"points"`
+}
+
+// riskInput são os dados de um registro usados no cálculo. Elapsed é o
+// número de semestres desde o ingresso (-1 se desconhecido) e
+// PrevStatus o enquadramento no semestre anterior do aluno ("" se não há).
+type riskInput struct {
+	Locks             int
+	SemestersNoHours  int
+	IntegralizedHours int
+	TotalHours        int
+	PendingObligatory int
+	Elapsed           int
+	Status            string
+	PrevStatus        string
+}
+
+// statusSeverity ordena os enquadramentos do menos ao mais grave.
+func statusSeverity(status string) float64 {
+	switch status {
+	case models.StatusPAE:
+		return 0.5
+	case models.StatusPIC:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// computeRisk calcula o escore e a contribuição de cada fator, do maior
+// para o menor.
+func computeRisk(in riskInput, w RiskWeights) (float64, []RiskFactor) {
+	capped := func(v, limit float64) float64 { return math.Min(math.Max(v, 0)/limit, 1) }
+
+	gap := 0.0
+	if in.TotalHours > 0 {
+		gap = 1 - float64(in.IntegralizedHours)/float64(in.TotalHours)
+	}
+	elapsed := 0.0
+	if in.Elapsed > 0 {
+		elapsed = float64(in.Elapsed)
+	}
+
+	// Tendência: piora em relação ao semestre anterior conta por inteiro;
+	// permanecer em PAE/PIC conta a metade; sem histórico, metade da
+	// gravidade atual.
+	now, trend := statusSeverity(in.Status), 0.0
+	switch {
+	case in.PrevStatus == "":
+		trend = now / 2
+	case now > statusSeverity(in.PrevStatus):
+		trend = 1
+	case now > 0 && now == statusSeverity(in.PrevStatus):
+		trend = 0.5
+	}
+
+	factors := []RiskFactor{
+		{Factor: RiskLocks, Label: "Trancamentos", Value: float64(in.Locks), Normalized: capped(float64(in.Locks), riskLocksCap), Weight: w.Locks},
+		{Factor: RiskSemestersNoHours, Label: "Semestres sem carga horária", Value: float64(in.SemestersNoHours), Normalized: capped(float64(in.SemestersNoHours), riskNoHoursCap), Weight: w.SemestersNoHours},
+		{Factor: RiskIntegralization, Label: "Carga horária não integralizada", Value: round2(gap * 100), Normalized: math.Min(math.Max(gap, 0), 1), Weight: w.Integralization},
+		{Factor: RiskPendingObligatory, Label: "Obrigatórias pendentes", Value: float64(in.PendingObligatory), Normalized: capped(float64(in.PendingObligatory), riskPendingCap), Weight: w.PendingObligatory},
+		{Factor: RiskTimeSinceEntry, Label: "Semestres desde o ingresso", Value: elapsed, Normalized: capped(elapsed, riskSemestersCap), Weight: w.TimeSinceEntry},
+		{Factor: RiskStatusTrend, Label: "Tendência do enquadramento", Value: round2(now - statusSeverity(in.PrevStatus)), Normalized: trend, Weight: w.StatusTrend},
+	}
+
+	total := w.total()
+	score := 0.0
+	for i := range factors {
+		f := &factors[i]
+		if total > 0 {
+			f.Points = round2(100 * f.Weight * f.Normalized / total)
+		}
+		f.Normalized = round2(f.Normalized)
+		score += f.Points
+	}
+	sort.SliceStable(factors, func(i, j int) bool { return factors[i].Points > factors[j].Points })
+	return round2(math.Min(score, 100)), factors
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// semestersBetween conta os semestres entre o ingresso (ano e período) e
+// o código do semestre do registro ("2025/1"); -1 se algum dado falta.
+func semestersBetween(entryYear int, entryPeriod, code string) int {
+	year, period, ok := strings.Cut(code, "/")
+	y, err1 := strconv.Atoi(year)
+	p, err2 := strconv.Atoi(period)
+	if !ok || err1 != nil || err2 != nil || entryYear == 0 {
+		return -1
+	}
+	ep, err := strconv.Atoi(strings.TrimSpace(entryPeriod))
+	if err != nil {
+		ep = 1
+	}
+	return (y-entryYear)*2 + (p - ep)
+}
+
+// RiskService calcula o risco de evasão dos registros acadêmicos e mantém
+// os pesos dos fatores.
+type RiskService struct {
+	db *gorm.DB
+}
+
+func NewRiskService(db *gorm.DB) *RiskService { return &RiskService{db: db} }
+
+// Weights devolve os pesos vigentes (os padrão, se nunca gravados).
+func (s *RiskService) Weights() (RiskWeights, error) {
+	return riskWeights(s.db)
+}
+
+// UpdateWeights valida e grava os pesos e recalcula o escore de todos os
+// registros na mesma transação.
+func (s *RiskService) UpdateWeights(w RiskWeights, actor Actor) (RiskWeights, error) {
+	for _, v := range []float64{w.Locks, w.SemestersNoHours, w.Integralization, w.PendingObligatory, w.TimeSinceEntry, w.StatusTrend} {
+		if v < 0 || v > 100 {
+			return RiskWeights{}, Invalid("os pesos devem estar entre 0 e 100")
+		}
+	}
+	if w.total() == 0 {
+		return RiskWeights{}, Invalid("ao menos um peso deve ser maior que zero")
+	}
+	raw, err := json.Marshal(w)
+	if err != nil {
+		return RiskWeights{}, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := setSetting(tx, settingRiskWeights, string(raw)); err != nil {
+			return err
+		}
+		if err := recomputeRecords(tx, nil); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditRiskWeights, settingRiskWeights, string(raw))
+	})
+	if err != nil {
+		return RiskWeights{}, err
+	}
+	return w, nil
+}
+
+// RiskEntry é um aluno da lista dos maiores riscos, com os fatores.
+type RiskEntry struct {
+	RecordID     uint         `json:"record_id"`
+	StudentID    uint         `json:"student_id"`
+	Registration string       `json:"registration"`
+	Name         string       `json:"name"`
+	Course       string       `json:"course"`
+	Status       string       `json:"status"`
+	Score        float64      `json:"score"`
+	Factors      []RiskFactor `json:"factors"`
+}
+
+// Top devolve os limit registros de maior risco no semestre, para os
+// cursos do escopo, com a contribuição de cada fator.
+func (s *RiskService) Top(semesterID string, limit int, scope CourseScope) ([]RiskEntry, error) {
+	if semesterID == "" {
+		return nil, Invalid("semester_id é obrigatório")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	weights, err := riskWeights(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	q := s.db.Model(&models.AcademicRecord{}).
+		Joins("JOIN students ON students.id = academic_records.student_id").
+		Preload("Student.Course").
+		Preload("Semester").
+		Where("academic_records.semester_id = ?", semesterID)
+	var records []models.AcademicRecord
+	if err := scope.apply(q, "students.course_id").
+		Order("academic_records.risk_score DESC, academic_records.id").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	prevStatus, err := s.previousStatuses(records)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]RiskEntry, 0, len(records))
+	for _, r := range records {
+		prev := prevStatus[r.StudentID]
+		score, factors := computeRisk(riskInputOf(r, r.Student.EntryYear, r.Student.EntryPeriod, r.Semester.Code, prev), weights)
+		entries = append(entries, RiskEntry{
+			RecordID:     r.ID,
+			StudentID:    r.StudentID,
+			Registration: r.Student.Registration,
+			Name:         r.Student.Name,
+			Course:       r.Student.Course.Name,
+			Status:       r.Status,
+			Score:        score,
+			Factors:      factors,
+		})
+	}
+	return entries, nil
+}
+
+// previousStatuses devolve, por aluno, a situação no semestre anterior ao
+// dos registros (todos do mesmo semestre). Os semestres são ordenados
+// pelo calendário (semesterIndex), não pelo texto do código; sem código
+// válido não há semestre anterior.
+func (s *RiskService) previousStatuses(records []models.AcademicRecord) (map[uint]string, error) {
+	prev := map[uint]string{}
+	if len(records) == 0 {
+		return prev, nil
+	}
+	current, ok := semesterIndex(records[0].Semester.Code)
+	if !ok {
+		return prev, nil
+	}
+
+	var semesters []models.Semester
+	if err := s.db.Select("id", "code").Find(&semesters).Error; err != nil {
+		return nil, err
+	}
+	earlier := map[uint]int{}
+	semesterIDs := []uint{}
+	for _, sem := range semesters {
+		if i, ok := semesterIndex(sem.Code); ok && i < current {
+			earlier[sem.ID] = i
+			semesterIDs = append(semesterIDs, sem.ID)
+		}
+	}
+	if len(semesterIDs) == 0 {
+		return prev, nil
+	}
+
+	studentIDs := make([]uint, len(records))
+	for i, r := range records {
+		studentIDs[i] = r.StudentID
+	}
+	var rows []struct {
+		StudentID  uint
+		SemesterID uint
+		Status     string
+	}
+	if err := s.db.Model(&models.AcademicRecord{}).
+		Select("student_id, semester_id, status").
+		Where("student_id IN ? AND semester_id IN ?", studentIDs, semesterIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	latest := map[uint]int{}
+	for _, r := range rows {
+		i := earlier[r.SemesterID]
+		if last, seen := latest[r.StudentID]; !seen || i > last {
+			latest[r.StudentID] = i
+			prev[r.StudentID] = r.Status
+		}
+	}
+	return prev, nil
+}
+
+func riskInputOf(r models.AcademicRecord, entryYear int, entryPeriod, code, prev string) riskInput {
+	return riskInput{
+		Locks:             r.Locks,
+		SemestersNoHours:  r.SemestersNoHours,
+		IntegralizedHours: r.IntegralizedHours,
+		TotalHours:        r.TotalHours,
+		PendingObligatory: r.PendingObligatory,
+		Elapsed:           semestersBetween(entryYear, entryPeriod, code),
+		Status:            r.Status,
+		PrevStatus:        prev,
+	}
+}
+
+// riskWeights lê os pesos gravados; ausentes valem os padrão.
+func riskWeights(db *gorm.DB) (RiskWeights, error) {
+	raw, err := getSetting(db, settingRiskWeights)
+	if err != nil || raw == "" {
+		return DefaultRiskWeights, err
+	}
+	var w RiskWeights
+	if err := json.Unmarshal([]byte(raw), &w); err != nil {
+		return DefaultRiskWeights, nil
+	}
+	return w, nil
+}
+
+// recomputeBatch é quantos alunos recomputeRecords carrega por vez e
+// quantos registros grava por comando.
+const recomputeBatch = 500
+
+// recomputeRecords recalcula o escore de risco e a projeção de formatura
+// (RN33) dos registros dos alunos studentIDs (nil: todos), percorrendo o
+// histórico de cada aluno em ordem de semestre. Os alunos são lidos em
+// lotes, e só os registros que mudaram são gravados, também em lote.
+func recomputeRecords(tx *gorm.DB, studentIDs []uint) error {
+	weights, err := riskWeights(tx)
+	if err != nil {
+		return err
+	}
+	if studentIDs != nil {
+		for batch := range slices.Chunk(studentIDs, recomputeBatch) {
+			if err := recomputeStudents(tx, weights, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var after uint
+	for {
+		var batch []uint
+		if err := tx.Model(&models.Student{}).Where("id > ?", after).
+			Order("id").Limit(recomputeBatch).Pluck("id", &batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := recomputeStudents(tx, weights, batch); err != nil {
+			return err
+		}
+		after = batch[len(batch)-1]
+	}
+}
+
+// recomputeStudents é recomputeRecords para um lote de alunos. As
+// alterações vão num INSERT … ON CONFLICT (id) DO UPDATE por lote, que
+// atualiza só as três colunas derivadas — o mesmo comando no PostgreSQL e
+// no SQLite.
+func recomputeStudents(tx *gorm.DB, weights RiskWeights, studentIDs []uint) error {
+	var rows []struct {
+		models.AcademicRecord
+		Code                 string
+		EntryYear            int
+		EntryPeriod          string
+		MaxDurationSemesters int
+	}
+	if err := tx.Model(&models.AcademicRecord{}).
+		Select("academic_records.*, semesters.code, students.entry_year, students.entry_period, courses.max_duration_semesters").
+		Joins("JOIN students ON students.id = academic_records.student_id").
+		Joins("JOIN courses ON courses.id = students.course_id").
+		Joins("JOIN semesters ON semesters.id = academic_records.semester_id").
+		Where("academic_records.student_id IN ?", studentIDs).
+		Order("academic_records.student_id, semesters.code").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	// O histórico de cada aluno segue a ordem do calendário; semestres de
+	// código malformado vão para o fim e não contam como anteriores.
+	order := func(code string) int {
+		if i, ok := semesterIndex(code); ok {
+			return i
+		}
+		return math.MaxInt
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].StudentID != rows[j].StudentID {
+			return rows[i].StudentID < rows[j].StudentID
+		}
+		return order(rows[i].Code) < order(rows[j].Code)
+	})
+
+	var changed []models.AcademicRecord
+	var student uint
+	var prev string
+	var first projectionPoint
+	for _, r := range rows {
+		if r.StudentID != student {
+			student, prev, first = r.StudentID, "", projectionPoint{}
+		}
+		score, _ := computeRisk(riskInputOf(r.AcademicRecord, r.EntryYear, r.EntryPeriod, r.Code, prev), weights)
+		if _, ok := semesterIndex(r.Code); ok {
+			prev = r.Status
+		}
+
+		point := projectionPoint{Code: r.Code, IntegralizedHours: r.IntegralizedHours, TotalHours: r.TotalHours}
+		if first.Code == "" && r.TotalHours > 0 {
+			first = point
+		}
+		from := first
+		if from.Code == "" {
+			from = point
+		}
+		projection := projectGraduation(r.EntryYear, r.EntryPeriod, r.MaxDurationSemesters, from, point)
+
+		if math.Abs(score-r.RiskScore) < 0.005 &&
+			projection.ExpectedSemester == r.ExpectedGraduation && projection.WillExceed == r.ExceedsMaxDuration {
+			continue
+		}
+		record := r.AcademicRecord
+		record.RiskScore = score
+		record.ExpectedGraduation = projection.ExpectedSemester
+		record.ExceedsMaxDuration = projection.WillExceed
+		changed = append(changed, record)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"risk_score", "expected_graduation", "exceeds_max_duration"}),
+	}).Omit(clause.Associations).CreateInBatches(&changed, recomputeBatch).Error
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestComputeRiskBounds(t *testing.T) {
+	if score, _ := computeRisk(riskInput{Elapsed: -1, Status: models.StatusRegular}, DefaultRiskWeights); score != 0 {
+		t.Errorf("registro sem nenhum fator: %v; esperado 0", score)
+	}
+	worst := riskInput{
+		Locks: 9, SemestersNoHours: 9, IntegralizedHours: 0, TotalHours: 3000,
+		PendingObligatory: 50, Elapsed: 20, Status: models.StatusPIC, PrevStatus: models.StatusRegular,
+	}
+	score, factors := computeRisk(worst, DefaultRiskWeights)
+	if score != 100 || len(factors) != 6 {
+		t.Errorf("todos os fatores no máximo: %v (%d fatores); esperado 100", score, len(factors))
+	}
+	if n := semestersBetween(2020, "2", "2024/1"); n != 7 {
+		t.Errorf("semestres desde 2020/2 até 2024/1: %d; esperado 7", n)
+	}
+}
+
+func TestRiskRankingAndWeights(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewRiskService(db)
+	reports := NewReportService(db)
+
+	// Ana piorou de "Em regularidade" para PIC e tem dois trancamentos;
+	// Bruno é calouro regular.
+	ana := seedStudentWithStatus(t, db, "ana", "2024/1", models.StatusRegular)
+	db.Model(ana).Updates(map[string]any{"entry_year": 2020, "entry_period": "1"})
+	var sem models.Semester
+	db.FirstOrCreate(&sem, models.Semester{Code: "2024/2"})
+	db.Create(&models.AcademicRecord{StudentID: ana.ID, SemesterID: sem.ID, Status: models.StatusPIC, Locks: 2})
+	bruno := models.Student{Registration: "bruno", Name: "Bruno", EntryYear: 2024, EntryPeriod: "2", CourseID: ana.CourseID}
+	db.Create(&bruno)
+	db.Create(&models.AcademicRecord{StudentID: bruno.ID, SemesterID: sem.ID, Status: models.StatusRegular})
+	// Registros posteriores ou de semestre com código malformado não
+	// contam como a situação anterior de 2024/2.
+	for _, code := range []string{"2025/1", "2024/1b"} {
+		other := models.Semester{Code: code}
+		db.Create(&other)
+		db.Create(&models.AcademicRecord{StudentID: ana.ID, SemesterID: other.ID, Status: models.StatusPIC})
+	}
+
+	if err := recomputeRecords(db, nil); err != nil {
+		t.Fatalf("recomputeRecords: %v", err)
+	}
+	semesterID := strconv.FormatUint(uint64(sem.ID), 10)
+
+	top, err := svc.Top(semesterID, 5, AllCourses())
+	if err != nil {
+		t.Fatalf("Top: %v", err)
+	}
+	if len(top) != 2 || top[0].Registration != "ana" || top[0].Score <= top[1].Score {
+		t.Fatalf("ranking: %+v", top)
+	}
+	// Tendência (20 pontos) > trancamentos (10) > tempo desde o ingresso.
+	if f := top[0].Factors; f[0].Factor != RiskStatusTrend || f[0].Points != 20 || f[1].Factor != RiskLocks || f[1].Points != 10 {
+		t.Errorf("fatores explicados: %+v", f)
+	}
+
+	records, _, err := reports.Records(RecordsFilter{SemesterID: semesterID, Sort: "risk"})
+	if err != nil || len(records) != 2 || records[0].StudentID != ana.ID || records[0].RiskScore != top[0].Score {
+		t.Fatalf("relatório ordenado por risco: %v %+v", err, records)
+	}
+	if _, _, err := reports.Records(RecordsFilter{Sort: "password"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("ordenação desconhecida deveria ser inválida; obtive %v", err)
+	}
+
+	// Só trancamentos pesam: 2 de 4 valem 50.
+	if _, err := svc.UpdateWeights(RiskWeights{Locks: 1}, Actor{UserID: 1}); err != nil {
+		t.Fatalf("UpdateWeights: %v", err)
+	}
+	var record models.AcademicRecord
+	db.Where("student_id = ? AND semester_id = ?", ana.ID, sem.ID).First(&record)
+	if record.RiskScore != 50 || record.Locks != 2 || record.Status != models.StatusPIC {
+		t.Errorf("escore recalculado com os novos pesos: %+v; esperado 50 sem alterar o registro", record)
+	}
+
+	// O recálculo restrito a um lote de alunos não toca nos demais.
+	db.Model(&record).UpdateColumn("locks", 4)
+	if err := recomputeRecords(db, []uint{bruno.ID}); err != nil {
+		t.Fatalf("recomputeRecords(bruno): %v", err)
+	}
+	db.First(&record, record.ID)
+	if record.RiskScore != 50 {
+		t.Errorf("registro fora do lote recalculado: %v", record.RiskScore)
+	}
+	if err := recomputeRecords(db, []uint{ana.ID}); err != nil {
+		t.Fatalf("recomputeRecords(ana): %v", err)
+	}
+	db.First(&record, record.ID)
+	if record.RiskScore != 100 {
+		t.Errorf("registro do lote: %v; esperado 100", record.RiskScore)
+	}
+	if w, _ := svc.Weights(); w != (RiskWeights{Locks: 1}) {
+		t.Errorf("pesos gravados: %+v", w)
+	}
+
+	if _, err := svc.UpdateWeights(RiskWeights{}, Actor{UserID: 1}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("pesos todos zerados deveriam ser inválidos; obtive %v", err)
+	}
+	if _, err := svc.UpdateWeights(RiskWeights{Locks: -1, StatusTrend: 1}, Actor{UserID: 1}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("peso negativo deveria ser inválido; obtive %v", err)
+	}
+}
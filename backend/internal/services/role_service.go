@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Ações de auditoria da gestão de papéis.
+const (
+	AuditRoleCreated = "role.created"
+	AuditRoleUpdated = "role.updated"
+	AuditRoleDeleted = "role.deleted"
+)
+
+// defaultRoles são os papéis semeados na inicialização. Papéis já
+// existentes não são alterados, exceto o admin, que é sincronizado com o
+// catálogo completo a cada subida.
+var defaultRoles = []struct {
+	name, description string
+	permissions       []string
+}{
+	{models.RoleAdmin, "Administrador do sistema", models.Permissions},
+	{models.RoleUser, "Coordenação de curso", []string{
+		models.PermReportsRead, models.PermActionsWrite, models.PermPlansManage,
+		models.PermRoundsManage, models.PermAdvisorsManage, models.PermStudentsManage,
+		models.PermDisciplinesManage,
+	}},
+	{models.RoleViewer, "Consulta somente leitura (ex.: diretoria de centro)", []string{
+		models.PermReportsRead, models.PermCoursesAll,
+	}},
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,39}$`)
+
+// permissionCacheTTL é por quanto tempo as permissões de um papel ficam em
+// memória. Edições feitas por esta instância limpam o cache na hora; as
+// feitas por outra instância valem aqui em até um minuto.
+const permissionCacheTTL = time.Minute
+
+type cachedPermissions struct {
+	perms   []string
+	expires time.Time
+}
+
+// RoleService mantém os papéis configuráveis da coordenação e responde às
+// checagens de permissão dos middlewares, com as permissões de cada papel
+// em cache para não consultar o banco a cada requisição.
+type RoleService struct {
+	db *gorm.DB
+
+	mu    sync.Mutex
+	cache map[string]cachedPermissions
+}
+
+func NewRoleService(db *gorm.DB) *RoleService { return &RoleService{db: db} }
+
+// RoleView é um papel com as permissões concedidas e o número de
+// usuários que o utilizam.
+type RoleView struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	System      bool     `json:"system"`
+	Permissions []string `json:"permissions"`
+	Users       int64    `json:"users"`
+}
+
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// EnsureDefaults semeia os papéis padrão que ainda não existem.
+func (s *RoleService) EnsureDefaults() error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, d := range defaultRoles {
+			role := models.Role{Name: d.name, Description: d.description, System: d.name == models.RoleAdmin}
+			res := tx.Where("name = ?", d.name).FirstOrCreate(&role)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 && !role.System {
+				continue
+			}
+			if err := replacePermissions(tx, role.ID, d.permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.invalidate()
+	return err
+}
+
+// HasPermission informa se o papel concede a permissão. O papel do aluno
+// não tem permissões nomeadas.
+func (s *RoleService) HasPermission(role, permission string) (bool, error) {
+	perms, err := s.Permissions(role)
+	return slices.Contains(perms, permission), err
+}
+
+// Permissions lista as permissões do papel, lidas do cache enquanto
+// válidas.
+func (s *RoleService) Permissions(role string) ([]string, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[role]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return slices.Clone(cached.perms), nil
+	}
+
+	perms, err := rolePermissions(s.db, role)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.cache == nil {
+		s.cache = map[string]cachedPermissions{}
+	}
+	s.cache[role] = cachedPermissions{perms: perms, expires: time.Now().Add(permissionCacheTTL)}
+	s.mu.Unlock()
+	return slices.Clone(perms), nil
+}
+
+// invalidate descarta o cache de permissões após a edição de papéis.
+func (s *RoleService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func (s *RoleService) List() ([]RoleView, error) {
+	var roles []models.Role
+	if err := s.db.Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Role  string
+		Total int64
+	}
+	if err := s.db.Model(&models.User{}).Select("role, COUNT(*) AS total").Group("role").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	users := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		users[c.Role] = c.Total
+	}
+
+	views := make([]RoleView, len(roles))
+	for i, r := range roles {
+		views[i] = newRoleView(r, users[r.Name])
+	}
+	return views, nil
+}
+
+func (s *RoleService) Create(in RoleInput, actor Actor) (*RoleView, error) {
+	name := strings.ToLower(strings.TrimSpace(in.Name))
+	if !roleNamePattern.MatchString(name) || reservedRole(name) {
+		return nil, Invalid("nome de papel inválido: use de 2 a 40 letras minúsculas, dígitos, '-' ou '_'")
+	}
+	if err := validatePermissions(in.Permissions); err != nil {
+		return nil, err
+	}
+
+	role := models.Role{Name: name, Description: strings.TrimSpace(in.Description)}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		if err := replacePermissions(tx, role.ID, in.Permissions); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditRoleCreated, roleTarget(role.Name), strings.Join(in.Permissions, ", "))
+	})
+	s.invalidate()
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, Conflict("Já existe um papel com este nome")
+		}
+		return nil, err
+	}
+	return s.view(role.ID)
+}
+
+// Update troca a descrição e as permissões do papel; o nome é fixo, pois
+// os usuários o referenciam. O papel admin não pode ser alterado.
+func (s *RoleService) Update(id uint, in RoleInput, actor Actor) (*RoleView, error) {
+	role, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if role.System {
+		return nil, Forbidden("O papel de administrador não pode ser alterado")
+	}
+	if err := validatePermissions(in.Permissions); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Update("description", strings.TrimSpace(in.Description)).Error; err != nil {
+			return err
+		}
+		if err := replacePermissions(tx, role.ID, in.Permissions); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditRoleUpdated, roleTarget(role.Name), strings.Join(in.Permissions, ", "))
+	})
+	s.invalidate()
+	if err != nil {
+		return nil, err
+	}
+	return s.view(role.ID)
+}
+
+// Delete remove um papel sem usuários. O papel admin é permanente.
+func (s *RoleService) Delete(id uint, actor Actor) error {
+	role, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return Forbidden("O papel de administrador não pode ser removido")
+	}
+	var users int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&users).Error; err != nil {
+		return err
+	}
+	if users > 0 {
+		return Conflict("Papel em uso: altere o papel dos usuários antes de removê-lo")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditRoleDeleted, roleTarget(role.Name), "")
+	})
+	s.invalidate()
+	return err
+}
+
+func (s *RoleService) load(id uint) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.Preload("Permissions").First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Papel não encontrado")
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (s *RoleService) view(id uint) (*RoleView, error) {
+	role, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	var users int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	v := newRoleView(*role, users)
+	return &v, nil
+}
+
+func newRoleView(r models.Role, users int64) RoleView {
+	perms := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		perms[i] = p.Permission
+	}
+	slices.SortFunc(perms, func(a, b string) int {
+		return slices.Index(models.Permissions, a) - slices.Index(models.Permissions, b)
+	})
+	return RoleView{ID: r.ID, Name: r.Name, Description: r.Description, System: r.System, Permissions: perms, Users: users}
+}
+
+func validatePermissions(perms []string) error {
+	for _, p := range perms {
+		if !slices.Contains(models.Permissions, p) {
+			return Invalid("permissão desconhecida: " + p)
+		}
+	}
+	return nil
+}
+
+// replacePermissions substitui as permissões concedidas ao papel.
+func replacePermissions(tx *gorm.DB, roleID uint, perms []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if err := tx.Create(&models.RolePermission{RoleID: roleID, Permission: p}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reservedRole indica os nomes que não são papéis da coordenação: o token
+// do aluno e as claims de chave de API.
+func reservedRole(name string) bool {
+	return name == "" || name == models.RoleStudent || name == models.RoleAPIKey
+}
+
+func roleHasPermission(db *gorm.DB, role, permission string) (bool, error) {
+	if reservedRole(role) {
+		return false, nil
+	}
+	var n int64
+	err := db.Model(&models.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ? AND role_permissions.permission = ?", role, permission).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func rolePermissions(db *gorm.DB, role string) ([]string, error) {
+	var perms []string
+	if reservedRole(role) {
+		return perms, nil
+	}
+	err := db.Model(&models.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", role).
+		Order("role_permissions.permission").
+		Pluck("role_permissions.permission", &perms).Error
+	return perms, err
+}
+
+// coversRole recusa a operação quando o papel alvo concede alguma
+// permissão que o papel de quem age não tem: com users.manage ninguém
+// convida ou promove acima do próprio papel, nem altera a conta de quem
+// está acima dele.
+func coversRole(db *gorm.DB, actorRole, targetRole string) error {
+	own, err := rolePermissions(db, actorRole)
+	if err != nil {
+		return err
+	}
+	target, err := rolePermissions(db, targetRole)
+	if err != nil {
+		return err
+	}
+	for _, p := range target {
+		if !slices.Contains(own, p) {
+			return Forbidden("O papel " + targetRole + " concede permissões que você não tem")
+		}
+	}
+	return nil
+}
+
+// ensureRole recusa papéis inexistentes ao criar ou editar usuários.
+func ensureRole(db *gorm.DB, name string) error {
+	var n int64
+	if err := db.Model(&models.Role{}).Where("name = ?", name).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 || reservedRole(name) {
+		return Invalid("papel inexistente: " + name)
+	}
+	return nil
+}
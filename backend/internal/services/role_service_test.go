@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestDefaultRolesAndCustomRole(t *testing.T) {
+	db := newTestDB(t)
+	roles := NewRoleService(db)
+	auth := NewAuthService(db, testSecret)
+
+	for _, tc := range []struct {
+		role, permission string
+		want             bool
+	}{
+		{models.RoleAdmin, models.PermUsersManage, true},
+		{models.RoleUser, models.PermActionsWrite, true},
+		{models.RoleUser, models.PermImportUpload, false},
+		{models.RoleViewer, models.PermReportsRead, true},
+		{models.RoleViewer, models.PermActionsWrite, false},
+		{models.RoleStudent, models.PermReportsRead, false},
+	} {
+		if got, err := roles.HasPermission(tc.role, tc.permission); err != nil || got != tc.want {
+			t.Errorf("%s/%s = %v (%v); esperado %v", tc.role, tc.permission, got, err, tc.want)
+		}
+	}
+
+	// O viewer enxerga todos os cursos sem vínculo em user_courses.
+	if scope, _ := NewScopeService(db, NewRoleService(db)).Resolve(&Claims{Role: models.RoleViewer}); !scope.Global() {
+		t.Error("viewer deveria ter escopo global")
+	}
+
+	if _, err := roles.Create(RoleInput{Name: "secretaria", Permissions: []string{"tudo.mesmo"}}, Actor{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("permissão desconhecida deveria ser recusada; obtive %v", err)
+	}
+	created, err := roles.Create(RoleInput{Name: "Secretaria", Permissions: []string{models.PermReportsRead}}, Actor{})
+	if err != nil || created.Name != "secretaria" {
+		t.Fatalf("Create: %+v, %v", created, err)
+	}
+	if _, err := auth.CreateUser("Sec", "sec@ufes.br", "segredo", "secretaria"); err != nil {
+		t.Fatalf("usuário com papel novo: %v", err)
+	}
+	if _, err := auth.CreateUser("X", "x@ufes.br", "segredo", "inexistente"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("papel inexistente deveria ser recusado; obtive %v", err)
+	}
+
+	// As permissões ficam em cache: alterações por fora do serviço só
+	// valem após a expiração; a edição pelo serviço limpa o cache na hora.
+	if ok, _ := roles.HasPermission("secretaria", models.PermActionsWrite); ok {
+		t.Error("secretaria ainda não tem actions.write")
+	}
+	db.Exec("DELETE FROM role_permissions WHERE role_id = ?", created.ID)
+	if ok, _ := roles.HasPermission("secretaria", models.PermReportsRead); !ok {
+		t.Error("permissões deveriam vir do cache")
+	}
+	if _, err := roles.Update(created.ID, RoleInput{Permissions: []string{models.PermReportsRead, models.PermActionsWrite}}, Actor{}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if ok, _ := roles.HasPermission("secretaria", models.PermActionsWrite); !ok {
+		t.Error("permissão concedida na edição deveria valer")
+	}
+	if err := roles.Delete(created.ID, Actor{}); !errors.Is(err, ErrConflict) {
+		t.Errorf("papel em uso não deveria ser removido; obtive %v", err)
+	}
+
+	var admin models.Role
+	db.Where("name = ?", models.RoleAdmin).First(&admin)
+	if _, err := roles.Update(admin.ID, RoleInput{}, Actor{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("papel admin não deveria ser alterado; obtive %v", err)
+	}
+}
+
+func TestUsersManageCannotEscalate(t *testing.T) {
+	db := newTestDB(t)
+	roles := NewRoleService(db)
+	auth := NewAuthService(db, testSecret)
+	users := NewUserService(db)
+	invitations := NewInvitationService(db, "https://ada.ufes.br")
+
+	for _, r := range []RoleInput{
+		{Name: "gestor", Permissions: []string{models.PermUsersManage, models.PermReportsRead}},
+		{Name: "leitura", Permissions: []string{models.PermReportsRead}},
+	} {
+		if _, err := roles.Create(r, Actor{}); err != nil {
+			t.Fatalf("Create %s: %v", r.Name, err)
+		}
+	}
+	auth.CreateUser("Admin", "admin@ufes.br", "segredo", models.RoleAdmin)
+	other, _ := auth.CreateUser("Outro admin", "outro@ufes.br", "segredo", models.RoleAdmin)
+	manager, _ := auth.CreateUser("Gestor", "gestor@ufes.br", "segredo", "gestor")
+	reader, _ := auth.CreateUser("Leitor", "leitor@ufes.br", "segredo", "leitura")
+	actor := Actor{UserID: manager.ID, Role: "gestor"}
+
+	// Convite e atribuição de papel exigem todas as permissões do papel.
+	if _, err := invitations.Invite(InviteInput{Name: "Ana", Email: "ana@ufes.br", Role: models.RoleAdmin}, actor); !errors.Is(err, ErrForbidden) {
+		t.Errorf("convite para admin deveria ser recusado; obtive %v", err)
+	}
+	if _, err := invitations.Invite(InviteInput{Name: "Ana", Email: "ana@ufes.br", Role: "leitura"}, actor); err != nil {
+		t.Errorf("convite para papel coberto: %v", err)
+	}
+	if _, err := users.Update(manager.ID, "gestor", reader.ID, UserUpdateInput{Role: models.RoleAdmin}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("promoção a admin deveria ser recusada; obtive %v", err)
+	}
+	if _, err := users.Update(manager.ID, "gestor", manager.ID, UserUpdateInput{Role: models.RoleAdmin}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("autopromoção a admin deveria ser recusada; obtive %v", err)
+	}
+
+	// Contas de papel acima do de quem age ficam intocadas.
+	if _, err := users.Update(manager.ID, "gestor", other.ID, UserUpdateInput{Password: "tomada123"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("troca de senha do admin deveria ser recusada; obtive %v", err)
+	}
+	if _, err := users.Update(manager.ID, "gestor", other.ID, UserUpdateInput{Email: "gestor2@ufes.br"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("troca de e-mail do admin deveria ser recusada; obtive %v", err)
+	}
+	if _, err := users.Deactivate(other.ID, actor); !errors.Is(err, ErrForbidden) {
+		t.Errorf("desativação do admin deveria ser recusada; obtive %v", err)
+	}
+	if _, err := users.Update(manager.ID, "gestor", reader.ID, UserUpdateInput{Email: "leitora@ufes.br"}); err != nil {
+		t.Errorf("edição de conta coberta: %v", err)
+	}
+	if _, err := users.Deactivate(reader.ID, actor); err != nil {
+		t.Errorf("desativação de conta coberta: %v", err)
+	}
+}
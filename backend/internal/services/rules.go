@@ -1,24 +1,171 @@
 package services
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"gorm.io/gorm"
 
 	"adamanagement/backend/internal/models"
 )
 
 // Escopos das regras de triagem (RN02 e RN03). São a única definição dos
-// critérios — o relatório acadêmico e o painel de indicadores os reutilizam.
+// critérios — o relatório acadêmico, o painel de indicadores e a carga dos
+// orientadores os reutilizam. Os limiares vêm das versões cadastradas em
+// triage_rules (RN31); as consultas precisam juntar students.
 
-// criticalScope: aluno ainda "Em regularidade", mas com mais de um
-// trancamento ou mais de um semestre sem carga horária (RN02).
-func criticalScope(q *gorm.DB) *gorm.DB {
-	return q.Where("academic_records.status = ?", models.StatusRegular).
-		Where("(academic_records.locks > 1 OR academic_records.semesters_no_hours > 1)")
+// TriageThresholds são os limiares de uma versão da regra de triagem.
+type TriageThresholds struct {
+	MaxLocks                 int `json:"max_locks"`
+	MaxSemestersNoHours      int `json:"max_semesters_no_hours"`
+	NearGraduationMaxPending int `json:"near_graduation_max_pending"`
+}
+
+// DefaultTriage são os limiares vigentes quando nenhuma versão cadastrada
+// se aplica: crítico com mais de um trancamento ou mais de um semestre sem
+// carga horária; próximo da formatura com até 6 obrigatórias pendentes.
+var DefaultTriage = TriageThresholds{MaxLocks: 1, MaxSemestersNoHours: 1, NearGraduationMaxPending: 6}
+
+func thresholdsOf(r models.TriageRule) TriageThresholds {
+	return TriageThresholds{
+		MaxLocks:                 r.MaxLocks,
+		MaxSemestersNoHours:      r.MaxSemestersNoHours,
+		NearGraduationMaxPending: r.NearGraduationMaxPending,
+	}
+}
+
+// triageGroup reúne os semestres em que valem os mesmos limiares: global
+// para os cursos sem versão própria e, em courses, os dos cursos que têm.
+type triageGroup struct {
+	semesterIDs []uint
+	global      TriageThresholds
+	courses     map[uint]TriageThresholds
+}
+
+// triagePlan é a tradução das versões cadastradas em condições SQL por
+// semestre e curso. Sem nenhuma versão cadastrada não há grupos, e valem
+// os limiares padrão em todos os registros.
+type triagePlan struct {
+	groups []triageGroup
+}
+
+// loadTriage resolve, para cada semestre, a versão vigente da regra
+// global e de cada curso: a de maior effective_from que não passe do
+// código do semestre.
+func loadTriage(db *gorm.DB) (*triagePlan, error) {
+	var rules []models.TriageRule
+	if err := db.Order("effective_from").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return &triagePlan{}, nil
+	}
+	var semesters []models.Semester
+	if err := db.Select("id", "code").Order("code").Find(&semesters).Error; err != nil {
+		return nil, err
+	}
+
+	plan := &triagePlan{}
+	index := map[string]int{}
+	for _, sem := range semesters {
+		g := triageGroup{global: DefaultTriage, courses: map[uint]TriageThresholds{}}
+		for _, r := range rules {
+			if r.EffectiveFrom > sem.Code {
+				break
+			}
+			if r.CourseID == nil {
+				g.global = thresholdsOf(r)
+			} else {
+				g.courses[*r.CourseID] = thresholdsOf(r)
+			}
+		}
+		key := g.signature()
+		if i, ok := index[key]; ok {
+			plan.groups[i].semesterIDs = append(plan.groups[i].semesterIDs, sem.ID)
+			continue
+		}
+		g.semesterIDs = []uint{sem.ID}
+		index[key] = len(plan.groups)
+		plan.groups = append(plan.groups, g)
+	}
+	return plan, nil
+}
+
+// signature identifica a configuração do grupo, para juntar semestres
+// com os mesmos limiares.
+func (g triageGroup) signature() string {
+	ids := make([]uint, 0, len(g.courses))
+	for id := range g.courses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v", g.global)
+	for _, id := range ids {
+		fmt.Fprintf(&b, ";%d=%v", id, g.courses[id])
+	}
+	return b.String()
+}
+
+// where monta "(semestres/cursos do trecho AND cond) OR ..." aplicando a
+// cada trecho a condição gerada por cond com os limiares vigentes.
+func (p *triagePlan) where(q *gorm.DB, cond func(TriageThresholds) (string, []any)) *gorm.DB {
+	if len(p.groups) == 0 {
+		sql, args := cond(DefaultTriage)
+		return q.Where(sql, args...)
+	}
+
+	var parts []string
+	var args []any
+	add := func(scope string, scopeArgs []any, t TriageThresholds) {
+		sql, condArgs := cond(t)
+		parts = append(parts, "("+scope+" AND "+sql+")")
+		args = append(args, scopeArgs...)
+		args = append(args, condArgs...)
+	}
+	for _, g := range p.groups {
+		// Cursos com versão própria, agrupados pelos mesmos limiares.
+		byThresholds := map[TriageThresholds][]uint{}
+		var own []uint
+		for id, t := range g.courses {
+			byThresholds[t] = append(byThresholds[t], id)
+			own = append(own, id)
+		}
+		if len(own) == 0 {
+			add("academic_records.semester_id IN ?", []any{g.semesterIDs}, g.global)
+			continue
+		}
+		add("academic_records.semester_id IN ? AND students.course_id NOT IN ?", []any{g.semesterIDs, own}, g.global)
+		for t, ids := range byThresholds {
+			add("academic_records.semester_id IN ? AND students.course_id IN ?", []any{g.semesterIDs, ids}, t)
+		}
+	}
+	return q.Where("("+strings.Join(parts, " OR ")+")", args...)
+}
+
+// criticalScope: aluno ainda "Em regularidade", mas com trancamentos ou
+// semestres sem carga horária acima dos limiares vigentes (RN02).
+func (p *triagePlan) criticalScope(q *gorm.DB) *gorm.DB {
+	q = q.Where("academic_records.status = ?", models.StatusRegular)
+	return p.where(q, func(t TriageThresholds) (string, []any) {
+		return "(academic_records.locks > ? OR academic_records.semesters_no_hours > ?)",
+			[]any{t.MaxLocks, t.MaxSemestersNoHours}
+	})
+}
+
+// nearGraduationScope: aluno "Em regularidade" com obrigatórias pendentes
+// até o limiar vigente (RN03).
+func (p *triagePlan) nearGraduationScope(q *gorm.DB) *gorm.DB {
+	q = q.Where("academic_records.status = ?", models.StatusRegular)
+	return p.where(q, func(t TriageThresholds) (string, []any) {
+		return "academic_records.pending_obligatory <= ?", []any{t.NearGraduationMaxPending}
+	})
 }
 
-// nearGraduationScope: aluno "Em regularidade" com até maxPending
-// disciplinas obrigatórias pendentes (RN03).
-func nearGraduationScope(q *gorm.DB, maxPending int) *gorm.DB {
+// maxPendingScope: aluno "Em regularidade" com até maxPending obrigatórias
+// pendentes — o filtro explícito do relatório, independente das regras.
+func maxPendingScope(q *gorm.DB, maxPending int) *gorm.DB {
 	return q.Where("academic_records.status = ?", models.StatusRegular).
 		Where("academic_records.pending_obligatory <= ?", maxPending)
 }
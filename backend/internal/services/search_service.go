@@ -0,0 +1,281 @@
+package services
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Busca sem diferenciar maiúsculas nem acentos. Os nomes de alunos, cursos
+// e disciplinas são gravados também normalizados (search_name, ver
+// models.Fold), e o termo buscado passa pela mesma normalização: "joao"
+// encontra "João" tanto no PostgreSQL quanto no SQLite dos testes. Fazer a
+// normalização na aplicação, e não com unaccent() na consulta, permite
+// indexar a coluna diretamente — unaccent não é IMMUTABLE e não entra em
+// índices de expressão. No PostgreSQL, índices trigram (pg_trgm) sobre
+// search_name atendem o LIKE '%…%' sem varrer a tabela.
+
+// Tipos de resultado da busca global.
+const (
+	SearchStudent    = "student"
+	SearchCourse     = "course"
+	SearchDiscipline = "discipline"
+)
+
+const (
+	searchMinLength    = 2
+	searchDefaultLimit = 20
+	searchMaxLimit     = 50
+)
+
+// searchTables são as tabelas com a coluna search_name.
+var searchTables = []string{"students", "courses", "disciplines"}
+
+// likeEscaper neutraliza os curingas do LIKE no termo buscado.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// foldedCondition monta a condição "a coluna normalizada contém todas as
+// palavras de text", em qualquer ordem: "silva joao" encontra "João da
+// Silva". Vazia se text não tem palavras.
+func foldedCondition(column, text string) (string, []any) {
+	var conds []string
+	var args []any
+	for _, word := range strings.Fields(models.Fold(text)) {
+		conds = append(conds, column+` LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(word)+"%")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// matchFolded aplica foldedCondition à consulta — a semântica dos filtros
+// por nome dos relatórios.
+func matchFolded(q *gorm.DB, column, text string) *gorm.DB {
+	cond, args := foldedCondition(column, text)
+	if cond == "" {
+		return q
+	}
+	return q.Where(cond, args...)
+}
+
+// matchContains filtra códigos (matrícula) que contêm text, tratando os
+// curingas do LIKE como caracteres comuns.
+func matchContains(q *gorm.DB, column, text string) *gorm.DB {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return q
+	}
+	return q.Where(column+` LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(text)+"%")
+}
+
+type SearchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *SearchService { return &SearchService{db: db} }
+
+// SearchHit é um resultado da busca global. Code é a matrícula do aluno
+// ou o código do curso/disciplina; Detail traz o curso do aluno. Score
+// vai de 0 a 1: 1 é o nome ou código exato.
+type SearchHit struct {
+	Type   string  `json:"type"`
+	ID     uint    `json:"id"`
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Detail string  `json:"detail,omitempty"`
+	Score  float64 `json:"score"`
+}
+
+// Search procura text nos alunos (nome ou matrícula), cursos (nome ou
+// código) e disciplinas (nome ou código) e devolve até limit resultados
+// (padrão 20, máximo 50) do mais ao menos relevante. Alunos e cursos
+// respeitam o escopo do requisitante.
+func (s *SearchService) Search(text string, scope CourseScope, limit int) ([]SearchHit, error) {
+	query := models.Fold(text)
+	raw := strings.TrimSpace(text)
+	if utf8.RuneCountInString(query) < searchMinLength {
+		return nil, Invalid("a busca precisa de ao menos 2 caracteres")
+	}
+	if limit <= 0 || limit > searchMaxLimit {
+		limit = searchDefaultLimit
+	}
+	// Candidatos por tipo: os nomes mais curtos primeiro, que tendem a ser
+	// os mais próximos do termo; a ordem final é pela relevância.
+	pool := limit * 3
+
+	hits := []SearchHit{}
+	nameCond, nameArgs := foldedCondition("students.search_name", query)
+	var students []models.Student
+	q := s.db.Preload("Course").
+		Where("("+nameCond+`) OR students.registration LIKE ? ESCAPE '\'`, append(nameArgs, likeEscaper.Replace(raw)+"%")...)
+	if err := scope.apply(q, "students.course_id").
+		Order("LENGTH(students.search_name), students.id").Limit(pool).
+		Find(&students).Error; err != nil {
+		return nil, err
+	}
+	for _, st := range students {
+		hits = append(hits, SearchHit{
+			Type: SearchStudent, ID: st.ID, Code: st.Registration, Name: st.Name, Detail: st.Course.Name,
+			Score: max(relevance(query, st.SearchName), codeRelevance(raw, st.Registration)),
+		})
+	}
+
+	nameCond, nameArgs = foldedCondition("search_name", query)
+	q = s.db.Model(&models.Course{})
+	if code, err := strconv.Atoi(raw); err == nil {
+		q = q.Where("("+nameCond+") OR code = ?", append(nameArgs, code)...)
+	} else {
+		q = q.Where(nameCond, nameArgs...)
+	}
+	var courses []models.Course
+	if err := scope.apply(q, "id").
+		Order("LENGTH(search_name), id").Limit(pool).
+		Find(&courses).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range courses {
+		code := strconv.Itoa(c.Code)
+		hits = append(hits, SearchHit{
+			Type: SearchCourse, ID: c.ID, Code: code, Name: c.Name,
+			Score: max(relevance(query, c.SearchName), codeRelevance(raw, code)),
+		})
+	}
+
+	var disciplines []models.Discipline
+	if err := s.db.
+		Where("("+nameCond+`) OR UPPER(code) LIKE ? ESCAPE '\'`, append(nameArgs, likeEscaper.Replace(strings.ToUpper(raw))+"%")...).
+		Order("LENGTH(search_name), id").Limit(pool).
+		Find(&disciplines).Error; err != nil {
+		return nil, err
+	}
+	for _, d := range disciplines {
+		hits = append(hits, SearchHit{
+			Type: SearchDiscipline, ID: d.ID, Code: d.Code, Name: d.Name,
+			Score: max(relevance(query, d.SearchName), codeRelevance(raw, d.Code)),
+		})
+	}
+
+	slices.SortStableFunc(hits, func(a, b SearchHit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Name, b.Name))
+	})
+	return hits[:min(limit, len(hits))], nil
+}
+
+// relevance pontua o nome normalizado text contra a busca normalizada
+// query: nome exato, começo do nome, começo de palavras e, por último,
+// apenas contém as palavras. Dentro de cada faixa, quanto mais do nome a
+// busca cobre, maior a nota. Zero se alguma palavra não aparece.
+func relevance(query, text string) float64 {
+	words := strings.Fields(query)
+	if !containsAll(text, words) {
+		return 0
+	}
+	var base float64
+	switch {
+	case text == query:
+		base = 1
+	case strings.HasPrefix(text, query):
+		base = 0.9
+	case wordsStart(text, words):
+		base = 0.75
+	default:
+		base = 0.6
+	}
+	coverage := min(float64(len(query))/float64(max(len(text), 1)), 1)
+	return math.Round((0.9*base+0.1*coverage)*1000) / 1000
+}
+
+// codeRelevance pontua matrícula e códigos: exato ou prefixo.
+func codeRelevance(query, code string) float64 {
+	switch {
+	case strings.EqualFold(code, query):
+		return 1
+	case len(code) >= len(query) && strings.EqualFold(code[:len(query)], query):
+		return 0.9
+	}
+	return 0
+}
+
+func containsAll(text string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// wordsStart informa se cada palavra da busca começa alguma palavra do
+// nome: "jo sil" em "joao da silva".
+func wordsStart(text string, words []string) bool {
+	names := strings.Fields(text)
+	for _, w := range words {
+		if !slices.ContainsFunc(names, func(n string) bool { return strings.HasPrefix(n, w) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Backfill preenche search_name nas linhas gravadas antes da coluna
+// existir (ou por fora da aplicação). Roda na subida, depois da migração;
+// sem pendências, custa uma consulta por tabela.
+func (s *SearchService) Backfill() error {
+	const batch = 1000
+	for _, table := range searchTables {
+		for {
+			var rows []struct {
+				ID   uint
+				Name string
+			}
+			if err := s.db.Table(table).Select("id, name").
+				Where("(search_name IS NULL OR search_name = '') AND name <> ''").
+				Order("id").Limit(batch).Find(&rows).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				for _, r := range rows {
+					// Nomes que normalizam para vazio (só acentos soltos)
+					// ganham um espaço para não voltarem ao lote.
+					folded := cmp.Or(models.Fold(r.Name), " ")
+					if err := tx.Table(table).Where("id = ?", r.ID).Update("search_name", folded).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureTrigramIndexes cria, no PostgreSQL, a extensão pg_trgm e os
+// índices GIN sobre search_name. Sem permissão para a extensão a busca
+// continua correta, apenas sem índice.
+func (s *SearchService) EnsureTrigramIndexes() error {
+	if s.db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := s.db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return err
+	}
+	for _, table := range searchTables {
+		if err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_search_name_trgm ON " + table +
+			" USING gin (search_name gin_trgm_ops)").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
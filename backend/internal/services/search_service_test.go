@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestSearchIgnoresCaseAndAccents(t *testing.T) {
+	db := newTestDB(t)
+	for reg, name := range map[string]string{"2019001": "João da Silva", "2019002": "Joana Souza", "2019003": "MARIA JOÃO"} {
+		s := seedStudentWithStatus(t, db, reg, "2024/1", models.StatusRegular)
+		s.Name = name
+		db.Save(s)
+	}
+	var course models.Course
+	db.First(&course)
+	course.Name = "Ciência da Computação"
+	db.Save(&course)
+	disciplines := NewDisciplineService(db)
+	calc, _ := disciplines.Create("INF100", "Calculo")
+	renamed := "Cálculo I"
+	if _, err := disciplines.Update(calc.ID, nil, &renamed); err != nil {
+		t.Fatalf("Update disciplina: %v", err)
+	}
+
+	reports := NewReportService(db)
+	for _, tc := range []struct {
+		f    RecordsFilter
+		want int
+	}{
+		{RecordsFilter{StudentName: "joao"}, 2},
+		{RecordsFilter{StudentName: "silva JOÃO"}, 1},
+		{RecordsFilter{CourseName: "computacao"}, 3},
+		{RecordsFilter{StudentName: "_"}, 0}, // curinga do LIKE é literal
+	} {
+		if got, _, err := reports.Records(tc.f); err != nil || len(got) != tc.want {
+			t.Errorf("Records(%+v): %d linhas, esperava %d (%v)", tc.f, len(got), tc.want, err)
+		}
+	}
+	if got, _, _ := reports.Students(StudentsFilter{Name: "JOAO"}); len(got) != 2 {
+		t.Errorf("Students por nome sem acento: %d", len(got))
+	}
+	if got, _, _ := reports.Courses(CoursesFilter{Name: "ciencia"}); len(got) != 1 {
+		t.Errorf("Courses por nome sem acento: %d", len(got))
+	}
+
+	svc := NewSearchService(db)
+	hits, err := svc.Search("joao", AllCourses(), 0)
+	if err != nil || len(hits) != 2 {
+		t.Fatalf("Search: %+v %v", hits, err)
+	}
+	// Começo do nome pesa mais que começo de uma palavra qualquer.
+	if hits[0].Name != "João da Silva" || hits[0].Detail != "Ciência da Computação" || hits[0].Score <= hits[1].Score {
+		t.Errorf("ordem por relevância: %+v", hits)
+	}
+	if hits, _ := svc.Search("2019002", AllCourses(), 0); len(hits) != 1 || hits[0].Score != 1 {
+		t.Errorf("busca por matrícula exata: %+v", hits)
+	}
+	if hits, _ := svc.Search("calculo", AllCourses(), 0); len(hits) != 1 || hits[0].Type != SearchDiscipline {
+		t.Errorf("disciplina renomeada: %+v", hits)
+	}
+	// Sem cursos no escopo, alunos e cursos somem; disciplinas não.
+	if hits, _ := svc.Search("ca", RestrictTo(nil), 0); len(hits) != 1 || hits[0].Type != SearchDiscipline {
+		t.Errorf("escopo vazio: %+v", hits)
+	}
+	if _, err := svc.Search(" á ", AllCourses(), 0); !errors.Is(err, ErrInvalid) {
+		t.Errorf("busca de 1 caractere: %v", err)
+	}
+
+	// Linhas anteriores à coluna são normalizadas na subida.
+	db.Exec("UPDATE students SET search_name = ''")
+	if err := svc.Backfill(); err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	var folded models.Student
+	db.Where("registration = ?", "2019003").First(&folded)
+	if folded.SearchName != "maria joao" {
+		t.Errorf("search_name após Backfill: %q", folded.SearchName)
+	}
+}
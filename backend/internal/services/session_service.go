@@ -0,0 +1,270 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Validades da sessão (RN08): o token de acesso é curto e renovado pelo
+// cliente com o refresh token, que gira a cada uso.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenPair é o par entregue no login e em cada renovação.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // segundos de validade do AccessToken
+}
+
+// SessionService emite, renova e revoga sessões. Cada sessão é uma família
+// de refresh tokens; o token de acesso carrega o ID da família (sid) e só
+// é aceito enquanto ela estiver ativa.
+type SessionService struct {
+	db        *gorm.DB
+	jwtSecret []byte
+}
+
+func NewSessionService(db *gorm.DB, jwtSecret string) *SessionService {
+	return &SessionService{db: db, jwtSecret: []byte(jwtSecret)}
+}
+
+// Start abre uma nova sessão para a identidade de claims (staff ou aluno).
+func (s *SessionService) Start(claims Claims) (*TokenPair, error) {
+	family, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	claims.SessionID = family
+
+	var pair *TokenPair
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		refresh, _, err := createRefreshToken(tx, &claims, family, time.Now())
+		if err != nil {
+			return err
+		}
+		pair, err = s.sign(claims, refresh)
+		return err
+	})
+	return pair, err
+}
+
+// Refresh troca um refresh token válido por um novo par (rotação). O papel
+// é relido do banco, de modo que mudanças de perfil valem já na próxima
+// renovação. Reapresentar um token já substituído indica vazamento: a
+// sessão inteira é revogada.
+func (s *SessionService) Refresh(raw string) (*TokenPair, error) {
+	var current models.RefreshToken
+	if err := s.db.Where("token_hash = ?", hashToken(raw)).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, Unauthorized("sessão inválida ou expirada")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if current.RevokedAt != nil {
+		if current.ReplacedByID != nil {
+			if err := revokeFamily(s.db, current.FamilyID, now); err != nil {
+				return nil, err
+			}
+		}
+		return nil, Unauthorized("sessão inválida ou expirada")
+	}
+	if !current.ExpiresAt.After(now) {
+		return nil, Unauthorized("sessão inválida ou expirada")
+	}
+
+	claims, err := s.claimsFor(&current)
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *TokenPair
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// A condição revoked_at IS NULL impede que duas renovações
+		// simultâneas do mesmo token gerem dois sucessores.
+		res := tx.Model(&current).Where("revoked_at IS NULL").Update("revoked_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return Unauthorized("sessão inválida ou expirada")
+		}
+
+		refresh, next, err := createRefreshToken(tx, claims, current.FamilyID, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&current).Update("replaced_by_id", next.ID).Error; err != nil {
+			return err
+		}
+		pair, err = s.sign(*claims, refresh)
+		return err
+	})
+	return pair, err
+}
+
+// claimsFor reconstrói a identidade do dono do refresh token. Conta
+// removida ou desativada invalida a sessão.
+func (s *SessionService) claimsFor(rt *models.RefreshToken) (*Claims, error) {
+	claims := &Claims{SessionID: rt.FamilyID}
+	if rt.StudentID != nil {
+		var student models.Student
+		if err := s.db.Select("id", "registration").First(&student, *rt.StudentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, Unauthorized("sessão inválida ou expirada")
+			}
+			return nil, err
+		}
+		claims.StudentID = student.ID
+		claims.Registration = student.Registration
+		claims.Role = models.RoleStudent
+		return claims, nil
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "role", "deactivated_at").First(&user, *rt.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, Unauthorized("sessão inválida ou expirada")
+		}
+		return nil, err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, Unauthorized("conta desativada")
+	}
+	claims.UserID = user.ID
+	claims.Role = user.Role
+	return claims, nil
+}
+
+// Logout encerra a sessão do token apresentado.
+func (s *SessionService) Logout(claims *Claims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+	return revokeFamily(s.db, claims.SessionID, time.Now())
+}
+
+// LogoutAll encerra todas as sessões da identidade do token.
+func (s *SessionService) LogoutAll(claims *Claims) error {
+	return revokeSessions(s.db, claims.UserID, claims.StudentID, time.Now())
+}
+
+// Validate é consultado pelo middleware a cada requisição autenticada:
+// recusa tokens de sessão encerrada, de conta removida ou desativada ou
+// emitidos antes da última troca de senha.
+func (s *SessionService) Validate(claims *Claims) error {
+	if claims.SessionID == "" {
+		return Unauthorized("sessão inválida")
+	}
+
+	var active int64
+	if err := s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL AND expires_at > ?", claims.SessionID, time.Now()).
+		Count(&active).Error; err != nil {
+		return err
+	}
+	if active == 0 {
+		return Unauthorized("sessão encerrada")
+	}
+
+	var account struct {
+		PasswordChangedAt *time.Time
+		DeactivatedAt     *time.Time
+	}
+	var q *gorm.DB
+	if claims.Role == models.RoleStudent {
+		q = s.db.Model(&models.Student{}).Select("password_changed_at").Where("id = ?", claims.StudentID)
+	} else {
+		q = s.db.Model(&models.User{}).Select("password_changed_at", "deactivated_at").Where("id = ?", claims.UserID)
+	}
+	res := q.Limit(1).Scan(&account)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return Unauthorized("sessão inválida")
+	}
+	if account.DeactivatedAt != nil {
+		return Unauthorized("conta desativada")
+	}
+	// iat tem resolução de segundos: compara no mesmo grão.
+	if account.PasswordChangedAt != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(account.PasswordChangedAt.Truncate(time.Second)) {
+		return Unauthorized("senha alterada: faça login novamente")
+	}
+	return nil
+}
+
+func (s *SessionService) sign(claims Claims, refresh string) (*TokenPair, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// createRefreshToken grava um novo refresh token da família e devolve o
+// valor em claro (entregue só ao cliente) e a linha criada.
+func createRefreshToken(tx *gorm.DB, claims *Claims, family string, now time.Time) (string, *models.RefreshToken, error) {
+	raw, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	rt := models.RefreshToken{
+		FamilyID:  family,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	}
+	if claims.StudentID != 0 {
+		rt.StudentID = &claims.StudentID
+	} else {
+		rt.UserID = &claims.UserID
+	}
+	if err := tx.Create(&rt).Error; err != nil {
+		return "", nil, err
+	}
+	return raw, &rt, nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func revokeFamily(tx *gorm.DB, family string, now time.Time) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", now).Error
+}
+
+// revokeSessions encerra todas as sessões de um usuário (userID) ou aluno
+// (studentID). Usada no logout geral e na troca de senha.
+func revokeSessions(tx *gorm.DB, userID, studentID uint, now time.Time) error {
+	q := tx.Model(&models.RefreshToken{}).Where("revoked_at IS NULL")
+	if studentID != 0 {
+		q = q.Where("student_id = ?", studentID)
+	} else {
+		q = q.Where("user_id = ?", userID)
+	}
+	return q.Update("revoked_at", now).Error
+}
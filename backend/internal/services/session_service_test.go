@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"adamanagement/backend/internal/models"
+)
+
+// parseAccess lê as claims de um token de acesso emitido nos testes.
+func parseAccess(t *testing.T, token string) *Claims {
+	t.Helper()
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(testSecret), nil
+	}); err != nil {
+		t.Fatalf("token de acesso inválido: %v", err)
+	}
+	return claims
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	sessions := NewSessionService(db, testSecret)
+	if _, err := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	login, err := auth.Login("ana@ufes.br", "segredo", Actor{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	first := login.Tokens
+	second, err := sessions.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("a renovação deve girar o refresh token")
+	}
+	claims := parseAccess(t, second.AccessToken)
+	if err := sessions.Validate(claims); err != nil {
+		t.Fatalf("sessão renovada deveria ser válida: %v", err)
+	}
+
+	// Reapresentar o token já girado revoga a sessão inteira.
+	if _, err := sessions.Refresh(first.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("reuso deveria ser recusado; obtive %v", err)
+	}
+	if _, err := sessions.Refresh(second.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("após reuso, o sucessor também deveria ser revogado; obtive %v", err)
+	}
+	if err := sessions.Validate(claims); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("token de acesso da sessão revogada deveria ser recusado; obtive %v", err)
+	}
+}
+
+func TestLogoutEndsOnlyCurrentSession(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentAuthService(db, testSecret)
+	sessions := NewSessionService(db, testSecret)
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	if err := svc.Register("2022001", "senha123"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	laptop, _, _ := svc.Login("2022001", "senha123", Actor{})
+	phone, _, _ := svc.Login("2022001", "senha123", Actor{})
+	laptopClaims, phoneClaims := parseAccess(t, laptop.AccessToken), parseAccess(t, phone.AccessToken)
+
+	if err := sessions.Logout(laptopClaims); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if err := sessions.Validate(laptopClaims); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("sessão encerrada deveria ser recusada; obtive %v", err)
+	}
+	if err := sessions.Validate(phoneClaims); err != nil {
+		t.Fatalf("outra sessão deveria continuar válida: %v", err)
+	}
+
+	if err := sessions.LogoutAll(phoneClaims); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+	if _, err := sessions.Refresh(phone.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("logout geral deveria revogar todos os refresh tokens; obtive %v", err)
+	}
+}
+
+func TestPasswordChangeAndDeactivationInvalidateTokens(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	users := NewUserService(db)
+	sessions := NewSessionService(db, testSecret)
+	admin, _ := auth.CreateUser("Admin", "admin@ufes.br", "segredo", models.RoleAdmin)
+	ana, _ := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser)
+
+	login, _ := auth.Login("ana@ufes.br", "segredo", Actor{})
+	claims := parseAccess(t, login.Tokens.AccessToken)
+	if _, err := users.Update(ana.ID, models.RoleUser, ana.ID, UserUpdateInput{Password: "nova-senha"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := sessions.Validate(claims); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("token anterior à troca de senha deveria ser recusado; obtive %v", err)
+	}
+
+	login, err := auth.Login("ana@ufes.br", "nova-senha", Actor{})
+	if err != nil {
+		t.Fatalf("Login com a nova senha: %v", err)
+	}
+	claims = parseAccess(t, login.Tokens.AccessToken)
+	if _, err := users.Deactivate(ana.ID, Actor{UserID: ana.ID}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("usuário não pode desativar a si mesmo; obtive %v", err)
+	}
+	if _, err := users.Deactivate(ana.ID, Actor{UserID: admin.ID, Role: models.RoleAdmin}); err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	if err := sessions.Validate(claims); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("token de usuário desativado deveria ser recusado; obtive %v", err)
+	}
+	if _, err := sessions.Refresh(login.Tokens.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("refresh de usuário desativado deveria ser recusado; obtive %v", err)
+	}
+	if _, err := auth.Login("ana@ufes.br", "nova-senha", Actor{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("usuário desativado não deveria entrar; obtive %v", err)
+	}
+
+	// O registro permanece: o histórico continua resolvendo o nome.
+	var kept models.User
+	if err := db.First(&kept, ana.ID).Error; err != nil || kept.Name != "Ana" {
+		t.Errorf("usuário desativado deveria continuar no banco: %v", err)
+	}
+	if inactive, _, _ := users.List(UserListFilter{Status: "inactive"}); len(inactive) != 1 {
+		t.Errorf("inativos: %d; esperado 1", len(inactive))
+	}
+
+	if _, err := users.Reactivate(ana.ID, Actor{UserID: admin.ID, Role: models.RoleAdmin}); err != nil {
+		t.Fatalf("Reactivate: %v", err)
+	}
+	if _, err := auth.Login("ana@ufes.br", "nova-senha", Actor{}); err != nil {
+		t.Errorf("usuário reativado deveria entrar; obtive %v", err)
+	}
+}
@@ -3,9 +3,7 @@ package services
 import (
 	"errors"
 	"strings"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
@@ -16,17 +14,33 @@ import (
 // mesmo maquinário de JWT/BCrypt do staff; a identidade do aluno é a
 // própria matrícula (não há e-mail nos dados importados).
 type StudentAuthService struct {
-	db        *gorm.DB
-	jwtSecret []byte
+	db       *gorm.DB
+	sessions *SessionService
+	guard    *LoginGuard
+	// passwordLogin desligado deixa apenas o login institucional (SSO).
+	passwordLogin bool
 }
 
 func NewStudentAuthService(db *gorm.DB, jwtSecret string) *StudentAuthService {
-	return &StudentAuthService{db: db, jwtSecret: []byte(jwtSecret)}
+	return &StudentAuthService{
+		db:            db,
+		sessions:      NewSessionService(db, jwtSecret),
+		guard:         NewLoginGuard(db),
+		passwordLogin: true,
+	}
 }
 
+// SetPasswordLogin liga ou desliga o autocadastro e o login por senha.
+func (s *StudentAuthService) SetPasswordLogin(enabled bool) { s.passwordLogin = enabled }
+
+var errBadStudentCredentials = Unauthorized("matrícula ou senha incorretos")
+
 // Register cria o acesso do aluno (RN16): exige matrícula já importada e
 // ainda sem senha definida; grava a senha em hash BCrypt. Login = matrícula.
 func (s *StudentAuthService) Register(registration, password string) error {
+	if !s.passwordLogin {
+		return errPasswordLoginDisabled
+	}
 	registration = strings.TrimSpace(registration)
 	if registration == "" {
 		return Invalid("matrícula é obrigatória")
@@ -43,7 +57,7 @@ func (s *StudentAuthService) Register(registration, password string) error {
 		return err
 	}
 	if student.Password != "" {
-		return Conflict("Já existe uma conta para esta matrícula. Faça login.")
+		return Conflict("Já existe uma conta para esta matrícula. Faça login ou peça à coordenação um código de redefinição de senha.")
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -53,42 +67,48 @@ func (s *StudentAuthService) Register(registration, password string) error {
 	return s.db.Model(&student).Update("password", string(hash)).Error
 }
 
-// Login valida matrícula + senha e emite JWT com role="student".
-func (s *StudentAuthService) Login(registration, password string) (string, *models.Student, error) {
+// Login valida matrícula + senha e abre uma sessão com role="student".
+// Matrículas são previsíveis: as falhas contam para o limite de tentativas
+// da matrícula (LoginGuard), exista ela ou não.
+func (s *StudentAuthService) Login(registration, password string, actor Actor) (*TokenPair, *models.Student, error) {
+	if !s.passwordLogin {
+		return nil, nil, errPasswordLoginDisabled
+	}
+	key := StudentAccountKey(registration)
+	if err := s.guard.Check(key); err != nil {
+		return nil, nil, err
+	}
+
 	var student models.Student
 	if err := s.db.Preload("Course").
 		Where("registration = ?", strings.TrimSpace(registration)).
 		First(&student).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, Unauthorized("matrícula ou senha incorretos")
+			return nil, nil, s.guard.loginFailed(key, actor, "matrícula inexistente", errBadStudentCredentials)
 		}
-		return "", nil, err
+		return nil, nil, err
 	}
 
 	if student.Password == "" {
 		// Conta ainda não criada — mensagem genérica evita revelar matrículas.
-		return "", nil, Unauthorized("matrícula ou senha incorretos")
+		return nil, nil, s.guard.loginFailed(key, actor, "conta não criada", errBadStudentCredentials)
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(password)); err != nil {
-		return "", nil, Unauthorized("matrícula ou senha incorretos")
+		return nil, nil, s.guard.loginFailed(key, actor, "senha incorreta", errBadStudentCredentials)
+	}
+	if err := s.guard.Reset(key); err != nil {
+		return nil, nil, err
 	}
 
-	now := time.Now()
-	claims := &Claims{
+	pair, err := s.sessions.Start(Claims{
 		Role:         models.RoleStudent,
 		StudentID:    student.ID,
 		Registration: student.Registration,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
-		},
-	}
-
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	})
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return token, &student, nil
+	return pair, &student, nil
 }
 
 // Me retorna o aluno (com curso) e o enquadramento mais recente.
@@ -49,7 +49,7 @@ func TestStudentLoginFlow(t *testing.T) {
 	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
 
 	// Sem conta criada → não autentica.
-	if _, _, err := svc.Login("2022001", "senha123"); !errors.Is(err, ErrUnauthorized) {
+	if _, _, err := svc.Login("2022001", "senha123", Actor{}); !errors.Is(err, ErrUnauthorized) {
 		t.Fatalf("login antes do cadastro deve dar ErrUnauthorized; obtive %v", err)
 	}
 
@@ -58,12 +58,12 @@ func TestStudentLoginFlow(t *testing.T) {
 	}
 
 	// Senha errada.
-	if _, _, err := svc.Login("2022001", "errada"); !errors.Is(err, ErrUnauthorized) {
+	if _, _, err := svc.Login("2022001", "errada", Actor{}); !errors.Is(err, ErrUnauthorized) {
 		t.Fatalf("senha errada deve dar ErrUnauthorized; obtive %v", err)
 	}
 
 	// Sucesso: token carrega role="student" e a matrícula.
-	token, student, err := svc.Login("2022001", "senha123")
+	pair, student, err := svc.Login("2022001", "senha123", Actor{})
 	if err != nil {
 		t.Fatalf("login válido: %v", err)
 	}
@@ -72,7 +72,7 @@ func TestStudentLoginFlow(t *testing.T) {
 	}
 
 	claims := &Claims{}
-	parsed, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+	parsed, err := jwt.ParseWithClaims(pair.AccessToken, claims, func(*jwt.Token) (any, error) {
 		return []byte(testSecret), nil
 	}, jwt.WithValidMethods([]string{"HS256"}))
 	if err != nil || !parsed.Valid {
@@ -87,6 +87,9 @@ func TestStudentLoginFlow(t *testing.T) {
 	if claims.UserID != 0 {
 		t.Errorf("token de aluno não deve carregar UserID; obtive %d", claims.UserID)
 	}
+	if claims.SessionID == "" || pair.RefreshToken == "" {
+		t.Errorf("login deve abrir sessão com refresh token: %+v", pair)
+	}
 }
 
 func TestStudentMeReturnsLatestStatus(t *testing.T) {
@@ -2,6 +2,10 @@ package services
 
 import (
 	"errors"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -37,6 +41,31 @@ func (s *StudentService) History(registration string) (*models.Student, []models
 	return &student, records, nil
 }
 
+// UpdateContact grava o e-mail de contato do aluno, usado nas notificações.
+// Vazio remove o contato.
+func (s *StudentService) UpdateContact(registration, email string) (*models.Student, error) {
+	email = strings.TrimSpace(email)
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return nil, Invalid("e-mail inválido")
+		}
+	}
+
+	var student models.Student
+	if err := s.db.Preload("Course").Where("registration = ?", registration).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Aluno não encontrado")
+		}
+		return nil, err
+	}
+
+	if err := s.db.Model(&student).Update("email", email).Error; err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
 // latestStatus devolve o enquadramento do aluno no semestre mais recente
 // (maior código). Base da elegibilidade PAE/PIC quando o plano mira
 // períodos futuros ainda não importados (RN18). Retorna "" se o aluno não
@@ -56,3 +85,144 @@ func latestStatus(db *gorm.DB, studentID uint) (string, error) {
 	}
 	return record.Status, nil
 }
+
+// Tipos de evento da linha do tempo do aluno.
+const (
+	TimelineAction        = "action"
+	TimelineStatusChange  = "status_change"
+	TimelinePlanSubmitted = "plan_submitted"
+)
+
+// TimelineEntry é um evento do acompanhamento do aluno, independente do
+// semestre: ação registrada, mudança de enquadramento entre importações ou
+// plano de integralização enviado. Modelo de leitura; as tags JSON definem
+// o contrato.
+type TimelineEntry struct {
+	Type         string    `json:"type"`
+	Date         time.Time `json:"date"`
+	SemesterID   uint      `json:"semester_id"`
+	SemesterCode string    `json:"semester_code"`
+	Description  string    `json:"description"`
+
+	// Preenchidos conforme o tipo do evento.
+	ActionID       uint       `json:"action_id,omitempty"`
+	ResponseDate   *time.Time `json:"response_date,omitempty"`
+	PreviousStatus string     `json:"previous_status,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	PlanID         uint       `json:"plan_id,omitempty"`
+	Disciplines    int        `json:"disciplines,omitempty"`
+}
+
+// Timeline reúne, em ordem cronológica, todo o acompanhamento do aluno em
+// todos os semestres: ações, mudanças de enquadramento (cada registro
+// importado cujo status difere do semestre anterior, datado pelo início do
+// semestre — não pela importação, que pode ter sido feita anos depois com
+// planilhas antigas) e planos enviados. Quando limit > 0 a lista é
+// paginada e o total é devolvido; caso contrário total é -1. A paginação
+// é feita em memória, sobre os eventos de um único aluno (algumas dezenas:
+// um registro e poucas ações e planos por semestre).
+func (s *StudentService) Timeline(registration string, limit, offset int) ([]TimelineEntry, int64, error) {
+	var student models.Student
+	if err := s.db.Where("registration = ?", registration).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, NotFound("Aluno não encontrado")
+		}
+		return nil, 0, err
+	}
+
+	var entries []TimelineEntry
+
+	var actions []models.StudentAction
+	if err := s.db.Preload("Semester").
+		Where("student_id = ?", student.ID).
+		Find(&actions).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, a := range actions {
+		entries = append(entries, TimelineEntry{
+			Type:         TimelineAction,
+			Date:         a.ActionDate,
+			SemesterID:   a.SemesterID,
+			SemesterCode: a.Semester.Code,
+			Description:  a.Description,
+			ActionID:     a.ID,
+			ResponseDate: a.ResponseDate,
+		})
+	}
+
+	var records []models.AcademicRecord
+	if err := s.db.Preload("Semester").
+		Joins("JOIN semesters ON semesters.id = academic_records.semester_id").
+		Where("student_id = ?", student.ID).
+		Order("semesters.code asc").
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	previous := ""
+	for _, r := range records {
+		if r.Status != previous {
+			date, ok := semesterStart(r.Semester.Code)
+			if !ok {
+				date = r.CreatedAt
+			}
+			entries = append(entries, TimelineEntry{
+				Type:           TimelineStatusChange,
+				Date:           date,
+				SemesterID:     r.SemesterID,
+				SemesterCode:   r.Semester.Code,
+				Description:    statusChangeDescription(previous, r.Status),
+				PreviousStatus: previous,
+				Status:         r.Status,
+			})
+		}
+		previous = r.Status
+	}
+
+	var plans []models.StudyPlan
+	if err := s.db.Preload("Semester").Preload("Disciplines").
+		Where("student_id = ?", student.ID).
+		Find(&plans).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, p := range plans {
+		entries = append(entries, TimelineEntry{
+			Type:         TimelinePlanSubmitted,
+			Date:         p.CreatedAt,
+			SemesterID:   p.SemesterID,
+			SemesterCode: p.Semester.Code,
+			Description:  "Plano de integralização enviado para " + p.Semester.Code,
+			PlanID:       p.ID,
+			Disciplines:  len(p.Disciplines),
+		})
+	}
+
+	// Empates de data (ação registrada no primeiro dia do semestre) seguem
+	// a ordem dos semestres.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].SemesterCode < entries[j].SemesterCode
+	})
+
+	total := int64(-1)
+	if limit > 0 {
+		total = int64(len(entries))
+		if offset >= len(entries) {
+			return []TimelineEntry{}, total, nil
+		}
+		end := min(offset+limit, len(entries))
+		entries = entries[offset:end]
+	}
+	if entries == nil {
+		entries = []TimelineEntry{}
+	}
+	return entries, total, nil
+}
+
+func statusChangeDescription(previous, status string) string {
+	if previous == "" {
+		return "Enquadramento inicial: " + status
+	}
+	return "Enquadramento alterado de " + previous + " para " + status
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestTimelineMergesSemestersChronologically(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewStudentService(db)
+
+	student := seedStudentWithStatus(t, db, "2022001", "2024/1", models.StatusRegular)
+
+	// Mesmo status em 2024/2 (sem mudança) e PAE em 2025/1.
+	for _, tc := range []struct{ code, status string }{
+		{"2024/2", models.StatusRegular},
+		{"2025/1", models.StatusPAE},
+	} {
+		var sem models.Semester
+		db.FirstOrCreate(&sem, models.Semester{Code: tc.code})
+		db.Create(&models.AcademicRecord{StudentID: student.ID, SemesterID: sem.ID, Status: tc.status})
+	}
+
+	var sem20251 models.Semester
+	db.Where("code = ?", "2025/1").First(&sem20251)
+	later := time.Now().Add(time.Hour)
+	db.Create(&models.StudentAction{
+		StudentID: student.ID, SemesterID: sem20251.ID,
+		ActionDate: later, Description: "Reunião com o aluno",
+	})
+	// Ação de 2024/2, anterior à importação: fica entre os enquadramentos,
+	// que valem pela data do semestre e não pela importação (agora).
+	var sem20242 models.Semester
+	db.Where("code = ?", "2024/2").First(&sem20242)
+	db.Create(&models.StudentAction{
+		StudentID: student.ID, SemesterID: sem20242.ID,
+		ActionDate: time.Date(2024, 9, 10, 0, 0, 0, 0, time.Local), Description: "Contato por e-mail",
+	})
+
+	entries, total, err := svc.Timeline("2022001", 0, 0)
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	if total != -1 {
+		t.Errorf("sem limit o total deve ser -1; obtive %d", total)
+	}
+	// Enquadramento inicial (2024/1), ação de 2024/2, mudança para PAE
+	// (2025/1) e a ação recente.
+	if len(entries) != 4 {
+		t.Fatalf("esperava 4 eventos; obtive %d: %+v", len(entries), entries)
+	}
+	if entries[0].Type != TimelineStatusChange || entries[0].SemesterCode != "2024/1" {
+		t.Errorf("primeiro evento deveria ser o enquadramento inicial: %+v", entries[0])
+	}
+	if entries[1].Type != TimelineAction || entries[1].SemesterCode != "2024/2" {
+		t.Errorf("segundo evento deveria ser a ação de 2024/2: %+v", entries[1])
+	}
+	if entries[2].Status != models.StatusPAE || entries[2].PreviousStatus != models.StatusRegular ||
+		!entries[2].Date.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("terceiro evento deveria ser a mudança para PAE, datada pelo semestre: %+v", entries[2])
+	}
+	if entries[3].Type != TimelineAction {
+		t.Errorf("último evento deveria ser a ação: %+v", entries[3])
+	}
+
+	page, total, err := svc.Timeline("2022001", 3, 3)
+	if err != nil {
+		t.Fatalf("Timeline paginada: %v", err)
+	}
+	if total != 4 || len(page) != 1 || page[0].Type != TimelineAction {
+		t.Errorf("página 2 deveria trazer só a ação (total 4); obtive total=%d %+v", total, page)
+	}
+}
+
+func TestTimelineUnknownStudent(t *testing.T) {
+	db := newTestDB(t)
+	if _, _, err := NewStudentService(db).Timeline("9999999", 0, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("matrícula inexistente deve dar ErrNotFound; obtive %v", err)
+	}
+}
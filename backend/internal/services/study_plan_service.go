@@ -2,6 +2,8 @@ package services
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -106,7 +108,10 @@ func (s *StudyPlanService) Create(registration string, semesterID uint, discipli
 			}
 			return err
 		}
-		return replaceDisciplines(tx, &plan, disciplineIDs)
+		if err := replaceDisciplines(tx, &plan, disciplineIDs); err != nil {
+			return err
+		}
+		return publishPlanSubmitted(tx, student, &plan, false)
 	})
 	if err != nil {
 		return nil, err
@@ -137,7 +142,74 @@ func (s *StudyPlanService) Update(registration string, semesterID uint, discipli
 	}
 
 	if err := s.db.Transaction(func(tx *gorm.DB) error {
-		return replaceDisciplines(tx, &plan, disciplineIDs)
+		if err := replaceDisciplines(tx, &plan, disciplineIDs); err != nil {
+			return err
+		}
+		// Editar o plano atende a uma eventual devolução da coordenação.
+		if err := tx.Model(&plan).Updates(map[string]any{"returned_at": nil, "return_note": ""}).Error; err != nil {
+			return err
+		}
+		return publishPlanSubmitted(tx, student, &plan, true)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.load(plan.ID)
+}
+
+// MaxReturnNote limita a justificativa da devolução do plano.
+const MaxReturnNote = 500
+
+// Return devolve o plano ao aluno para ajustes, com a justificativa da
+// coordenação. Só faz sentido enquanto a rodada do plano está aberta — é
+// quando o aluno consegue editá-lo. Os avisos no app e por e-mail são
+// gravados na mesma transação.
+func (s *StudyPlanService) Return(registration string, semesterID uint, note string) (*models.StudyPlan, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, Invalid("informe o motivo da devolução")
+	}
+	if len(note) > MaxReturnNote {
+		return nil, Invalid("O motivo deve ter no máximo 500 caracteres")
+	}
+
+	student, err := s.findStudent(registration)
+	if err != nil {
+		return nil, err
+	}
+
+	round, err := s.rounds.currentOrNil()
+	if err != nil {
+		return nil, err
+	}
+	if round == nil || !isTargetSemester(round, semesterID) {
+		return nil, Forbidden("Só é possível devolver planos da rodada aberta")
+	}
+
+	var plan models.StudyPlan
+	if err := s.db.Preload("Semester").
+		Where("student_id = ? AND semester_id = ?", student.ID, semesterID).
+		First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Plano não encontrado")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&plan).Updates(map[string]any{"returned_at": now, "return_note": note}).Error; err != nil {
+			return err
+		}
+		if err := notify(tx, StudentRecipient(student.ID), NotifyPlanReturned,
+			"Plano de "+plan.Semester.Code+" devolvido para ajustes", note); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, EmailPlanReturned, student.Email, map[string]any{
+			"StudentName":  student.Name,
+			"SemesterCode": plan.Semester.Code,
+			"Note":         note,
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -145,6 +217,26 @@ func (s *StudyPlanService) Update(registration string, semesterID uint, discipli
 	return s.load(plan.ID)
 }
 
+// publishPlanSubmitted publica plan.submitted com as disciplinas já
+// associadas ao plano (após replaceDisciplines).
+func publishPlanSubmitted(tx *gorm.DB, student *models.Student, plan *models.StudyPlan, updated bool) error {
+	var semester models.Semester
+	if err := tx.First(&semester, plan.SemesterID).Error; err != nil {
+		return err
+	}
+	codes := make([]string, len(plan.Disciplines))
+	for i, d := range plan.Disciplines {
+		codes[i] = d.Code
+	}
+	return publishEvent(tx, EventPlanSubmitted, map[string]any{
+		"plan_id":      plan.ID,
+		"registration": student.Registration,
+		"semester":     semester.Code,
+		"disciplines":  codes,
+		"updated":      updated,
+	})
+}
+
 func replaceDisciplines(tx *gorm.DB, plan *models.StudyPlan, ids []uint) error {
 	var disciplines []models.Discipline
 	if len(ids) > 0 {
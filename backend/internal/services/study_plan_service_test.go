@@ -11,7 +11,7 @@ import (
 // dos semestres-alvo.
 func openRoundFor(t *testing.T, svc *PlanRoundService, p1, p2 string) *models.PlanRound {
 	t.Helper()
-	round, err := svc.Open(p1, p2, 1)
+	round, err := svc.Open(p1, p2, nil, 1)
 	if err != nil {
 		t.Fatalf("abrir rodada: %v", err)
 	}
@@ -121,10 +121,50 @@ func TestPlanRoundOpenValidations(t *testing.T) {
 	db := newTestDB(t)
 	rounds := NewPlanRoundService(db)
 
-	if _, err := rounds.Open("", "2026/2", 1); !errors.Is(err, ErrInvalid) {
+	if _, err := rounds.Open("", "2026/2", nil, 1); !errors.Is(err, ErrInvalid) {
 		t.Errorf("período vazio deve dar ErrInvalid; obtive %v", err)
 	}
-	if _, err := rounds.Open("2026/1", "2026/1", 1); !errors.Is(err, ErrInvalid) {
+	if _, err := rounds.Open("2026/1", "2026/1", nil, 1); !errors.Is(err, ErrInvalid) {
 		t.Errorf("períodos iguais devem dar ErrInvalid; obtive %v", err)
 	}
 }
+
+func TestReturnPlanIsClearedByStudentEdit(t *testing.T) {
+	db := newTestDB(t)
+	rounds := NewPlanRoundService(db)
+	plans := NewStudyPlanService(db, rounds)
+	student := seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+	db.Model(student).Update("email", "aluno@ufes.br")
+	round := openRoundFor(t, rounds, "2026/1", "2026/2")
+
+	if _, err := plans.Return("2022001", round.Period1SemesterID, "Inclua Cálculo I"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("devolver plano inexistente deve dar ErrNotFound; obtive %v", err)
+	}
+	if _, err := plans.Create("2022001", round.Period1SemesterID, nil); err != nil {
+		t.Fatalf("criar plano: %v", err)
+	}
+	if _, err := plans.Return("2022001", round.Period1SemesterID, "  "); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("devolução sem motivo deve dar ErrInvalid; obtive %v", err)
+	}
+
+	returned, err := plans.Return("2022001", round.Period1SemesterID, "Inclua Cálculo I")
+	if err != nil {
+		t.Fatalf("Return: %v", err)
+	}
+	if returned.ReturnedAt == nil || returned.ReturnNote != "Inclua Cálculo I" {
+		t.Fatalf("plano deveria estar devolvido: %+v", returned)
+	}
+	var emails int64
+	db.Model(&models.OutboxEmail{}).Where("event = ?", EmailPlanReturned).Count(&emails)
+	if emails != 1 {
+		t.Errorf("esperava 1 e-mail de devolução; obtive %d", emails)
+	}
+
+	edited, err := plans.Update("2022001", round.Period1SemesterID, nil)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if edited.ReturnedAt != nil || edited.ReturnNote != "" {
+		t.Errorf("editar o plano deveria limpar a devolução: %+v", edited)
+	}
+}
@@ -27,9 +27,34 @@ func newTestDB(t *testing.T) *gorm.DB {
 		&models.Discipline{},
 		&models.StudyPlan{},
 		&models.PlanRound{},
+		&models.OutboxAttachment{},
+		&models.OutboxEmail{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Notification{},
+		&models.AdvisorAssignment{},
+		&models.RefreshToken{},
+		&models.AuditLog{},
+		&models.PasswordResetCode{},
+		&models.LoginThrottle{},
+		&models.RecoveryCode{},
+		&models.TwoFactorChallenge{},
+		&models.Setting{},
+		&models.OIDCLoginState{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.APIKey{},
+		&models.UserInvitation{},
+		&models.TriageRule{},
+		&models.ReportView{},
+		&models.ReportSchedule{},
+		&models.ReportScheduleRun{},
 	); err != nil {
 		t.Fatalf("migração: %v", err)
 	}
+	if err := NewRoleService(db).EnsureDefaults(); err != nil {
+		t.Fatalf("papéis padrão: %v", err)
+	}
 	return db
 }
 
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Ações de auditoria das regras de triagem.
+const (
+	AuditTriageRuleCreated = "triage_rule.created"
+	AuditTriageRuleDeleted = "triage_rule.deleted"
+)
+
+// Limites aceitos para os limiares; valores fora deles indicam erro de
+// digitação, não uma regulamentação real.
+const (
+	maxTriageLocks   = 20
+	maxTriageNoHours = 20
+	maxTriagePending = 100
+)
+
+// semesterCodePattern é o formato dos códigos de semestre ("2025/1").
+var semesterCodePattern = regexp.MustCompile(`^\d{4}/\d$`)
+
+// TriageRuleService mantém as versões dos limiares de triagem, globais e
+// por curso, editadas pelo administrador.
+type TriageRuleService struct {
+	db *gorm.DB
+}
+
+func NewTriageRuleService(db *gorm.DB) *TriageRuleService { return &TriageRuleService{db: db} }
+
+type TriageRuleInput struct {
+	CourseID      *uint
+	EffectiveFrom string
+	TriageThresholds
+}
+
+func triageRuleTarget(id uint) string { return "triage_rule:" + strconv.FormatUint(uint64(id), 10) }
+
+// List devolve as versões, globais primeiro e, em cada alcance, da mais
+// recente para a mais antiga. Com courseID, apenas as daquele curso.
+func (s *TriageRuleService) List(courseID *uint) ([]models.TriageRule, error) {
+	q := s.db.Preload("Course").Preload("CreatedBy", authorName)
+	if courseID != nil {
+		q = q.Where("course_id = ?", *courseID)
+	}
+	var rules []models.TriageRule
+	if err := q.Order("course_id IS NOT NULL, course_id, effective_from desc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Create cadastra uma nova versão. Cada alcance (global ou curso) tem no
+// máximo uma versão por semestre de início.
+func (s *TriageRuleService) Create(in TriageRuleInput, actor Actor) (*models.TriageRule, error) {
+	code := strings.TrimSpace(in.EffectiveFrom)
+	if !semesterCodePattern.MatchString(code) {
+		return nil, Invalid("effective_from deve ser um código de semestre (ex.: 2025/1)")
+	}
+	if err := validateThresholds(in.TriageThresholds); err != nil {
+		return nil, err
+	}
+	if in.CourseID != nil {
+		var n int64
+		if err := s.db.Model(&models.Course{}).Where("id = ?", *in.CourseID).Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, NotFound("Curso não encontrado")
+		}
+	}
+
+	rule := models.TriageRule{
+		CourseID:                 in.CourseID,
+		EffectiveFrom:            code,
+		MaxLocks:                 in.MaxLocks,
+		MaxSemestersNoHours:      in.MaxSemestersNoHours,
+		NearGraduationMaxPending: in.NearGraduationMaxPending,
+		CreatedByUserID:          actor.UserID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		dup := tx.Model(&models.TriageRule{}).Where("effective_from = ?", code)
+		if in.CourseID == nil {
+			dup = dup.Where("course_id IS NULL")
+		} else {
+			dup = dup.Where("course_id = ?", *in.CourseID)
+		}
+		var n int64
+		if err := dup.Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return Conflict("Já existe uma versão desta regra a partir de " + code)
+		}
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditTriageRuleCreated, triageRuleTarget(rule.ID), describeRule(rule))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Course").Preload("CreatedBy", authorName).First(&rule, rule.ID).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Delete remove uma versão; a anterior do mesmo alcance (ou o padrão)
+// volta a valer a partir do semestre dela.
+func (s *TriageRuleService) Delete(id uint, actor Actor) error {
+	var rule models.TriageRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NotFound("Regra de triagem não encontrada")
+		}
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&rule).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditTriageRuleDeleted, triageRuleTarget(rule.ID), describeRule(rule))
+	})
+}
+
+// Effective devolve os limiares vigentes no semestre para o curso (nil:
+// a regra global), aplicando a mesma precedência dos relatórios.
+func (s *TriageRuleService) Effective(courseID *uint, semesterCode string) (TriageThresholds, error) {
+	if !semesterCodePattern.MatchString(semesterCode) {
+		return TriageThresholds{}, Invalid("semestre deve ser um código (ex.: 2025/1)")
+	}
+	var rule models.TriageRule
+	if courseID != nil {
+		err := s.db.Where("course_id = ? AND effective_from <= ?", *courseID, semesterCode).
+			Order("effective_from desc").First(&rule).Error
+		if err == nil {
+			return thresholdsOf(rule), nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return TriageThresholds{}, err
+		}
+	}
+	err := s.db.Where("course_id IS NULL AND effective_from <= ?", semesterCode).
+		Order("effective_from desc").First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultTriage, nil
+	}
+	if err != nil {
+		return TriageThresholds{}, err
+	}
+	return thresholdsOf(rule), nil
+}
+
+func validateThresholds(t TriageThresholds) error {
+	if t.MaxLocks < 0 || t.MaxLocks > maxTriageLocks {
+		return Invalid(fmt.Sprintf("max_locks deve estar entre 0 e %d", maxTriageLocks))
+	}
+	if t.MaxSemestersNoHours < 0 || t.MaxSemestersNoHours > maxTriageNoHours {
+		return Invalid(fmt.Sprintf("max_semesters_no_hours deve estar entre 0 e %d", maxTriageNoHours))
+	}
+	if t.NearGraduationMaxPending < 0 || t.NearGraduationMaxPending > maxTriagePending {
+		return Invalid(fmt.Sprintf("near_graduation_max_pending deve estar entre 0 e %d", maxTriagePending))
+	}
+	return nil
+}
+
+// describeRule resume a versão no detalhe da auditoria.
+func describeRule(r models.TriageRule) string {
+	scope := "global"
+	if r.CourseID != nil {
+		scope = "curso " + strconv.FormatUint(uint64(*r.CourseID), 10)
+	}
+	return fmt.Sprintf("%s a partir de %s: trancamentos > %d, semestres sem CH > %d, pendentes ≤ %d",
+		scope, r.EffectiveFrom, r.MaxLocks, r.MaxSemestersNoHours, r.NearGraduationMaxPending)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestTriageRuleVersionsAndPrecedence(t *testing.T) {
+	db := newTestDB(t)
+	rules := NewTriageRuleService(db)
+	reports := NewReportService(db)
+	indicators := NewIndicatorsService(db)
+	admin := Actor{UserID: 1}
+
+	// Um aluno com dois trancamentos em cada curso, nos dois semestres.
+	other := models.Course{Code: 2, Name: "Outro Curso"}
+	db.Create(&other)
+	semesterIDs := map[string]string{}
+	for i, code := range []string{"2024/1", "2025/1"} {
+		a := seedStudentWithStatus(t, db, "A"+strconv.Itoa(i), code, models.StatusRegular)
+		b := models.Student{Registration: "B" + strconv.Itoa(i), Name: "Aluno B", CourseID: other.ID}
+		db.Create(&b)
+		var sem models.Semester
+		db.Where("code = ?", code).First(&sem)
+		db.Create(&models.AcademicRecord{StudentID: b.ID, SemesterID: sem.ID, Status: models.StatusRegular})
+		db.Model(&models.AcademicRecord{}).Where("student_id IN ?", []uint{a.ID, b.ID}).Update("locks", 2)
+		semesterIDs[code] = strconv.FormatUint(uint64(sem.ID), 10)
+	}
+	critical := func(code string) int {
+		t.Helper()
+		records, _, err := reports.Records(RecordsFilter{SemesterID: semesterIDs[code], CriticalOnly: true})
+		if err != nil {
+			t.Fatalf("Records: %v", err)
+		}
+		dash, err := indicators.Dashboard(semesterIDs[code], AllCourses())
+		if err != nil {
+			t.Fatalf("Dashboard: %v", err)
+		}
+		if len(dash.CriticalStudents) != len(records) {
+			t.Errorf("%s: painel (%d) e relatório (%d) divergem", code, len(dash.CriticalStudents), len(records))
+		}
+		return len(records)
+	}
+
+	if n := critical("2024/1"); n != 2 {
+		t.Errorf("sem regras, limiar padrão: %d críticos; esperado 2", n)
+	}
+
+	// Nova regra global a partir de 2025/1 tolera dois trancamentos.
+	if _, err := rules.Create(TriageRuleInput{EffectiveFrom: "2025/1", TriageThresholds: TriageThresholds{
+		MaxLocks: 2, MaxSemestersNoHours: 1, NearGraduationMaxPending: 6,
+	}}, admin); err != nil {
+		t.Fatalf("Create global: %v", err)
+	}
+	if n := critical("2024/1"); n != 2 {
+		t.Errorf("2024/1 continua no limiar padrão: %d críticos; esperado 2", n)
+	}
+	if n := critical("2025/1"); n != 0 {
+		t.Errorf("2025/1 com a nova regra global: %d críticos; esperado 0", n)
+	}
+
+	// A regra do curso prevalece sobre a global.
+	if _, err := rules.Create(TriageRuleInput{CourseID: &other.ID, EffectiveFrom: "2024/1", TriageThresholds: TriageThresholds{
+		MaxLocks: 1, MaxSemestersNoHours: 1, NearGraduationMaxPending: 4,
+	}}, admin); err != nil {
+		t.Fatalf("Create do curso: %v", err)
+	}
+	if n := critical("2025/1"); n != 1 {
+		t.Errorf("2025/1 com regra do curso: %d críticos; esperado 1", n)
+	}
+	if got, _ := rules.Effective(&other.ID, "2025/1"); got.NearGraduationMaxPending != 4 {
+		t.Errorf("vigente do curso: %+v", got)
+	}
+	if got, _ := rules.Effective(nil, "2024/2"); got != DefaultTriage {
+		t.Errorf("antes da primeira versão global valem os limiares padrão: %+v", got)
+	}
+
+	if _, err := rules.Create(TriageRuleInput{EffectiveFrom: "2025/1"}, admin); !errors.Is(err, ErrConflict) {
+		t.Errorf("versão repetida deveria ser conflito; obtive %v", err)
+	}
+	if _, err := rules.Create(TriageRuleInput{EffectiveFrom: "2025-1"}, admin); !errors.Is(err, ErrInvalid) {
+		t.Errorf("código de semestre inválido deveria ser recusado; obtive %v", err)
+	}
+	if _, err := rules.Create(TriageRuleInput{EffectiveFrom: "2026/1", TriageThresholds: TriageThresholds{MaxLocks: -1}}, admin); !errors.Is(err, ErrInvalid) {
+		t.Errorf("limiar negativo deveria ser recusado; obtive %v", err)
+	}
+
+	list, err := rules.List(nil)
+	if err != nil || len(list) != 2 || list[0].CourseID != nil {
+		t.Fatalf("List: %v %+v", err, list)
+	}
+	if err := rules.Delete(list[0].ID, admin); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if n := critical("2025/1"); n != 2 {
+		t.Errorf("removida a versão global, 2025/1 volta ao padrão: %d críticos; esperado 2", n)
+	}
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adamanagement/backend/internal/models"
+)
+
+// totpIssuer identifica o sistema no aplicativo autenticador.
+const totpIssuer = "ADAManagement"
+
+// RecoveryCodeCount é a quantidade de códigos de recuperação gerados.
+const RecoveryCodeCount = 10
+
+// settingRequireAdmin2FA torna a verificação em duas etapas obrigatória
+// para o papel admin.
+const settingRequireAdmin2FA = "security.require_admin_two_factor"
+
+const (
+	AuditTwoFactorEnabled  = "user.two_factor.enabled"
+	AuditTwoFactorDisabled = "user.two_factor.disabled"
+	AuditTwoFactorReset    = "user.two_factor.reset"
+	AuditRecoveryRegen     = "user.two_factor.recovery_codes"
+	AuditRecoveryUsed      = "user.two_factor.recovery_used"
+	AuditSecurityPolicy    = "security.policy.updated"
+)
+
+const twoFactorRequiredReason = "A verificação em duas etapas é obrigatória para administradores"
+
+var errInvalidTOTP = Unauthorized("código de verificação inválido")
+
+// userTarget é o alvo de auditoria de um usuário da coordenação.
+func userTarget(email string) string { return "user:" + email }
+
+// TwoFactorService cuida da verificação em duas etapas (TOTP, RFC 6238) dos
+// usuários da coordenação: configuração, ativação, códigos de recuperação e
+// a política que a torna obrigatória para administradores.
+type TwoFactorService struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorService(db *gorm.DB) *TwoFactorService { return &TwoFactorService{db: db} }
+
+// TOTPSetup é o segredo recém-gerado e o URI otpauth:// para o QR code.
+type TOTPSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatus resume a situação do usuário.
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// SecurityPolicy é a política de autenticação editável pelo administrador.
+type SecurityPolicy struct {
+	RequireAdminTwoFactor bool `json:"require_admin_two_factor"`
+}
+
+func (s *TwoFactorService) findUser(id uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Usuário não encontrado")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Status devolve a situação da verificação em duas etapas do usuário.
+func (s *TwoFactorService) Status(userID uint) (*TwoFactorStatus, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.requiredFor(user)
+	if err != nil {
+		return nil, err
+	}
+	status := &TwoFactorStatus{Enabled: user.TOTPEnabledAt != nil, EnabledAt: user.TOTPEnabledAt, Required: required}
+	if err := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesLeft).Error; err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Setup gera um novo segredo para o usuário ainda sem verificação ativa.
+// O segredo só passa a valer no login depois de confirmado em Enable.
+func (s *TwoFactorService) Setup(userID uint) (*TOTPSetup, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, Conflict("A verificação em duas etapas já está ativa")
+	}
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(user).Updates(map[string]any{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		return nil, err
+	}
+	return &TOTPSetup{Secret: secret, URI: totpURI(totpIssuer, user.Email, secret)}, nil
+}
+
+// Enable confirma o segredo com um código do aplicativo, ativa a
+// verificação e devolve os códigos de recuperação — única vez em que são
+// exibidos.
+func (s *TwoFactorService) Enable(userID uint, code string, actor Actor) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, Conflict("A verificação em duas etapas já está ativa")
+	}
+	if user.TOTPSecret == "" {
+		return nil, Invalid("gere o segredo antes de ativar")
+	}
+	step, ok := verifyTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, errInvalidTOTP
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(user).Updates(map[string]any{"totp_enabled_at": now, "totp_last_step": step}).Error; err != nil {
+			return err
+		}
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditTwoFactorEnabled, userTarget(user.Email), "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable desativa a verificação do próprio usuário mediante um código
+// válido (do aplicativo ou de recuperação). Administradores não podem
+// desativá-la enquanto a política a exigir.
+func (s *TwoFactorService) Disable(userID uint, code string, actor Actor) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return Conflict("A verificação em duas etapas não está ativa")
+	}
+	required, err := s.requiredFor(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return Forbidden(twoFactorRequiredReason)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := verifySecondFactor(tx, user, code, actor); err != nil {
+			return err
+		}
+		if err := clearTwoFactor(tx, user.ID); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditTwoFactorDisabled, userTarget(user.Email), "")
+	})
+}
+
+// RegenerateRecoveryCodes invalida os códigos de recuperação restantes e
+// gera novos, mediante um código do aplicativo.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string, actor Actor) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, Conflict("A verificação em duas etapas não está ativa")
+	}
+	step, ok := verifyTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, errInvalidTOTP
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("totp_last_step", step).Error; err != nil {
+			return err
+		}
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditRecoveryRegen, userTarget(user.Email), "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Reset remove a verificação de outro usuário (aparelho e códigos
+// perdidos). Ação do administrador; o usuário volta a configurá-la no
+// próximo login se a política exigir.
+func (s *TwoFactorService) Reset(targetID uint, actor Actor) error {
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil && user.TOTPSecret == "" {
+		return Conflict("O usuário não tem verificação em duas etapas")
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearTwoFactor(tx, user.ID); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditTwoFactorReset, userTarget(user.Email), "")
+	})
+}
+
+// Policy devolve a política de verificação em duas etapas.
+func (s *TwoFactorService) Policy() (*SecurityPolicy, error) {
+	value, err := getSetting(s.db, settingRequireAdmin2FA)
+	if err != nil {
+		return nil, err
+	}
+	return &SecurityPolicy{RequireAdminTwoFactor: value == "true"}, nil
+}
+
+// UpdatePolicy grava a política. Administradores sem verificação ativa
+// passam a configurá-la no próximo login; sessões abertas seguem válidas.
+func (s *TwoFactorService) UpdatePolicy(p SecurityPolicy, actor Actor) (*SecurityPolicy, error) {
+	value := "false"
+	if p.RequireAdminTwoFactor {
+		value = "true"
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := setSetting(tx, settingRequireAdmin2FA, value); err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditSecurityPolicy, settingRequireAdmin2FA, value)
+	}); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// requiredFor indica se a política exige a verificação para o usuário.
+func (s *TwoFactorService) requiredFor(user *models.User) (bool, error) {
+	if user.Role != models.RoleAdmin {
+		return false, nil
+	}
+	p, err := s.Policy()
+	if err != nil {
+		return false, err
+	}
+	return p.RequireAdminTwoFactor, nil
+}
+
+// verifySecondFactor aceita um código do aplicativo ou um código de
+// recuperação ainda não usado (consumido aqui). Roda em tx para que o
+// consumo acompanhe a operação que ele autoriza.
+func verifySecondFactor(tx *gorm.DB, user *models.User, code string, actor Actor) error {
+	if step, ok := verifyTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		// A condição no passo evita que o mesmo código valha em duas
+		// requisições simultâneas.
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvalidTOTP
+		}
+		return nil
+	}
+
+	given := hashToken(normalizeResetCode(code))
+	var candidates []models.RecoveryCode
+	if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Find(&candidates).Error; err != nil {
+		return err
+	}
+	for _, rc := range candidates {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(rc.CodeHash)) != 1 {
+			continue
+		}
+		res := tx.Model(&rc).Where("used_at IS NULL").Update("used_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvalidTOTP
+		}
+		return audit(tx, actor, AuditRecoveryUsed, userTarget(user.Email), "")
+	}
+	return errInvalidTOTP
+}
+
+// replaceRecoveryCodes apaga os códigos do usuário e grava um novo
+// conjunto, devolvendo os valores em claro.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, RecoveryCodeCount)
+	rows := make([]models.RecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		code, err := newResetCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeResetCode(code))}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func clearTwoFactor(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"totp_secret":     "",
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// getSetting lê uma configuração; ausente equivale a "".
+func getSetting(db *gorm.DB, key string) (string, error) {
+	var setting models.Setting
+	if err := db.Where("key = ?", key).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+func setSetting(tx *gorm.DB, key, value string) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.Setting{Key: key, Value: value}).Error
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"adamanagement/backend/internal/models"
+)
+
+// currentCode gera o código do aplicativo para o passo atual + offset.
+func currentCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totpCode(secret, totpStep(time.Now())+offset)
+	if err != nil {
+		t.Fatalf("totpCode: %v", err)
+	}
+	return code
+}
+
+// Vetores da RFC 6238 (apêndice B, SHA-1), truncados para 6 dígitos.
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"}
+	for unix, want := range cases {
+		got, err := totpCode(secret, totpStep(time.Unix(unix, 0)))
+		if err != nil || got != want {
+			t.Errorf("T=%d: obtive %q (err=%v); esperado %q", unix, got, err, want)
+		}
+	}
+	if !strings.HasPrefix(totpURI(totpIssuer, "ana@ufes.br", secret), "otpauth://totp/ADAManagement:ana@ufes.br?") {
+		t.Errorf("URI de provisionamento inesperado: %s", totpURI(totpIssuer, "ana@ufes.br", secret))
+	}
+}
+
+func TestTwoStepLoginWithCodeAndRecoveryCode(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	svc := NewTwoFactorService(db)
+	ana, _ := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser)
+
+	setup, err := svc.Setup(ana.ID)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if _, err := svc.Enable(ana.ID, "abcdef", Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("código errado não deveria ativar; obtive %v", err)
+	}
+	recovery, err := svc.Enable(ana.ID, currentCode(t, setup.Secret, 0), Actor{UserID: ana.ID})
+	if err != nil || len(recovery) != RecoveryCodeCount {
+		t.Fatalf("Enable: %d códigos, %v", len(recovery), err)
+	}
+
+	first, err := auth.Login("ana@ufes.br", "segredo", Actor{})
+	if err != nil || first.Tokens != nil || first.Challenge == "" || first.EnrollmentRequired {
+		t.Fatalf("senha correta deveria devolver só o desafio: %+v, %v", first, err)
+	}
+	if _, err := jwt.ParseWithClaims(first.Challenge, &Claims{}, func(*jwt.Token) (any, error) {
+		return []byte(testSecret), nil
+	}); err == nil {
+		t.Fatal("o desafio não pode valer como token de acesso")
+	}
+
+	// O código já usado na ativação não vale de novo.
+	if _, err := auth.VerifyTwoFactor(first.Challenge, currentCode(t, setup.Secret, 0), Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("código reaproveitado deveria ser recusado; obtive %v", err)
+	}
+	done, err := auth.VerifyTwoFactor(first.Challenge, strings.ToLower(recovery[0]), Actor{})
+	if err != nil || done.Tokens == nil {
+		t.Fatalf("código de recuperação deveria concluir o login: %+v, %v", done, err)
+	}
+	// Desafio concluído não abre outra sessão, mesmo com código válido.
+	if _, err := auth.VerifyTwoFactor(first.Challenge, recovery[1], Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("desafio reapresentado deveria ser recusado; obtive %v", err)
+	}
+	second, _ := auth.Login("ana@ufes.br", "segredo", Actor{})
+	if _, err := auth.VerifyTwoFactor(second.Challenge, recovery[0], Actor{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("código de recuperação é de uso único; obtive %v", err)
+	}
+
+	status, _ := svc.Status(ana.ID)
+	if !status.Enabled || status.RecoveryCodesLeft != RecoveryCodeCount-1 {
+		t.Errorf("status inesperado: %+v", status)
+	}
+}
+
+func TestAdminPolicyForcesEnrollmentAtLogin(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testSecret)
+	svc := NewTwoFactorService(db)
+	admin, _ := auth.CreateUser("Admin", "admin@ufes.br", "segredo", models.RoleAdmin)
+	if _, err := auth.CreateUser("Ana", "ana@ufes.br", "segredo", models.RoleUser); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := svc.UpdatePolicy(SecurityPolicy{RequireAdminTwoFactor: true}, Actor{UserID: admin.ID}); err != nil {
+		t.Fatalf("UpdatePolicy: %v", err)
+	}
+	if res, err := auth.Login("ana@ufes.br", "segredo", Actor{}); err != nil || res.Tokens == nil {
+		t.Fatalf("a política vale só para admin: %+v, %v", res, err)
+	}
+
+	res, err := auth.Login("admin@ufes.br", "segredo", Actor{})
+	if err != nil || res.Tokens != nil || !res.EnrollmentRequired {
+		t.Fatalf("admin sem 2FA deveria ser levado à configuração: %+v, %v", res, err)
+	}
+	setup, err := auth.EnrollTwoFactor(res.Challenge)
+	if err != nil {
+		t.Fatalf("EnrollTwoFactor: %v", err)
+	}
+	done, err := auth.VerifyTwoFactor(res.Challenge, currentCode(t, setup.Secret, 0), Actor{})
+	if err != nil || done.Tokens == nil || len(done.RecoveryCodes) != RecoveryCodeCount {
+		t.Fatalf("a confirmação deveria ativar e abrir a sessão: %+v, %v", done, err)
+	}
+
+	if err := svc.Disable(admin.ID, currentCode(t, setup.Secret, 1), Actor{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("admin não pode desativar sob a política; obtive %v", err)
+	}
+	if err := svc.Reset(admin.ID, Actor{UserID: 1}); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if status, _ := svc.Status(admin.ID); status.Enabled || status.RecoveryCodesLeft != 0 {
+		t.Errorf("reset deveria remover segredo e códigos: %+v", status)
+	}
+}
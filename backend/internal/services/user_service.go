@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -15,13 +16,32 @@ type UserService struct {
 
 func NewUserService(db *gorm.DB) *UserService { return &UserService{db: db} }
 
+// authorName restringe o preload de autoria (quem abriu a rodada, atribuiu
+// o orientador, criou a regra…) às colunas exibidas.
+func authorName(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }
+
 type UserListFilter struct {
 	Name  string
 	Email string
 	Role  string
+	// Status filtra por situação da conta: "active" ou "inactive".
+	Status string
+	Sort   string // chaves de userSortKeys; padrão: nome
+	Page   Page
+}
+
+var userSortKeys = []sortKey[models.User]{
+	{"name", "name", func(u models.User) any { return u.Name }},
+	{"email", "email", func(u models.User) any { return u.Email }},
+	{"role", "role", func(u models.User) any { return u.Role }},
+	{"id", "id", func(u models.User) any { return u.ID }},
 }
 
-func (s *UserService) List(f UserListFilter) ([]models.User, error) {
+func (s *UserService) List(f UserListFilter) ([]models.User, PageInfo, error) {
+	terms, err := parseSort(f.Sort, "name", userSortKeys)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
 	q := s.db.Model(&models.User{})
 	if f.Name != "" {
 		q = q.Where("name LIKE ?", "%"+f.Name+"%")
@@ -32,12 +52,16 @@ func (s *UserService) List(f UserListFilter) ([]models.User, error) {
 	if f.Role != "" {
 		q = q.Where("role = ?", f.Role)
 	}
-
-	var users []models.User
-	if err := q.Omit("password").Find(&users).Error; err != nil {
-		return nil, err
+	switch f.Status {
+	case "":
+	case "active":
+		q = q.Where("deactivated_at IS NULL")
+	case "inactive":
+		q = q.Where("deactivated_at IS NOT NULL")
+	default:
+		return nil, PageInfo{}, Invalid("status inválido: use active ou inactive")
 	}
-	return users, nil
+	return paginate(q.Omit("password").Preload("Courses"), terms, f.Page)
 }
 
 type UserUpdateInput struct {
@@ -47,9 +71,10 @@ type UserUpdateInput struct {
 	Role     string
 }
 
-// Update aplica as regras de edição: um usuário comum só edita a si
-// mesmo e não altera papel; o Admin Master (ID = 1) não pode ser
-// rebaixado (RN01).
+// Update aplica as regras de edição: sem a permissão users.manage o
+// usuário só edita a si mesmo e não altera papel; com ela, só edita
+// contas e atribui papéis cujas permissões estejam todas no seu próprio
+// papel; o Admin Master (ID = 1) não pode ser rebaixado (RN01).
 func (s *UserService) Update(requesterID uint, requesterRole string, targetID uint, in UserUpdateInput) (*models.User, error) {
 	var user models.User
 	if err := s.db.First(&user, targetID).Error; err != nil {
@@ -59,8 +84,17 @@ func (s *UserService) Update(requesterID uint, requesterRole string, targetID ui
 		return nil, err
 	}
 
-	if requesterRole != "admin" && user.ID != requesterID {
-		return nil, Forbidden("Sem permissão")
+	manager, err := roleHasPermission(s.db, requesterRole, models.PermUsersManage)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != requesterID {
+		if !manager {
+			return nil, Forbidden("Sem permissão")
+		}
+		if err := coversRole(s.db, requesterRole, user.Role); err != nil {
+			return nil, err
+		}
 	}
 
 	if in.Name != "" {
@@ -69,7 +103,8 @@ func (s *UserService) Update(requesterID uint, requesterRole string, targetID ui
 	if in.Email != "" {
 		user.Email = in.Email
 	}
-	if in.Password != "" {
+	passwordChanged := in.Password != ""
+	if passwordChanged {
 		if len(in.Password) < 6 {
 			return nil, Invalid("A senha deve ter pelo menos 6 caracteres")
 		}
@@ -77,19 +112,33 @@ func (s *UserService) Update(requesterID uint, requesterRole string, targetID ui
 		if err != nil {
 			return nil, err
 		}
+		now := time.Now()
 		user.Password = string(hash)
+		user.PasswordChangedAt = &now
 	}
-	if in.Role != "" && requesterRole == "admin" {
-		if in.Role != "user" && in.Role != "admin" {
-			return nil, Invalid("papel inválido: use 'admin' ou 'user'")
+	if in.Role != "" && manager {
+		if err := ensureRole(s.db, in.Role); err != nil {
+			return nil, err
 		}
-		if user.ID == 1 && in.Role != "admin" {
+		if err := coversRole(s.db, requesterRole, in.Role); err != nil {
+			return nil, err
+		}
+		if user.ID == 1 && in.Role != models.RoleAdmin {
 			return nil, Forbidden("O Admin Principal não pode ser rebaixado.")
 		}
 		user.Role = in.Role
 	}
 
-	if err := s.db.Save(&user).Error; err != nil {
+	// Troca de senha encerra todas as sessões do usuário na mesma transação.
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if passwordChanged {
+			return revokeSessions(tx, user.ID, 0, *user.PasswordChangedAt)
+		}
+		return nil
+	}); err != nil {
 		if isUniqueViolation(err) {
 			return nil, Conflict("Este e-mail já está em uso por outro usuário")
 		}
@@ -98,23 +147,85 @@ func (s *UserService) Update(requesterID uint, requesterRole string, targetID ui
 	return &user, nil
 }
 
-// Delete remove o usuário em definitivo (hard delete): o e-mail tem
-// índice único e uma exclusão lógica impediria recadastrá-lo depois.
-func (s *UserService) Delete(requesterID, targetID uint) error {
-	var user models.User
-	if err := s.db.First(&user, targetID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return NotFound("Usuário não encontrado")
+// Ações de auditoria da situação da conta.
+const (
+	AuditUserDeactivated = "user.deactivated"
+	AuditUserReactivated = "user.reactivated"
+)
+
+// Deactivate desativa o usuário no lugar da exclusão: a conta deixa de
+// entrar e todas as sessões são encerradas na mesma transação, mas o
+// registro permanece, de modo que rodadas, ações, atribuições e a trilha
+// de auditoria continuam mostrando quem as fez. Contas de papel acima do
+// de quem age não podem ser desativadas.
+func (s *UserService) Deactivate(targetID uint, actor Actor) (*models.User, error) {
+	user, err := s.find(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 1 {
+		return nil, Forbidden("O Admin Principal não pode ser desativado.")
+	}
+	if user.ID == actor.UserID {
+		return nil, Invalid("Você não pode desativar a si mesmo.")
+	}
+	if err := coversRole(s.db, actor.Role, user.Role); err != nil {
+		return nil, err
+	}
+	if user.DeactivatedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("deactivated_at", now).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, user.ID, 0, now); err != nil {
+			return err
 		}
-		return err
+		return audit(tx, actor, AuditUserDeactivated, userTarget(user.Email), "")
+	})
+	if err != nil {
+		return nil, err
 	}
+	return user, nil
+}
 
-	if user.ID == 1 {
-		return Forbidden("O Admin Principal não pode ser excluído.")
+// Reactivate devolve o acesso ao usuário desativado, com o mesmo papel e
+// os mesmos cursos de antes, desde que o papel dele não esteja acima do
+// de quem age.
+func (s *UserService) Reactivate(targetID uint, actor Actor) (*models.User, error) {
+	user, err := s.find(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := coversRole(s.db, actor.Role, user.Role); err != nil {
+		return nil, err
 	}
-	if user.ID == requesterID {
-		return Invalid("Você não pode deletar a si mesmo.")
+	if user.DeactivatedAt == nil {
+		return user, nil
 	}
 
-	return s.db.Unscoped().Delete(&user).Error
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("deactivated_at", nil).Error; err != nil {
+			return err
+		}
+		return audit(tx, actor, AuditUserReactivated, userTarget(user.Email), "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) find(id uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.Omit("password").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Usuário não encontrado")
+		}
+		return nil, err
+	}
+	return &user, nil
 }
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adamanagement/backend/internal/models"
+)
+
+// Cabeçalhos das entregas de webhook. A assinatura é o HMAC-SHA256 do
+// corpo com o segredo do webhook, em hexadecimal com o prefixo "sha256=".
+const (
+	HeaderWebhookEvent     = "X-ADA-Event"
+	HeaderWebhookDelivery  = "X-ADA-Delivery"
+	HeaderWebhookSignature = "X-ADA-Signature"
+)
+
+type WebhookService struct {
+	db *gorm.DB
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService { return &WebhookService{db: db} }
+
+type WebhookInput struct {
+	URL    string
+	Secret string
+	Events []string
+	Active *bool
+}
+
+func (s *WebhookService) List() ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	if err := s.db.Order("id asc").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// Create cadastra o webhook (ativo por padrão). Sem segredo informado, um
+// aleatório é gerado; o segredo só é devolvido nesta resposta.
+func (s *WebhookService) Create(userID uint, in WebhookInput) (*models.Webhook, error) {
+	if err := validateWebhookURL(in.URL); err != nil {
+		return nil, err
+	}
+	events, err := validateWebhookEvents(in.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+
+	hook := models.Webhook{
+		URL:             strings.TrimSpace(in.URL),
+		Secret:          secret,
+		Events:          strings.Join(events, ","),
+		Active:          in.Active == nil || *in.Active,
+		CreatedByUserID: userID,
+	}
+	if err := s.db.Create(&hook).Error; err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// Update altera apenas os campos informados.
+func (s *WebhookService) Update(id uint, in WebhookInput) (*models.Webhook, error) {
+	hook, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+	if in.URL != "" {
+		if err := validateWebhookURL(in.URL); err != nil {
+			return nil, err
+		}
+		updates["url"] = strings.TrimSpace(in.URL)
+	}
+	if in.Secret != "" {
+		updates["secret"] = in.Secret
+	}
+	if in.Events != nil {
+		events, err := validateWebhookEvents(in.Events)
+		if err != nil {
+			return nil, err
+		}
+		updates["events"] = strings.Join(events, ",")
+	}
+	if in.Active != nil {
+		updates["active"] = *in.Active
+	}
+	if len(updates) == 0 {
+		return nil, Invalid("Nenhum campo fornecido para atualização")
+	}
+
+	if err := s.db.Model(hook).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Delete remove o webhook e o seu registro de entregas.
+func (s *WebhookService) Delete(id uint) error {
+	hook, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(hook).Error
+	})
+}
+
+// Deliveries devolve o registro de entregas do webhook, mais recentes
+// primeiro. Quando limit > 0 a consulta é paginada e o total é calculado;
+// caso contrário total é -1.
+func (s *WebhookService) Deliveries(webhookID uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.find(webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	q := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	total := int64(-1)
+	if limit > 0 {
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+		q = q.Limit(limit).Offset(offset)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := q.Order("id desc").Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver agenda uma nova entrega do mesmo payload, com tentativas
+// zeradas. A entrega original permanece no registro.
+func (s *WebhookService) Redeliver(deliveryID uint) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := s.db.First(&original, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Entrega não encontrada")
+		}
+		return nil, err
+	}
+	if _, err := s.find(original.WebhookID); err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:      original.WebhookID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOfID: &original.ID,
+	}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *WebhookService) find(id uint) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := s.db.First(&hook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NotFound("Webhook não encontrado")
+		}
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Invalid("URL do webhook deve ser http(s) absoluta")
+	}
+	return nil
+}
+
+func validateWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, Invalid("informe ao menos um evento")
+	}
+	var out []string
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if !slices.Contains(WebhookEvents, e) {
+			return nil, Invalid("evento desconhecido: " + e)
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload calcula a assinatura enviada em X-ADA-Signature.
+// Exportada para que integrações (e testes) validem com a mesma regra.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDispatcher entrega as entregas pendentes por HTTP POST, com a
+// mesma política de reenvio da outbox de e-mails.
+type WebhookDispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+func NewWebhookDispatcher(db *gorm.DB) *WebhookDispatcher {
+	return &WebhookDispatcher{db: db, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Dispatch envia um lote de entregas vencidas e devolve quantas foram
+// aceitas (resposta 2xx).
+func (d *WebhookDispatcher) Dispatch(ctx context.Context) (int, error) {
+	var batch []models.WebhookDelivery
+	if err := claimDue(d.db, &models.WebhookDelivery{}, models.DeliveryPending, &batch); err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range batch {
+		if ctx.Err() != nil {
+			break
+		}
+		delivery := &batch[i]
+
+		var hook models.Webhook
+		if err := d.db.First(&hook, delivery.WebhookID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := d.record(delivery, 0, errors.New("webhook removido"), true); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			return delivered, err
+		}
+
+		status, sendErr := d.post(ctx, &hook, delivery)
+		if err := d.record(delivery, status, sendErr, false); err != nil {
+			return delivered, err
+		}
+		if sendErr == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (d *WebhookDispatcher) post(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ADAManagement-Webhooks/1.0")
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhookPayload(hook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("resposta HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// record grava o resultado de uma tentativa; final encerra a entrega como
+// falha sem novas tentativas.
+func (d *WebhookDispatcher) record(delivery *models.WebhookDelivery, status int, sendErr error, final bool) error {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	updates := map[string]any{"attempts": attempts, "response_status": status}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliveryDelivered
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	case final || attempts >= outboxMaxAttempts:
+		updates["status"] = models.DeliveryFailed
+		updates["last_error"] = sendErr.Error()
+		slog.Error("entrega de webhook descartada", "id", delivery.ID, "webhook", delivery.WebhookID, "error", sendErr)
+	default:
+		updates["next_attempt_at"] = now.Add(retryBackoff(attempts))
+		updates["last_error"] = sendErr.Error()
+		slog.Warn("falha na entrega de webhook; nova tentativa agendada", "id", delivery.ID, "attempts", attempts, "error", sendErr)
+	}
+
+	return d.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"adamanagement/backend/internal/models"
+)
+
+func TestWebhookCreateValidates(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewWebhookService(db)
+
+	if _, err := svc.Create(1, WebhookInput{URL: "ftp://x", Events: []string{EventRoundOpened}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("URL não http deve dar ErrInvalid; obtive %v", err)
+	}
+	if _, err := svc.Create(1, WebhookInput{URL: "https://bi.ufes.br/hook", Events: []string{"round.deleted"}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("evento desconhecido deve dar ErrInvalid; obtive %v", err)
+	}
+
+	hook, err := svc.Create(1, WebhookInput{URL: "https://bi.ufes.br/hook", Events: []string{EventRoundOpened}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !hook.Active || len(hook.Secret) != 64 {
+		t.Errorf("webhook deveria nascer ativo e com segredo gerado: %+v", hook)
+	}
+}
+
+func TestPublishEventOnlyForSubscribedActiveHooks(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewWebhookService(db)
+	rounds := NewPlanRoundService(db)
+	seedStudentWithStatus(t, db, "2022001", "2025/2", models.StatusPAE)
+
+	inactive := false
+	subscribed, _ := svc.Create(1, WebhookInput{URL: "https://a.ufes.br", Events: []string{EventRoundOpened, EventRoundClosed}})
+	svc.Create(1, WebhookInput{URL: "https://b.ufes.br", Events: []string{EventActionCreated}})
+	svc.Create(1, WebhookInput{URL: "https://c.ufes.br", Events: []string{EventRoundOpened}, Active: &inactive})
+
+	first := openRoundFor(t, rounds, "2026/1", "2026/2")
+	openRoundFor(t, rounds, "2027/1", "2027/2") // fecha a primeira
+
+	var deliveries []models.WebhookDelivery
+	db.Order("id asc").Find(&deliveries)
+	if len(deliveries) != 3 {
+		t.Fatalf("esperava 3 entregas (aberta, fechada, aberta) só para o webhook assinante; obtive %d", len(deliveries))
+	}
+	wantEvents := []string{EventRoundOpened, EventRoundClosed, EventRoundOpened}
+	for i, d := range deliveries {
+		if d.WebhookID != subscribed.ID || d.Event != wantEvents[i] {
+			t.Errorf("entrega %d inesperada: %+v", i, d)
+		}
+	}
+
+	var envelope struct {
+		Event string         `json:"event"`
+		Data  map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(deliveries[1].Payload), &envelope); err != nil {
+		t.Fatalf("payload não é JSON: %v", err)
+	}
+	if envelope.Event != EventRoundClosed || envelope.Data["round_id"] != float64(first.ID) || envelope.Data["period1"] != "2026/1" {
+		t.Errorf("payload de round.closed incorreto: %+v", envelope)
+	}
+}
+
+func TestWebhookDispatchSignsAndRetries(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewWebhookService(db)
+
+	status := http.StatusInternalServerError
+	var gotSignature, gotEvent string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(HeaderWebhookSignature)
+		gotEvent = r.Header.Get(HeaderWebhookEvent)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	hook, err := svc.Create(1, WebhookInput{URL: server.URL, Secret: "s3gredo", Events: []string{EventImportCompleted}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := publishEvent(db, EventImportCompleted, map[string]any{"filename": "dados.csv"}); err != nil {
+		t.Fatalf("publishEvent: %v", err)
+	}
+
+	dispatcher := NewWebhookDispatcher(db)
+	if n, err := dispatcher.Dispatch(context.Background()); err != nil || n != 0 {
+		t.Fatalf("resposta 500 não deveria contar como entregue: n=%d err=%v", n, err)
+	}
+
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != 500 {
+		t.Fatalf("falha deveria ser registrada e reagendada: %+v", delivery)
+	}
+	if gotSignature != SignWebhookPayload("s3gredo", gotBody) || gotEvent != EventImportCompleted {
+		t.Errorf("cabeçalhos incorretos: assinatura %q evento %q", gotSignature, gotEvent)
+	}
+
+	status = http.StatusOK
+	db.Model(&delivery).Update("next_attempt_at", time.Now().Add(-time.Second))
+	if n, err := dispatcher.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("reenvio: n=%d err=%v", n, err)
+	}
+	db.First(&delivery, delivery.ID)
+	if delivery.Status != models.DeliveryDelivered || delivery.DeliveredAt == nil {
+		t.Errorf("entrega deveria estar concluída: %+v", delivery)
+	}
+
+	// Reenvio manual gera nova entrega, preservando a original no registro.
+	again, err := svc.Redeliver(delivery.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if again.ID == delivery.ID || again.RedeliveryOfID == nil || *again.RedeliveryOfID != delivery.ID ||
+		again.Status != models.DeliveryPending || again.Payload != delivery.Payload {
+		t.Errorf("reenvio incorreto: %+v", again)
+	}
+	log, total, err := svc.Deliveries(hook.ID, 10, 0)
+	if err != nil || total != 2 || len(log) != 2 || log[0].ID != again.ID {
+		t.Errorf("registro de entregas: total=%d err=%v %+v", total, err, log)
+	}
+}
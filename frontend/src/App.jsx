@@ -8,12 +8,15 @@ import { ThemeContextProvider } from './context/ThemeContext';
 
 import Login from './pages/Login';
 import StudentLogin from './pages/StudentLogin';
+import SSOCallback from './pages/SSOCallback';
 import StudentRegister from './pages/StudentRegister';
+import ActivateAccount from './pages/ActivateAccount';
 import StudentPlanPage from './pages/StudentPlanPage';
 import Home from './pages/Home';
 import RegisterUser from './pages/RegisterUser';
 import Profile from './pages/Profile';
 import UsersList from './pages/UsersList';
+import Roles from './pages/Roles';
 import ImportData from './pages/ImportData';
 import AcademicReport from './pages/Reports/AcademicReport';
 import CoursesReport from './pages/Reports/CoursesReport';
@@ -29,12 +32,11 @@ import CoordinatorStudentPlan from './pages/CoordinatorStudentPlan';
 import { ToastContainer } from 'react-toastify';
 import 'react-toastify/dist/ReactToastify.css';
 
-const STAFF = ['admin', 'user'];
-
-// PrivateRoute exige sessão e, opcionalmente, um dos papéis informados.
-// Quem não tem o papel é enviado para a sua própria área.
-const PrivateRoute = ({ children, roles }) => {
-  const { authenticated, user, loading } = useContext(AuthContext);
+// PrivateRoute exige sessão e, opcionalmente, um dos papéis informados,
+// a conta da coordenação (staff) ou uma permissão nomeada. Quem não tem
+// acesso é enviado para a sua própria área.
+const PrivateRoute = ({ children, roles, staff, permission }) => {
+  const { authenticated, user, can, loading } = useContext(AuthContext);
 
   if (loading) {
     return <div>Carregando...</div>;
@@ -42,13 +44,18 @@ const PrivateRoute = ({ children, roles }) => {
   if (!authenticated) {
     return <Navigate to="/" />;
   }
-  if (roles && !roles.includes(user?.role)) {
+  const denied = (roles && !roles.includes(user?.role))
+    || (staff && user?.role === 'student')
+    || (permission && user?.permissions && !can(permission));
+  if (denied) {
     return <Navigate to={user?.role === 'student' ? '/aluno' : '/home'} replace />;
   }
   return children;
 };
 
-const Staff = ({ children }) => <PrivateRoute roles={STAFF}>{children}</PrivateRoute>;
+const Staff = ({ children, permission }) => (
+  <PrivateRoute staff permission={permission}>{children}</PrivateRoute>
+);
 
 function App() {
   return (
@@ -64,17 +71,20 @@ function App() {
               {/* Público */}
               <Route path="/" element={<Login />} />
               <Route path="/aluno/login" element={<StudentLogin />} />
+              <Route path="/auth/callback" element={<SSOCallback />} />
               <Route path="/aluno/cadastro" element={<StudentRegister />} />
+              <Route path="/ativar" element={<ActivateAccount />} />
 
               {/* Área do aluno */}
               <Route path="/aluno" element={<PrivateRoute roles={['student']}><StudentPlanPage /></PrivateRoute>} />
 
               {/* Coordenação (admin ou user) */}
               <Route path="/home" element={<Staff><Home /></Staff>} />
-              <Route path="/register-user" element={<Staff><RegisterUser /></Staff>} />
+              <Route path="/register-user" element={<Staff permission="users.manage"><RegisterUser /></Staff>} />
               <Route path="/profile" element={<Staff><Profile /></Staff>} />
-              <Route path="/users" element={<Staff><UsersList /></Staff>} />
-              <Route path="/import" element={<Staff><ImportData /></Staff>} />
+              <Route path="/users" element={<Staff permission="users.manage"><UsersList /></Staff>} />
+              <Route path="/roles" element={<Staff permission="users.manage"><Roles /></Staff>} />
+              <Route path="/import" element={<Staff permission="import.upload"><ImportData /></Staff>} />
               <Route path="/reports/records" element={<Staff><AcademicReport /></Staff>} />
               <Route path="/report/records" element={<Staff><AcademicReport /></Staff>} />
               <Route path="/report/courses" element={<Staff><CoursesReport /></Staff>} />
@@ -8,18 +8,20 @@ import { useNavigate } from 'react-router-dom';
 import { AuthContext } from '../context/AuthContext';
 import { SemesterContext } from '../context/SemesterContext';
 import { ThemeContext } from '../context/ThemeContext';
+import NotificationBell from './NotificationBell';
 
 import ManageAccountsIcon from '@mui/icons-material/ManageAccounts';
 import AccountCircleIcon from '@mui/icons-material/AccountCircle';
 import LogoutIcon from '@mui/icons-material/Logout';
 import PersonAddIcon from '@mui/icons-material/PersonAdd';
+import AdminPanelSettingsIcon from '@mui/icons-material/AdminPanelSettings';
 import DashboardIcon from '@mui/icons-material/Dashboard';
 import CloudUploadIcon from '@mui/icons-material/CloudUpload';
 import DarkModeIcon from '@mui/icons-material/DarkMode';
 import LightModeIcon from '@mui/icons-material/LightMode';
 
 const Header = () => {
-  const { logout, user } = useContext(AuthContext);
+  const { logout, user, can } = useContext(AuthContext);
   const { semesters, selectedSemester, changeSemester } = useContext(SemesterContext);
   const { toggleColorMode, mode } = useContext(ThemeContext);
 
@@ -83,6 +85,8 @@ const Header = () => {
             </Typography>
           )}
 
+          <NotificationBell />
+
           <Tooltip title="Menu">
             <IconButton
               onClick={handleMenuOpen}
@@ -144,16 +148,26 @@ const Header = () => {
 
           <Divider sx={{ my: 1 }} />
 
-          {user && user.role === 'admin' && (
+          {(can('import.upload') || can('users.manage')) && (
             <div>
-                <MenuItem onClick={() => handleNavigate('/import')}>
-                    <ListItemIcon><CloudUploadIcon fontSize="small" /></ListItemIcon>
-                    Importar Dados
-                </MenuItem>
-                <MenuItem onClick={() => handleNavigate('/register-user')}>
-                    <ListItemIcon><PersonAddIcon fontSize="small" /></ListItemIcon>
-                    Cadastrar Usuário
-                </MenuItem>
+                {can('import.upload') && (
+                  <MenuItem onClick={() => handleNavigate('/import')}>
+                      <ListItemIcon><CloudUploadIcon fontSize="small" /></ListItemIcon>
+                      Importar Dados
+                  </MenuItem>
+                )}
+                {can('users.manage') && (
+                  <MenuItem onClick={() => handleNavigate('/register-user')}>
+                      <ListItemIcon><PersonAddIcon fontSize="small" /></ListItemIcon>
+                      Convidar Usuário
+                  </MenuItem>
+                )}
+                {can('users.manage') && (
+                  <MenuItem onClick={() => handleNavigate('/roles')}>
+                      <ListItemIcon><AdminPanelSettingsIcon fontSize="small" /></ListItemIcon>
+                      Papéis e Permissões
+                  </MenuItem>
+                )}
                 <Divider sx={{ my: 1 }} />
             </div>
           )}
@@ -0,0 +1,102 @@
+import { useEffect, useState } from 'react';
+import {
+  Badge, Box, Button, Divider, IconButton, Menu, MenuItem, Tooltip, Typography,
+} from '@mui/material';
+import NotificationsIcon from '@mui/icons-material/Notifications';
+
+import api from '../services/api';
+import { subscribe } from '../services/stream';
+
+const LIST_SIZE = 10;
+
+// Sino da central de notificações (staff e aluno): carrega as mais
+// recentes e acompanha o stream do backend, de modo que novas
+// notificações e o contador de não lidas chegam sem recarregar a página.
+const NotificationBell = () => {
+  const [items, setItems] = useState([]);
+  const [unread, setUnread] = useState(0);
+  const [anchorEl, setAnchorEl] = useState(null);
+
+  useEffect(() => {
+    api.get('/notifications', { params: { limit: LIST_SIZE } })
+      .then((res) => {
+        setItems(res.data);
+        setUnread(Number(res.headers['x-unread-count'] || 0));
+      })
+      .catch(() => {});
+
+    return subscribe('/notifications/stream', (event, data) => {
+      if (event === 'notification') {
+        setItems((prev) => [data, ...prev.filter((n) => n.ID !== data.ID)].slice(0, LIST_SIZE));
+      } else if (event === 'unread') {
+        setUnread(data.count);
+      }
+    });
+  }, []);
+
+  const markRead = async (n) => {
+    if (n.read_at) return;
+    try {
+      const res = await api.put(`/notifications/${n.ID}/read`);
+      setItems((prev) => prev.map((item) => (item.ID === n.ID ? res.data : item)));
+      setUnread((count) => Math.max(0, count - 1));
+    } catch {
+      // O contador volta a se alinhar pelo stream.
+    }
+  };
+
+  const markAllRead = async () => {
+    try {
+      await api.put('/notifications/read-all');
+      const now = new Date().toISOString();
+      setItems((prev) => prev.map((item) => ({ ...item, read_at: item.read_at || now })));
+      setUnread(0);
+    } catch {
+      // idem
+    }
+  };
+
+  return (
+    <>
+      <Tooltip title="Notificações">
+        <IconButton onClick={(e) => setAnchorEl(e.currentTarget)} sx={{ mr: 1 }}>
+          <Badge badgeContent={unread} color="error" max={99}>
+            <NotificationsIcon />
+          </Badge>
+        </IconButton>
+      </Tooltip>
+      <Menu
+        anchorEl={anchorEl}
+        open={Boolean(anchorEl)}
+        onClose={() => setAnchorEl(null)}
+        transformOrigin={{ horizontal: 'right', vertical: 'top' }}
+        anchorOrigin={{ horizontal: 'right', vertical: 'bottom' }}
+        PaperProps={{ sx: { width: 360, maxHeight: 480 } }}
+      >
+        <Box sx={{ px: 2, py: 1, display: 'flex', alignItems: 'center', justifyContent: 'space-between' }}>
+          <Typography variant="subtitle2" fontWeight={700}>Notificações</Typography>
+          <Button size="small" onClick={markAllRead} disabled={unread === 0}>
+            Marcar todas como lidas
+          </Button>
+        </Box>
+        <Divider />
+        {items.length === 0 && (
+          <MenuItem disabled>Nenhuma notificação.</MenuItem>
+        )}
+        {items.map((n) => (
+          <MenuItem key={n.ID} onClick={() => markRead(n)} sx={{ whiteSpace: 'normal', display: 'block' }}>
+            <Typography variant="body2" fontWeight={n.read_at ? 400 : 700}>{n.title}</Typography>
+            {n.body && (
+              <Typography variant="caption" color="text.secondary" component="div">{n.body}</Typography>
+            )}
+            <Typography variant="caption" color="text.secondary">
+              {new Date(n.created_at).toLocaleString('pt-BR')}
+            </Typography>
+          </MenuItem>
+        ))}
+      </Menu>
+    </>
+  );
+};
+
+export default NotificationBell;
@@ -7,6 +7,7 @@ import { useNavigate } from 'react-router-dom';
 
 import { AuthContext } from '../context/AuthContext';
 import { ThemeContext } from '../context/ThemeContext';
+import NotificationBell from './NotificationBell';
 
 // Cabeçalho enxuto da área do aluno: sem a navegação da coordenação e sem
 // o seletor de semestre (que é uma rota exclusiva de staff).
@@ -36,6 +37,7 @@ const StudentHeader = () => {
           </Typography>
         )}
 
+        <NotificationBell />
         <Tooltip title={mode === 'dark' ? 'Tema claro' : 'Tema escuro'}>
           <IconButton onClick={toggleColorMode} sx={{ mr: 1 }}>
             {mode === 'dark' ? <DarkModeIcon /> : <LightModeIcon />}
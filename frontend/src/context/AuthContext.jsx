@@ -21,6 +21,7 @@ export const AuthProvider = ({ children }) => {
       })
       .catch(() => {
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         localStorage.removeItem('user');
       })
       .finally(() => {
@@ -28,35 +29,77 @@ export const AuthProvider = ({ children }) => {
       });
   }, []);
 
+  // A resposta do login traz só a identidade; permissões e cursos do
+  // escopo vêm de /me, consultado em seguida.
+  const storeSession = ({ token, refresh_token: refreshToken, user: userData }) => {
+    localStorage.setItem('token', token);
+    localStorage.setItem('refresh_token', refreshToken);
+    localStorage.setItem('user', JSON.stringify(userData));
+    setUser(userData);
+    api.get('/me').then((res) => setUser(res.data)).catch(() => {});
+  };
+
+  // can informa se o papel do usuário concede a permissão nomeada.
+  const can = (permission) => !!user?.permissions?.includes(permission);
+
+  // Com verificação em duas etapas, a senha correta devolve apenas o
+  // desafio do segundo passo; a sessão só é aberta em verifyTwoFactor.
   const login = async (email, password) => {
     const response = await api.post('/login', { email, password });
 
-    const { token, user: userData } = response.data;
+    if (response.data.two_factor_required) {
+      return {
+        challenge: response.data.challenge,
+        enrollmentRequired: response.data.enrollment_required,
+      };
+    }
+    storeSession(response.data);
+    return null;
+  };
 
-    localStorage.setItem('token', token);
-    localStorage.setItem('user', JSON.stringify(userData));
-    setUser(userData);
+  const enrollTwoFactor = async (challenge) => {
+    const response = await api.post('/login/2fa/enroll', { challenge });
+    return response.data;
+  };
+
+  // Devolve os códigos de recuperação quando o login concluiu a ativação.
+  const verifyTwoFactor = async (challenge, code) => {
+    const response = await api.post('/login/2fa', { challenge, code });
+    storeSession(response.data);
+    return response.data.recovery_codes || null;
+  };
+
+  // Retorno do login institucional: o backend decide, pela identidade do
+  // provedor, se a sessão é de coordenação ou de aluno.
+  const loginWithSSO = async (code, state) => {
+    const response = await api.post('/auth/oidc/callback', { code, state }, { withCredentials: true });
+    storeSession(response.data);
+    return response.data.user;
   };
 
   // Login do aluno: identidade é a matrícula (role="student").
   const loginStudent = async (registration, password) => {
     const response = await api.post('/student/login', { registration, password });
-
-    const { token, user: userData } = response.data;
-
-    localStorage.setItem('token', token);
-    localStorage.setItem('user', JSON.stringify(userData));
-    setUser(userData);
+    storeSession(response.data);
   };
 
+  // Encerra a sessão também no servidor; falhas de rede não impedem a
+  // saída local.
   const logout = () => {
+    const token = localStorage.getItem('token');
+    if (token) {
+      api.post('/logout', null, { headers: { Authorization: `Bearer ${token}` } }).catch(() => {});
+    }
     localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
     localStorage.removeItem('user');
     setUser(null);
   };
 
   return (
-    <AuthContext.Provider value={{ authenticated: !!user, user, login, loginStudent, logout, loading }}>
+    <AuthContext.Provider value={{
+      authenticated: !!user, user, can, login, enrollTwoFactor, verifyTwoFactor, loginWithSSO, loginStudent, logout, loading,
+    }}>
       {children}
     </AuthContext.Provider>
   );
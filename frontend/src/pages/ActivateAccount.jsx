@@ -0,0 +1,89 @@
+import { useState } from 'react';
+import {
+  Box, Button, Container, TextField, Typography, Paper, Alert, Link as MuiLink,
+} from '@mui/material';
+import api from '../services/api';
+import { useNavigate, useSearchParams, Link as RouterLink } from 'react-router-dom';
+import { toast } from 'react-toastify';
+
+// Página pública do link de convite: o convidado define a própria senha
+// e segue para o login.
+const ActivateAccount = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get('token') || '';
+
+  const [password, setPassword] = useState('');
+  const [confirm, setConfirm] = useState('');
+  const [error, setError] = useState('');
+  const [saving, setSaving] = useState(false);
+
+  const navigate = useNavigate();
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError('');
+
+    if (password.length < 6) {
+      setError('A senha deve ter pelo menos 6 caracteres.');
+      return;
+    }
+    if (password !== confirm) {
+      setError('As senhas não coincidem.');
+      return;
+    }
+
+    setSaving(true);
+    try {
+      await api.post('/activate', { token, password });
+      toast.success('Conta ativada! Entre com o seu e-mail e a nova senha.');
+      navigate('/');
+    } catch (err) {
+      setError(err.response?.data?.error || 'Não foi possível ativar a conta.');
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  return (
+    <Box sx={{ minHeight: '100vh', display: 'flex', alignItems: 'center', justifyContent: 'center', bgcolor: 'background.default', py: 4 }}>
+      <Container maxWidth="xs">
+        <Paper elevation={0} sx={{ p: 5, display: 'flex', flexDirection: 'column', alignItems: 'center', border: '1px solid', borderColor: 'divider', borderRadius: 3 }}>
+          <Box component="img" src="/ufes-logo.png" alt="Logo UFES" sx={{ height: 52, mb: 3 }} />
+
+          <Typography component="h1" variant="h5" fontWeight={700} color="text.primary" sx={{ mb: 0.5 }}>
+            Ativar conta
+          </Typography>
+          <Typography variant="body2" color="text.secondary" sx={{ mb: 3, textAlign: 'center' }}>
+            Defina a senha de acesso ao sistema.
+          </Typography>
+
+          {!token && <Alert severity="warning" sx={{ width: '100%', mb: 2 }}>Link de convite incompleto. Abra o link recebido por e-mail.</Alert>}
+          {error && <Alert severity="error" sx={{ width: '100%', mb: 2 }}>{error}</Alert>}
+
+          <Box component="form" onSubmit={handleSubmit} sx={{ width: '100%' }}>
+            <TextField
+              margin="normal" required fullWidth autoFocus
+              name="password" label="Senha (mín. 6 caracteres)" type="password"
+              value={password} onChange={(e) => setPassword(e.target.value)}
+            />
+            <TextField
+              margin="normal" required fullWidth
+              name="confirm" label="Confirmar senha" type="password"
+              value={confirm} onChange={(e) => setConfirm(e.target.value)}
+            />
+            <Button type="submit" fullWidth variant="contained" size="large" disabled={saving || !token} sx={{ mt: 3, mb: 1, py: 1.5 }}>
+              {saving ? 'Ativando...' : 'Ativar conta'}
+            </Button>
+          </Box>
+
+          <Typography variant="body2" color="text.secondary" sx={{ mt: 2 }}>
+            Já ativou?{' '}
+            <MuiLink component={RouterLink} to="/">Entrar</MuiLink>
+          </Typography>
+        </Paper>
+      </Container>
+    </Box>
+  );
+};
+
+export default ActivateAccount;
@@ -86,7 +86,7 @@ const ModuleCard = ({ icon: Icon, title, description, color, isEmpty, onClick })
 
 const Home = () => {
   const navigate = useNavigate();
-  const { user } = useContext(AuthContext);
+  const { user, can } = useContext(AuthContext);
   const { selectedSemester } = useContext(SemesterContext);
 
   const [emptyReports, setEmptyReports] = useState({
@@ -219,7 +219,7 @@ const Home = () => {
             />
           </Grid>
 
-          {user?.role === 'admin' && (
+          {can('users.manage') && (
             <Grid item xs={12} sm={6} md={4}>
               <ModuleCard
                 icon={PeopleIcon}
@@ -232,7 +232,7 @@ const Home = () => {
             </Grid>
           )}
 
-          {user?.role === 'admin' && (
+          {can('import.upload') && (
             <Grid item xs={12} sm={6} md={4}>
               <ModuleCard
                 icon={UploadFileIcon}
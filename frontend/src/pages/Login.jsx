@@ -1,4 +1,4 @@
-import { useState, useContext } from 'react';
+import { useState, useContext, useEffect } from 'react';
 import {
   Box,
   Button,
@@ -8,26 +8,78 @@ import {
   Paper,
   Alert,
   Link as MuiLink,
+  Divider,
 } from '@mui/material';
 import { AuthContext } from '../context/AuthContext';
 import { useNavigate, Link as RouterLink } from 'react-router-dom';
+import api from '../services/api';
 
 const Login = () => {
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
   const [error, setError] = useState('');
+  // Segundo passo (verificação em duas etapas): desafio devolvido pela
+  // senha, segredo a configurar (administrador obrigado) e códigos de
+  // recuperação exibidos uma única vez ao concluir a ativação.
+  const [challenge, setChallenge] = useState(null);
+  const [setup, setSetup] = useState(null);
+  const [code, setCode] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState(null);
+  // Formas de login habilitadas no servidor (senha e/ou institucional).
+  const [providers, setProviders] = useState({ password: true, oidc: false });
 
-  const { login } = useContext(AuthContext);
+  const { login, enrollTwoFactor, verifyTwoFactor } = useContext(AuthContext);
   const navigate = useNavigate();
 
+  const apiError = (err, fallback) => err.response?.data?.error || fallback;
+
+  useEffect(() => {
+    api.get('/auth/providers')
+      .then((res) => setProviders(res.data))
+      .catch(() => {});
+  }, []);
+
+  const handleSSO = async () => {
+    setError('');
+    try {
+      // O backend grava um cookie que prende o login a este navegador.
+      const res = await api.get('/auth/oidc/authorize', { withCredentials: true });
+      window.location.assign(res.data.url);
+    } catch (err) {
+      setError(apiError(err, 'Não foi possível iniciar o login institucional.'));
+    }
+  };
+
   const handleSubmit = async (e) => {
     e.preventDefault();
     setError('');
     try {
-      await login(email, password);
+      const pending = await login(email, password);
+      if (!pending) {
+        navigate('/home');
+        return;
+      }
+      setChallenge(pending.challenge);
+      if (pending.enrollmentRequired) {
+        setSetup(await enrollTwoFactor(pending.challenge));
+      }
+    } catch (err) {
+      setError(apiError(err, 'Credenciais inválidas. Tente novamente.'));
+    }
+  };
+
+  const handleVerify = async (e) => {
+    e.preventDefault();
+    setError('');
+    try {
+      const codes = await verifyTwoFactor(challenge, code);
+      if (codes) {
+        setRecoveryCodes(codes);
+        return;
+      }
       navigate('/home');
-    } catch {
-      setError('Credenciais inválidas. Tente novamente.');
+    } catch (err) {
+      setError(apiError(err, 'Código inválido. Tente novamente.'));
     }
   };
 
@@ -74,39 +126,95 @@ const Login = () => {
             </Alert>
           )}
 
-          <Box component="form" onSubmit={handleSubmit} sx={{ width: '100%' }}>
-            <TextField
-              margin="normal"
-              required
-              fullWidth
-              label="E-mail Institucional"
-              name="email"
-              autoComplete="email"
-              autoFocus
-              value={email}
-              onChange={(e) => setEmail(e.target.value)}
-            />
-            <TextField
-              margin="normal"
-              required
-              fullWidth
-              name="password"
-              label="Senha"
-              type="password"
-              autoComplete="current-password"
-              value={password}
-              onChange={(e) => setPassword(e.target.value)}
-            />
-            <Button
-              type="submit"
-              fullWidth
-              variant="contained"
-              size="large"
-              sx={{ mt: 3, mb: 1, py: 1.5 }}
-            >
-              Entrar
-            </Button>
-          </Box>
+          {recoveryCodes && (
+            <Box sx={{ width: '100%' }}>
+              <Alert severity="warning" sx={{ mb: 2 }}>
+                Guarde estes códigos de recuperação em local seguro. Cada um substitui o código
+                do aplicativo uma única vez e eles não serão exibidos novamente.
+              </Alert>
+              <Typography component="pre" sx={{ fontFamily: 'monospace', textAlign: 'center', mb: 2 }}>
+                {recoveryCodes.join('\n')}
+              </Typography>
+              <Button fullWidth variant="contained" size="large" onClick={() => navigate('/home')}>
+                Continuar
+              </Button>
+            </Box>
+          )}
+
+          {challenge && !recoveryCodes && (
+            <Box component="form" onSubmit={handleVerify} sx={{ width: '100%' }}>
+              {setup ? (
+                <Alert severity="info" sx={{ mb: 2, wordBreak: 'break-all' }}>
+                  A verificação em duas etapas é obrigatória para administradores. Cadastre no
+                  aplicativo autenticador a chave <strong>{setup.secret}</strong> (ou o endereço{' '}
+                  <MuiLink href={setup.otpauth_uri}>otpauth</MuiLink>) e informe o código gerado.
+                </Alert>
+              ) : (
+                <Typography variant="body2" color="text.secondary">
+                  Informe o código do aplicativo autenticador ou um código de recuperação.
+                </Typography>
+              )}
+              <TextField
+                margin="normal"
+                required
+                fullWidth
+                label="Código de verificação"
+                name="code"
+                autoComplete="one-time-code"
+                autoFocus
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+              />
+              <Button type="submit" fullWidth variant="contained" size="large" sx={{ mt: 3, mb: 1, py: 1.5 }}>
+                Verificar
+              </Button>
+            </Box>
+          )}
+
+          {!challenge && providers.oidc && (
+            <Box sx={{ width: '100%' }}>
+              <Button fullWidth variant="outlined" size="large" onClick={handleSSO} sx={{ py: 1.5 }}>
+                Entrar com conta institucional
+              </Button>
+              {providers.password && <Divider sx={{ my: 2 }}>ou</Divider>}
+            </Box>
+          )}
+
+          {!challenge && providers.password && (
+            <Box component="form" onSubmit={handleSubmit} sx={{ width: '100%' }}>
+              <TextField
+                margin="normal"
+                required
+                fullWidth
+                label="E-mail Institucional"
+                name="email"
+                autoComplete="email"
+                autoFocus
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+              />
+              <TextField
+                margin="normal"
+                required
+                fullWidth
+                name="password"
+                label="Senha"
+                type="password"
+                autoComplete="current-password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+              />
+              <Button
+                type="submit"
+                fullWidth
+                variant="contained"
+                size="large"
+                sx={{ mt: 3, mb: 1, py: 1.5 }}
+              >
+                Entrar
+              </Button>
+            </Box>
+          )}
 
           <Typography variant="body2" color="text.secondary" sx={{ mt: 2 }}>
             É aluno em PAE/PIC?{' '}
@@ -1,13 +1,39 @@
-import React, { useState } from 'react';
-import { Box, Button, Container, TextField, Typography, Paper, Grid } from '@mui/material';
+import React, { useEffect, useState } from 'react';
+import {
+  Box, Button, Container, TextField, Typography, Paper, Grid, MenuItem,
+  Table, TableBody, TableCell, TableHead, TableRow, IconButton, Tooltip, Alert,
+} from '@mui/material';
+import SendIcon from '@mui/icons-material/Send';
+import ContentCopyIcon from '@mui/icons-material/ContentCopy';
+import CancelIcon from '@mui/icons-material/Cancel';
 import api from '../services/api';
 import Header from '../components/Header';
-import { useNavigate } from 'react-router-dom';
 import { toast } from 'react-toastify';
 
+// Convite de usuários da coordenação: o administrador informa nome, e-mail
+// e papel; o convidado define a própria senha pelo link enviado por e-mail.
 const RegisterUser = () => {
-  const navigate = useNavigate();
-  const [formData, setFormData] = useState({ name: '', email: '', password: '' });
+  const [formData, setFormData] = useState({ name: '', email: '', role: 'user' });
+  const [roles, setRoles] = useState([]);
+  const [pending, setPending] = useState([]);
+  // Último link gerado, para entrega manual caso o e-mail não chegue.
+  const [lastLink, setLastLink] = useState(null);
+
+  const fetchPending = async () => {
+    try {
+      const response = await api.get('/invitations');
+      setPending(response.data);
+    } catch (error) {
+      toast.error('Erro ao carregar convites.');
+    }
+  };
+
+  useEffect(() => {
+    fetchPending();
+    api.get('/roles')
+      .then((res) => setRoles(res.data))
+      .catch(() => {});
+  }, []);
 
   const handleChange = (e) => {
     setFormData({ ...formData, [e.target.name]: e.target.value });
@@ -17,27 +43,52 @@ const RegisterUser = () => {
     e.preventDefault();
 
     try {
-      await api.post('/register', formData);
-
-      toast.success(`Usuário ${formData.name} criado com sucesso!`);
+      const response = await api.post('/invitations', formData);
+      toast.success(`Convite enviado para ${formData.email}.`);
+      setLastLink({ email: formData.email, url: response.data.activation_url });
+      setFormData({ name: '', email: '', role: formData.role });
+      fetchPending();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao convidar. Verifique se o e-mail já existe.');
+    }
+  };
 
-      navigate('/home');
+  const handleResend = async (inv) => {
+    try {
+      const response = await api.post(`/invitations/${inv.ID}/resend`);
+      toast.success('Convite reenviado.');
+      setLastLink({ email: inv.email, url: response.data.activation_url });
+      fetchPending();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao reenviar convite.');
+    }
+  };
 
+  const handleRevoke = async (inv) => {
+    if (!window.confirm(`Revogar o convite de ${inv.email}?`)) return;
+    try {
+      await api.delete(`/invitations/${inv.ID}`);
+      toast.success('Convite revogado.');
+      fetchPending();
     } catch (error) {
-      console.error(error);
-      toast.error('Erro ao cadastrar. Verifique se o e-mail já existe.');
+      toast.error(error.response?.data?.error || 'Erro ao revogar convite.');
     }
   };
 
+  const copyLink = () => {
+    navigator.clipboard?.writeText(lastLink.url);
+    toast.info('Link copiado.');
+  };
+
   return (
     <Box sx={{ flexGrow: 1, minHeight: '100vh', bgcolor: 'background.default' }}>
       <Header />
 
       <Container maxWidth="md" sx={{ mt: 5 }}>
-        <Paper elevation={3} sx={{ p: 4 }}>
+        <Paper elevation={3} sx={{ p: 4, mb: 3 }}>
           <Box display="flex" justifyContent="space-between" alignItems="center" mb={3}>
               <Typography variant="h5" color="primary" fontWeight="bold">
-              Cadastrar Novo Usuário
+              Convidar Novo Usuário
               </Typography>
           </Box>
 
@@ -50,25 +101,76 @@ const RegisterUser = () => {
                   variant="outlined"
                 />
               </Grid>
-              <Grid item xs={12} sm={6}>
+              <Grid item xs={12} sm={8}>
                 <TextField
                   required fullWidth label="E-mail" name="email" type="email"
                   value={formData.email} onChange={handleChange}
                   variant="outlined"
                 />
               </Grid>
-              <Grid item xs={12} sm={6}>
+              <Grid item xs={12} sm={4}>
                 <TextField
-                  required fullWidth label="Senha Inicial" name="password" type="password"
-                  value={formData.password} onChange={handleChange}
+                  select fullWidth label="Papel" name="role"
+                  value={formData.role} onChange={handleChange}
                   variant="outlined"
-                />
+                >
+                  {roles.map((r) => (
+                    <MenuItem key={r.id} value={r.name}>{r.description || r.name}</MenuItem>
+                  ))}
+                </TextField>
               </Grid>
             </Grid>
             <Button type="submit" variant="contained" fullWidth size="large" sx={{ mt: 4 }}>
-              Cadastrar Usuário
+              Enviar Convite
             </Button>
           </Box>
+
+          {lastLink && (
+            <Alert
+              severity="info"
+              sx={{ mt: 3, wordBreak: 'break-all' }}
+              action={<IconButton size="small" onClick={copyLink}><ContentCopyIcon fontSize="small" /></IconButton>}
+            >
+              Link de ativação de {lastLink.email} (exibido só agora): {lastLink.url}
+            </Alert>
+          )}
+        </Paper>
+
+        <Paper elevation={3} sx={{ p: 4 }}>
+          <Typography variant="h6" fontWeight="bold" mb={2}>Convites pendentes</Typography>
+          {pending.length === 0 ? (
+            <Typography variant="body2" color="text.secondary">Nenhum convite pendente.</Typography>
+          ) : (
+            <Table size="small">
+              <TableHead>
+                <TableRow>
+                  <TableCell>Nome</TableCell>
+                  <TableCell>E-mail</TableCell>
+                  <TableCell>Papel</TableCell>
+                  <TableCell>Expira em</TableCell>
+                  <TableCell align="right">Ações</TableCell>
+                </TableRow>
+              </TableHead>
+              <TableBody>
+                {pending.map((inv) => (
+                  <TableRow key={inv.ID}>
+                    <TableCell>{inv.name}</TableCell>
+                    <TableCell>{inv.email}</TableCell>
+                    <TableCell>{inv.role}</TableCell>
+                    <TableCell>{new Date(inv.expires_at).toLocaleString('pt-BR')}</TableCell>
+                    <TableCell align="right">
+                      <Tooltip title="Reenviar (gera um link novo)">
+                        <IconButton color="primary" onClick={() => handleResend(inv)}><SendIcon /></IconButton>
+                      </Tooltip>
+                      <Tooltip title="Revogar convite">
+                        <IconButton color="error" onClick={() => handleRevoke(inv)}><CancelIcon /></IconButton>
+                      </Tooltip>
+                    </TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          )}
         </Paper>
       </Container>
     </Box>
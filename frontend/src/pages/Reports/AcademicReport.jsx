@@ -2,12 +2,15 @@ import { useEffect, useState, useContext, useRef } from 'react';
 import {
   Box, Container, Paper, Typography, Table, TableBody, TableCell,
   TableContainer, TableHead, TableRow, Chip, LinearProgress,
-  Grid, TextField, MenuItem, Button, IconButton, Tooltip
+  Grid, TextField, MenuItem, Button, IconButton, Tooltip, TableSortLabel,
+  FormControlLabel, Switch
 } from '@mui/material';
 import SearchIcon from '@mui/icons-material/Search';
 import ClearIcon from '@mui/icons-material/Clear';
 import AssignmentIcon from '@mui/icons-material/Assignment';
 import FilterAltIcon from '@mui/icons-material/FilterAlt';
+import BookmarkAddIcon from '@mui/icons-material/BookmarkAdd';
+import DeleteIcon from '@mui/icons-material/Delete';
 import { useSearchParams, useNavigate } from 'react-router-dom';
 
 import Header from '../../components/Header';
@@ -29,10 +32,22 @@ const AcademicReport = () => {
 
   const [status, setStatus] = useState(searchParams.get('status') || '');
 
+  const [views, setViews] = useState([]);
+  const [selectedView, setSelectedView] = useState('');
+  const [viewName, setViewName] = useState('');
+  const [viewShared, setViewShared] = useState(false);
+
+  const loadViews = () => {
+    api.get('/report-views?report=records')
+       .then(res => setViews(res.data))
+       .catch(err => console.error('Erro ao carregar visões:', err));
+  };
+
   useEffect(() => {
     api.get('/reports/courses')
        .then(res => setCourses(res.data))
        .catch(err => console.error('Erro ao carregar cursos:', err));
+    loadViews();
   }, []);
 
   const fetchRecords = () => {
@@ -48,6 +63,8 @@ const AcademicReport = () => {
 
     if (mode) params.append('mode', mode);
     if (maxPending) params.append('max_pending', maxPending);
+    if (searchParams.get('sort')) params.append('sort', searchParams.get('sort'));
+    if (searchParams.get('exceeds_max_duration')) params.append('exceeds_max_duration', 'true');
 
     const currentStatus = urlStatus || status;
 
@@ -69,6 +86,97 @@ const AcademicReport = () => {
     fetchRecords();
   }, [selectedSemester, searchParams]);
 
+  // Ordenação no servidor por uma coluna: cada clique alterna entre a
+  // primeira direção, a oposta e a ordem padrão. Os valores antigos
+  // (risk, risk_asc) ainda chegam por links e visões salvas.
+  const legacySorts = { risk: '-risk_score', risk_asc: 'risk_score' };
+  const rawSort = searchParams.get('sort') || '';
+  const sort = legacySorts[rawSort] || rawSort;
+  const sortKey = sort.replace(/^-/, '');
+  const sortDesc = sort.startsWith('-');
+  const handleSort = (key, descFirst = false) => {
+    const first = descFirst ? `-${key}` : key;
+    const second = descFirst ? key : `-${key}`;
+    const next = sort === first ? second : sort === second ? '' : first;
+    const newParams = new URLSearchParams(searchParams);
+    if (next) newParams.set('sort', next);
+    else newParams.delete('sort');
+    setSearchParams(newParams);
+  };
+  const sortHeader = (key, label, descFirst = false) => (
+    <TableSortLabel
+      active={sortKey === key}
+      direction={sortKey === key && sortDesc ? 'desc' : 'asc'}
+      onClick={() => handleSort(key, descFirst)}
+    >
+      <b>{label}</b>
+    </TableSortLabel>
+  );
+
+  const exceedsOnly = searchParams.get('exceeds_max_duration') === 'true';
+  const handleExceedsToggle = (e) => {
+    const newParams = new URLSearchParams(searchParams);
+    if (e.target.checked) newParams.set('exceeds_max_duration', 'true');
+    else newParams.delete('exceeds_max_duration');
+    setSearchParams(newParams);
+  };
+
+  const riskColor = (score) => (score >= 60 ? 'error' : score >= 30 ? 'warning' : 'default');
+
+  // Visões salvas: os filtros atuais (exceto o semestre, que segue o
+  // seletor global) e a ordenação.
+  const currentFilters = () => {
+    const filters = {};
+    if (registrationRef.current?.value) filters.registration = registrationRef.current.value;
+    if (studentNameRef.current?.value) filters.student_name = studentNameRef.current.value;
+    if (selectedCourse) filters.course_name = selectedCourse;
+    if (searchParams.get('status') || status) filters.status = searchParams.get('status') || status;
+    if (searchParams.get('mode')) filters.mode = searchParams.get('mode');
+    if (searchParams.get('max_pending')) filters.max_pending = Number(searchParams.get('max_pending'));
+    if (exceedsOnly) filters.exceeds_max_duration = true;
+    return filters;
+  };
+
+  const handleSaveView = () => {
+    if (!viewName.trim()) return;
+    api.post('/report-views', { name: viewName, report: 'records', filters: currentFilters(), sort, shared: viewShared })
+       .then(res => {
+         setSelectedView(res.data.ID);
+         setViewName('');
+         loadViews();
+       })
+       .catch(err => alert(err.response?.data?.error || 'Erro ao salvar a visão.'));
+  };
+
+  const applyView = (id) => {
+    setSelectedView(id);
+    const view = views.find((v) => v.ID === id);
+    if (!view) return;
+    const f = view.filters || {};
+    if (registrationRef.current) registrationRef.current.value = f.registration || '';
+    if (studentNameRef.current) studentNameRef.current.value = f.student_name || '';
+    setSelectedCourse(f.course_name || '');
+    setStatus(f.status || '');
+    const newParams = new URLSearchParams();
+    if (f.status) newParams.set('status', f.status);
+    if (f.mode) newParams.set('mode', f.mode);
+    if (f.max_pending !== undefined) newParams.set('max_pending', f.max_pending);
+    if (f.exceeds_max_duration) newParams.set('exceeds_max_duration', 'true');
+    if (view.sort) newParams.set('sort', view.sort);
+    setSearchParams(newParams);
+  };
+
+  const handleDeleteView = () => {
+    api.delete(`/report-views/${selectedView}`)
+       .then(() => {
+         setSelectedView('');
+         loadViews();
+       })
+       .catch(err => alert(err.response?.data?.error || 'Erro ao remover a visão.'));
+  };
+
+  const selectedViewMine = views.find((v) => v.ID === selectedView)?.mine;
+
   const handleClear = () => {
     if(registrationRef.current) registrationRef.current.value = '';
     if(studentNameRef.current) studentNameRef.current.value = '';
@@ -79,7 +187,9 @@ const AcademicReport = () => {
 
   let title = `Relatório Acadêmico - ${selectedSemesterCode}`;
   if (searchParams.get('mode') === 'critical') title = `Relatório: Alunos em Situação Crítica (${selectedSemesterCode})`;
+  if (searchParams.get('mode') === 'near_graduation') title = `Relatório: Possíveis Formandos (${selectedSemesterCode})`;
   if (searchParams.get('max_pending')) title = `Relatório: Possíveis Formandos (${selectedSemesterCode})`;
+  if (exceedsOnly) title = `Relatório: Alunos que Excederão o Prazo Máximo (${selectedSemesterCode})`;
 
   return (
     <Box sx={{ flexGrow: 1, minHeight: '100vh', bgcolor: 'background.default' }}>
@@ -107,11 +217,11 @@ const AcademicReport = () => {
             <Grid item xs={12} sm={2}>
                 <TextField fullWidth label="Matrícula" inputRef={registrationRef} size="small" />
             </Grid>
-            <Grid item xs={12} sm={3}>
+            <Grid item xs={12} sm={2}>
                 <TextField fullWidth label="Aluno" inputRef={studentNameRef} size="small" />
             </Grid>
 
-            <Grid item xs={12} sm={3}>
+            <Grid item xs={12} sm={2}>
                 <TextField
                     select fullWidth label="Curso"
                     value={selectedCourse}
@@ -148,10 +258,49 @@ const AcademicReport = () => {
                     <MenuItem value="Desligamento">Desligamento</MenuItem>
                 </TextField>
             </Grid>
+            <Grid item xs={12} sm={2}>
+                <FormControlLabel
+                    control={<Switch checked={exceedsOnly} onChange={handleExceedsToggle} size="small" />}
+                    label={<Typography variant="body2">Excederá o prazo máximo</Typography>}
+                />
+            </Grid>
             <Grid item xs={12} sm={2} sx={{ display: 'flex', gap: 1 }}>
                 <Button variant="contained" startIcon={<SearchIcon />} onClick={fetchRecords}>Buscar</Button>
                 <Button variant="outlined" startIcon={<ClearIcon />} onClick={handleClear}>Limpar</Button>
             </Grid>
+
+            <Grid item xs={12} sm={4} sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
+                <TextField
+                    select fullWidth label="Visão salva"
+                    value={selectedView}
+                    onChange={(e) => applyView(e.target.value)}
+                    size="small"
+                >
+                    <MenuItem value="">Nenhuma</MenuItem>
+                    {views.map((v) => (
+                        <MenuItem key={v.ID} value={v.ID}>
+                            {v.name}{!v.mine && v.owner ? ` (${v.owner})` : ''}
+                        </MenuItem>
+                    ))}
+                </TextField>
+                {selectedView && selectedViewMine && (
+                    <Tooltip title="Remover visão">
+                        <IconButton color="error" onClick={handleDeleteView}><DeleteIcon /></IconButton>
+                    </Tooltip>
+                )}
+            </Grid>
+            <Grid item xs={12} sm={3}>
+                <TextField fullWidth label="Nome da nova visão" value={viewName} onChange={(e) => setViewName(e.target.value)} size="small" />
+            </Grid>
+            <Grid item xs={12} sm={5} sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
+                <FormControlLabel
+                    control={<Switch checked={viewShared} onChange={(e) => setViewShared(e.target.checked)} size="small" />}
+                    label={<Typography variant="body2">Compartilhar</Typography>}
+                />
+                <Button variant="outlined" startIcon={<BookmarkAddIcon />} onClick={handleSaveView} disabled={!viewName.trim()}>
+                    Salvar visão
+                </Button>
+            </Grid>
           </Grid>
         </Paper>
 
@@ -161,13 +310,15 @@ const AcademicReport = () => {
           <Table size="small">
             <TableHead>
               <TableRow>
-                <TableCell><b>Matrícula</b></TableCell>
-                <TableCell><b>Aluno</b></TableCell>
-                <TableCell><b>Curso</b></TableCell>
+                <TableCell>{sortHeader('registration', 'Matrícula')}</TableCell>
+                <TableCell>{sortHeader('name', 'Aluno')}</TableCell>
+                <TableCell>{sortHeader('course', 'Curso')}</TableCell>
                 <TableCell><b>Status</b></TableCell>
                 <TableCell><b>Detalhe</b></TableCell>
                 <TableCell align="center"><b>% Concluído</b></TableCell>
                 <TableCell align="center"><b>Materias Obrigatórias Pendentes</b></TableCell>
+                <TableCell align="center"><b>Formatura Prevista</b></TableCell>
+                <TableCell align="center">{sortHeader('risk_score', 'Risco', true)}</TableCell>
                 <TableCell align="center"><b>Ações</b></TableCell>
               </TableRow>
             </TableHead>
@@ -192,6 +343,12 @@ const AcademicReport = () => {
                     </TableCell>
                     <TableCell align="center">{progress.toFixed(1)}%</TableCell>
                     <TableCell align="center">{row.pending_obligatory}</TableCell>
+                    <TableCell align="center" sx={{ color: row.exceeds_max_duration ? 'error.main' : 'inherit', fontWeight: row.exceeds_max_duration ? 'bold' : 'normal' }}>
+                        {row.expected_graduation || '—'}
+                    </TableCell>
+                    <TableCell align="center">
+                        <Chip label={row.risk_score.toFixed(0)} color={riskColor(row.risk_score)} size="small" variant="outlined" />
+                    </TableCell>
                     <TableCell align="center">
                         <Tooltip title={isRegular ? 'Aluno em regularidade' : 'Registrar Ação'}>
                             <span>
@@ -209,7 +366,7 @@ const AcademicReport = () => {
                  )
               })}
               {records.length === 0 && !loading && (
-                  <TableRow><TableCell colSpan={8} align="center">Nenhum registro encontrado.</TableCell></TableRow>
+                  <TableRow><TableCell colSpan={10} align="center">Nenhum registro encontrado.</TableCell></TableRow>
               )}
             </TableBody>
           </Table>
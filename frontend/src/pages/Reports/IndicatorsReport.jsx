@@ -2,16 +2,20 @@ import { useEffect, useState, useContext } from 'react';
 import {
   Box, Container, Grid, Paper, Typography, LinearProgress,
   Table, TableBody, TableCell, TableContainer, TableHead, TableRow,
-  Chip, IconButton, Tooltip, Button, useTheme
+  Chip, IconButton, Tooltip, Button, TextField, MenuItem, FormControlLabel, Switch,
+  ToggleButton, ToggleButtonGroup, useTheme
 } from '@mui/material';
 import {
-  PieChart, Pie, Cell, Tooltip as RechartsTooltip, Legend, ResponsiveContainer
+  PieChart, Pie, Cell, Tooltip as RechartsTooltip, Legend, ResponsiveContainer,
+  LineChart, Line, XAxis, YAxis, CartesianGrid
 } from 'recharts';
 
 import WarningAmberIcon from '@mui/icons-material/WarningAmber';
 import TimelineIcon from '@mui/icons-material/Timeline';
 import SchoolIcon from '@mui/icons-material/School';
+import TrendingUpIcon from '@mui/icons-material/TrendingUp';
 import VisibilityIcon from '@mui/icons-material/Visibility';
+import DownloadIcon from '@mui/icons-material/Download';
 import { useNavigate } from 'react-router-dom';
 
 import Header from '../../components/Header';
@@ -20,13 +24,45 @@ import { SemesterContext } from '../../context/SemesterContext';
 
 const COLORS = ['#10B981', '#3B82F6', '#F59E0B', '#EF4444', '#8B5CF6'];
 
+// Métricas da série histórica; "status" (só na visão consolidada) traça
+// os três percentuais de enquadramento juntos.
+const TREND_METRICS = [
+  { key: 'status', label: 'Enquadramento (%)' },
+  { key: 'regular_pct', label: 'Em regularidade (%)' },
+  { key: 'pae_pct', label: 'PAE (%)' },
+  { key: 'pic_pct', label: 'PIC (%)' },
+  { key: 'critical', label: 'Alunos críticos' },
+  { key: 'near_graduation', label: 'Próximos da formatura' },
+];
+
+// Dimensões do recorte de indicadores (GET /reports/breakdown).
+const BREAKDOWNS = [
+  { key: 'course', label: 'Curso' },
+  { key: 'quota', label: 'Tipo de cota' },
+  { key: 'cohort', label: 'Coorte de ingresso' },
+];
+
 const IndicatorsReport = () => {
   const navigate = useNavigate();
   const theme = useTheme();
-  const { selectedSemester, selectedSemesterCode } = useContext(SemesterContext);
+  const { semesters, selectedSemester, selectedSemesterCode } = useContext(SemesterContext);
   const [data, setData] = useState(null);
   const [loading, setLoading] = useState(false);
 
+  const [trends, setTrends] = useState(null);
+  const [trendFrom, setTrendFrom] = useState('');
+  const [trendTo, setTrendTo] = useState('');
+  const [byCourse, setByCourse] = useState(false);
+  const [metric, setMetric] = useState('status');
+
+  const [risk, setRisk] = useState([]);
+  const [breakdownBy, setBreakdownBy] = useState('course');
+  const [breakdown, setBreakdown] = useState(null);
+
+  const [courses, setCourses] = useState([]);
+  const [cohortCourse, setCohortCourse] = useState('');
+  const [cohorts, setCohorts] = useState([]);
+
   useEffect(() => {
     if (!selectedSemester) return;
     setLoading(true);
@@ -37,6 +73,96 @@ const IndicatorsReport = () => {
        .finally(() => setLoading(false));
   }, [selectedSemester]);
 
+  useEffect(() => {
+    const params = new URLSearchParams();
+    if (trendFrom) params.append('from', trendFrom);
+    if (trendTo) params.append('to', trendTo);
+    if (byCourse) params.append('by_course', 'true');
+    api.get(`/reports/trends?${params.toString()}`)
+       .then(res => setTrends(res.data))
+       .catch(err => console.error(err));
+  }, [trendFrom, trendTo, byCourse]);
+
+  useEffect(() => {
+    if (!selectedSemester) return;
+    api.get(`/reports/risk?semester_id=${selectedSemester}&limit=10`)
+       .then(res => setRisk(res.data))
+       .catch(err => console.error(err));
+  }, [selectedSemester]);
+
+  useEffect(() => {
+    if (!selectedSemester) return;
+    api.get(`/reports/breakdown?semester_id=${selectedSemester}&by=${breakdownBy}`)
+       .then(res => setBreakdown(res.data))
+       .catch(err => console.error(err));
+  }, [selectedSemester, breakdownBy]);
+
+  useEffect(() => {
+    api.get('/reports/courses')
+       .then(res => setCourses(res.data))
+       .catch(err => console.error(err));
+  }, []);
+
+  const cohortParams = (format) => {
+    const params = new URLSearchParams();
+    if (cohortCourse) params.append('course_id', cohortCourse);
+    if (format) params.append('format', format);
+    return params.toString();
+  };
+
+  useEffect(() => {
+    api.get(`/reports/cohorts?${cohortParams()}`)
+       .then(res => setCohorts(res.data))
+       .catch(err => console.error(err));
+  }, [cohortCourse]);
+
+  // Baixa a tabela de retenção no formato pedido (csv ou xlsx).
+  const exportCohorts = (format) => {
+    api.get(`/reports/cohorts?${cohortParams(format)}`, { responseType: 'blob' })
+       .then(res => {
+         const url = URL.createObjectURL(res.data);
+         const link = document.createElement('a');
+         link.href = url;
+         link.download = `retencao-coortes.${format}`;
+         link.click();
+         URL.revokeObjectURL(url);
+       })
+       .catch(err => console.error(err));
+  };
+
+  const handleByCourse = (checked) => {
+    setByCourse(checked);
+    if (checked && metric === 'status') setMetric('regular_pct');
+  };
+
+  // Linhas do gráfico: na visão por curso, uma coluna por curso com o
+  // valor da métrica escolhida.
+  const trendRows = (trends?.semesters || []).map((semester, i) => {
+    if (!byCourse) return trends.series[0]?.points[i] || { semester };
+    const row = { semester };
+    trends.series.forEach((s) => { row[s.course] = s.points[i][metric]; });
+    return row;
+  });
+
+  const trendLines = () => {
+    if (byCourse) {
+      return trends.series.map((s, i) => (
+        <Line key={s.course_id} type="monotone" dataKey={s.course} stroke={COLORS[i % COLORS.length]} />
+      ));
+    }
+    if (metric === 'status') {
+      return [
+        <Line key="regular" type="monotone" dataKey="regular_pct" name="Em regularidade (%)" stroke={COLORS[0]} />,
+        <Line key="pae" type="monotone" dataKey="pae_pct" name="PAE (%)" stroke={COLORS[2]} />,
+        <Line key="pic" type="monotone" dataKey="pic_pct" name="PIC (%)" stroke={COLORS[3]} />,
+      ];
+    }
+    const label = TREND_METRICS.find((m) => m.key === metric)?.label;
+    return <Line type="monotone" dataKey={metric} name={label} stroke={COLORS[1]} />;
+  };
+
+  const semesterCodes = [...semesters].map((s) => s.code).sort();
+
   const handlePieClick = (entry) => {
     navigate(`/reports/records?status=${entry.name}`);
   };
@@ -194,13 +320,13 @@ const IndicatorsReport = () => {
                         <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
                             <SchoolIcon color="success" />
                             <Typography variant="h6" color="success.main" fontWeight="bold">
-                                Próximos da Formatura (≤ 6 Matérias Pendentes)
+                                Próximos da Formatura
                             </Typography>
                         </Box>
 
                         <Button
                             variant="outlined" color="success" size="small" endIcon={<VisibilityIcon />}
-                            onClick={() => navigate('/reports/records?max_pending=6')}
+                            onClick={() => navigate('/reports/records?mode=near_graduation')}
                         >
                             Ver Relatório Completo
                         </Button>
@@ -253,6 +379,228 @@ const IndicatorsReport = () => {
                 </Paper>
             </Grid>
 
+            <Grid item xs={12}>
+                <Paper sx={{ p: 3, display: 'flex', flexDirection: 'column' }}>
+                    <Box sx={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', mb: 2 }}>
+                        <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
+                            <TrendingUpIcon color="warning" />
+                            <Typography variant="h6" color="warning.main" fontWeight="bold">
+                                Maior Risco de Evasão
+                            </Typography>
+                        </Box>
+
+                        <Button
+                            variant="outlined" color="warning" size="small" endIcon={<VisibilityIcon />}
+                            onClick={() => navigate('/reports/records?sort=-risk_score')}
+                        >
+                            Ver Relatório por Risco
+                        </Button>
+                    </Box>
+
+                    <TableContainer sx={{ maxHeight: 400, ...scrollStyle }}>
+                        <Table size="small" stickyHeader>
+                            <TableHead>
+                                <TableRow>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Matrícula</TableCell>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Nome</TableCell>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Curso</TableCell>
+                                    <TableCell align="center" sx={{ fontWeight: 'bold' }}>Risco</TableCell>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Principais fatores</TableCell>
+                                    <TableCell align="center" sx={{ fontWeight: 'bold' }}>Ação</TableCell>
+                                </TableRow>
+                            </TableHead>
+                            <TableBody>
+                                {risk.map((entry) => (
+                                    <TableRow key={entry.record_id} hover>
+                                        <TableCell>{entry.registration}</TableCell>
+                                        <TableCell>{entry.name}</TableCell>
+                                        <TableCell>{entry.course}</TableCell>
+                                        <TableCell align="center">
+                                            <Chip label={entry.score.toFixed(0)} color={entry.score >= 60 ? 'error' : 'warning'} size="small" />
+                                        </TableCell>
+                                        <TableCell>
+                                            <Box sx={{ display: 'flex', flexWrap: 'wrap', gap: 0.5 }}>
+                                                {entry.factors.filter((f) => f.points > 0).slice(0, 3).map((f) => (
+                                                    <Tooltip key={f.factor} title={`Valor: ${f.value} · peso ${f.weight}`}>
+                                                        <Chip label={`${f.label}: +${f.points.toFixed(1)}`} size="small" variant="outlined" />
+                                                    </Tooltip>
+                                                ))}
+                                            </Box>
+                                        </TableCell>
+                                        <TableCell align="center">
+                                            <Tooltip title="Ver Histórico">
+                                                <IconButton size="small" color="primary" onClick={() => navigate(`/students/${entry.registration}`)}>
+                                                    <TimelineIcon fontSize="small" />
+                                                </IconButton>
+                                            </Tooltip>
+                                        </TableCell>
+                                    </TableRow>
+                                ))}
+                                {risk.length === 0 && (
+                                    <TableRow>
+                                        <TableCell colSpan={6} align="center" sx={{ py: 3 }}>Nenhum registro no semestre.</TableCell>
+                                    </TableRow>
+                                )}
+                            </TableBody>
+                        </Table>
+                    </TableContainer>
+                </Paper>
+            </Grid>
+
+            <Grid item xs={12}>
+                <Paper sx={{ p: 3, display: 'flex', flexDirection: 'column' }}>
+                    <Box sx={{ display: 'flex', flexWrap: 'wrap', justifyContent: 'space-between', alignItems: 'center', gap: 2, mb: 2 }}>
+                        <Typography variant="h6" fontWeight="bold">
+                            Indicadores por Grupo
+                        </Typography>
+                        <ToggleButtonGroup
+                            size="small"
+                            exclusive
+                            value={breakdownBy}
+                            onChange={(_, value) => value && setBreakdownBy(value)}
+                        >
+                            {BREAKDOWNS.map((b) => (
+                                <ToggleButton key={b.key} value={b.key}>{b.label}</ToggleButton>
+                            ))}
+                        </ToggleButtonGroup>
+                    </Box>
+
+                    <TableContainer sx={{ maxHeight: 400, ...scrollStyle }}>
+                        <Table size="small" stickyHeader>
+                            <TableHead>
+                                <TableRow>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>
+                                        {BREAKDOWNS.find((b) => b.key === breakdownBy)?.label}
+                                    </TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Alunos</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Em regularidade</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>PAE</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>PIC</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Integralização média</TableCell>
+                                </TableRow>
+                            </TableHead>
+                            <TableBody>
+                                {[...(breakdown?.groups || []), ...(breakdown?.groups?.length ? [breakdown.overall] : [])].map((g) => (
+                                    <TableRow key={`${g.course_id ?? ''}:${g.key}`} hover sx={g === breakdown.overall ? { '& td': { fontWeight: 'bold' } } : undefined}>
+                                        <TableCell>{g.key}</TableCell>
+                                        <TableCell align="right">{g.total}</TableCell>
+                                        <TableCell align="right">{g.regular} ({g.regular_pct}%)</TableCell>
+                                        <TableCell align="right">{g.pae} ({g.pae_pct}%)</TableCell>
+                                        <TableCell align="right">{g.pic} ({g.pic_pct}%)</TableCell>
+                                        <TableCell align="right">{g.integralization_pct}%</TableCell>
+                                    </TableRow>
+                                ))}
+                                {!breakdown?.groups?.length && (
+                                    <TableRow>
+                                        <TableCell colSpan={6} align="center" sx={{ py: 3 }}>Nenhum registro no semestre.</TableCell>
+                                    </TableRow>
+                                )}
+                            </TableBody>
+                        </Table>
+                    </TableContainer>
+                </Paper>
+            </Grid>
+
+            <Grid item xs={12}>
+                <Paper sx={{ p: 3, display: 'flex', flexDirection: 'column' }}>
+                    <Box sx={{ display: 'flex', flexWrap: 'wrap', justifyContent: 'space-between', alignItems: 'center', gap: 2, mb: 2 }}>
+                        <Typography variant="h6" fontWeight="bold">
+                            Evolução por Semestre
+                        </Typography>
+                        <Box sx={{ display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: 2 }}>
+                            <TextField select size="small" label="De" value={trendFrom} onChange={(e) => setTrendFrom(e.target.value)} sx={{ minWidth: 110 }}>
+                                <MenuItem value="">Início</MenuItem>
+                                {semesterCodes.map((code) => <MenuItem key={code} value={code}>{code}</MenuItem>)}
+                            </TextField>
+                            <TextField select size="small" label="Até" value={trendTo} onChange={(e) => setTrendTo(e.target.value)} sx={{ minWidth: 110 }}>
+                                <MenuItem value="">Atual</MenuItem>
+                                {semesterCodes.map((code) => <MenuItem key={code} value={code}>{code}</MenuItem>)}
+                            </TextField>
+                            <TextField select size="small" label="Métrica" value={metric} onChange={(e) => setMetric(e.target.value)} sx={{ minWidth: 200 }}>
+                                {TREND_METRICS.filter((m) => !byCourse || m.key !== 'status').map((m) => (
+                                    <MenuItem key={m.key} value={m.key}>{m.label}</MenuItem>
+                                ))}
+                            </TextField>
+                            <FormControlLabel
+                                control={<Switch checked={byCourse} onChange={(e) => handleByCourse(e.target.checked)} />}
+                                label="Por curso"
+                            />
+                        </Box>
+                    </Box>
+
+                    {trendRows.length === 0 ? (
+                        <Typography color="text.secondary" sx={{ py: 3, textAlign: 'center' }}>
+                            Nenhum registro no intervalo selecionado.
+                        </Typography>
+                    ) : (
+                        <ResponsiveContainer width="100%" height={360}>
+                            <LineChart data={trendRows}>
+                                <CartesianGrid strokeDasharray="3 3" stroke={theme.palette.divider} />
+                                <XAxis dataKey="semester" stroke={theme.palette.text.secondary} />
+                                <YAxis stroke={theme.palette.text.secondary} allowDecimals={metric.endsWith('_pct') || metric === 'status'} />
+                                <RechartsTooltip contentStyle={chartTooltipStyle} />
+                                <Legend />
+                                {trendLines()}
+                            </LineChart>
+                        </ResponsiveContainer>
+                    )}
+                </Paper>
+            </Grid>
+
+            <Grid item xs={12}>
+                <Paper sx={{ p: 3, display: 'flex', flexDirection: 'column' }}>
+                    <Box sx={{ display: 'flex', flexWrap: 'wrap', justifyContent: 'space-between', alignItems: 'center', gap: 2, mb: 2 }}>
+                        <Typography variant="h6" fontWeight="bold">
+                            Retenção por Coorte
+                        </Typography>
+                        <Box sx={{ display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: 2 }}>
+                            <TextField select size="small" label="Curso" value={cohortCourse} onChange={(e) => setCohortCourse(e.target.value)} sx={{ minWidth: 220 }}>
+                                <MenuItem value="">Todos</MenuItem>
+                                {courses.map((c) => <MenuItem key={c.ID} value={c.ID}>{c.name}</MenuItem>)}
+                            </TextField>
+                            <Button size="small" variant="outlined" startIcon={<DownloadIcon />} onClick={() => exportCohorts('csv')}>CSV</Button>
+                            <Button size="small" variant="outlined" startIcon={<DownloadIcon />} onClick={() => exportCohorts('xlsx')}>XLSX</Button>
+                        </Box>
+                    </Box>
+
+                    <TableContainer sx={{ maxHeight: 400, ...scrollStyle }}>
+                        <Table size="small" stickyHeader>
+                            <TableHead>
+                                <TableRow>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Curso</TableCell>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Coorte</TableCell>
+                                    <TableCell sx={{ fontWeight: 'bold' }}>Semestre</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Presentes</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Em regularidade</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>PAE</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>PIC</TableCell>
+                                    <TableCell align="right" sx={{ fontWeight: 'bold' }}>Ausentes</TableCell>
+                                </TableRow>
+                            </TableHead>
+                            <TableBody>
+                                {cohorts.flatMap((c) => c.semesters.map((p, i) => (
+                                    <TableRow key={`${c.course_id}-${c.cohort}-${p.semester}`} hover>
+                                        <TableCell>{i === 0 ? c.course : ''}</TableCell>
+                                        <TableCell>{i === 0 ? `${c.cohort} (${c.size} alunos)` : ''}</TableCell>
+                                        <TableCell>{p.semester}</TableCell>
+                                        <TableCell align="right">{p.present_pct}%</TableCell>
+                                        <TableCell align="right">{p.regular_pct}%</TableCell>
+                                        <TableCell align="right">{p.pae_pct}%</TableCell>
+                                        <TableCell align="right">{p.pic_pct}%</TableCell>
+                                        <TableCell align="right" sx={{ color: p.gone_pct > 0 ? 'error.main' : 'inherit' }}>{p.gone_pct}%</TableCell>
+                                    </TableRow>
+                                )))}
+                                {cohorts.length === 0 && (
+                                    <TableRow>
+                                        <TableCell colSpan={8} align="center" sx={{ py: 3 }}>Nenhuma coorte com ano de ingresso informado.</TableCell>
+                                    </TableRow>
+                                )}
+                            </TableBody>
+                        </Table>
+                    </TableContainer>
+                </Paper>
+            </Grid>
+
         </Grid>
       </Container>
     </Box>
@@ -0,0 +1,136 @@
+import React, { useEffect, useState } from 'react';
+import {
+  Box, Container, Typography, Paper, Table, TableBody, TableCell,
+  TableContainer, TableHead, TableRow, IconButton, Tooltip, Checkbox,
+  Grid, TextField, Button,
+} from '@mui/material';
+import DeleteIcon from '@mui/icons-material/Delete';
+import Header from '../components/Header';
+import api from '../services/api';
+import { toast } from 'react-toastify';
+
+// Papéis da coordenação e as permissões de cada um. O papel admin é fixo;
+// os demais são editados marcando as permissões na matriz.
+const Roles = () => {
+  const [roles, setRoles] = useState([]);
+  const [permissions, setPermissions] = useState([]);
+  const [name, setName] = useState('');
+  const [description, setDescription] = useState('');
+
+  const fetchRoles = async () => {
+    try {
+      const [rolesRes, permsRes] = await Promise.all([api.get('/roles'), api.get('/permissions')]);
+      setRoles(rolesRes.data);
+      setPermissions(permsRes.data);
+    } catch (error) {
+      toast.error('Erro ao carregar papéis.');
+    }
+  };
+
+  useEffect(() => {
+    fetchRoles();
+  }, []);
+
+  const handleToggle = async (role, permission) => {
+    const granted = role.permissions.includes(permission)
+      ? role.permissions.filter((p) => p !== permission)
+      : [...role.permissions, permission];
+    try {
+      await api.put(`/roles/${role.id}`, { description: role.description, permissions: granted });
+      fetchRoles();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao alterar permissões.');
+    }
+  };
+
+  const handleCreate = async (e) => {
+    e.preventDefault();
+    try {
+      await api.post('/roles', { name, description, permissions: [] });
+      toast.success('Papel criado.');
+      setName('');
+      setDescription('');
+      fetchRoles();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao criar papel.');
+    }
+  };
+
+  const handleDelete = async (role) => {
+    if (!window.confirm(`Remover o papel ${role.name}?`)) return;
+    try {
+      await api.delete(`/roles/${role.id}`);
+      toast.success('Papel removido.');
+      fetchRoles();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao remover papel.');
+    }
+  };
+
+  return (
+    <Box sx={{ flexGrow: 1, minHeight: '100vh', bgcolor: 'background.default' }}>
+      <Header />
+      <Container maxWidth="lg" sx={{ mt: 5 }}>
+        <Paper elevation={3} sx={{ p: 4, mb: 3 }}>
+          <Typography variant="h5" color="primary" fontWeight="bold" mb={3}>
+            Papéis e Permissões
+          </Typography>
+          <Grid container spacing={2} component="form" onSubmit={handleCreate} alignItems="center">
+            <Grid item xs={12} sm={3}>
+              <TextField fullWidth required size="small" label="Nome" value={name} onChange={(e) => setName(e.target.value)} />
+            </Grid>
+            <Grid item xs={12} sm={7}>
+              <TextField fullWidth size="small" label="Descrição" value={description} onChange={(e) => setDescription(e.target.value)} />
+            </Grid>
+            <Grid item xs={12} sm={2}>
+              <Button fullWidth type="submit" variant="contained">Criar papel</Button>
+            </Grid>
+          </Grid>
+        </Paper>
+
+        <Paper elevation={3}>
+          <TableContainer>
+            <Table size="small">
+              <TableHead>
+                <TableRow>
+                  <TableCell>Permissão</TableCell>
+                  {roles.map((r) => (
+                    <TableCell key={r.id} align="center">
+                      <Tooltip title={`${r.description || r.name} · ${r.users} usuário(s)`}>
+                        <span>{r.name}</span>
+                      </Tooltip>
+                      {!r.system && (
+                        <IconButton size="small" color="error" onClick={() => handleDelete(r)} disabled={r.users > 0}>
+                          <DeleteIcon fontSize="small" />
+                        </IconButton>
+                      )}
+                    </TableCell>
+                  ))}
+                </TableRow>
+              </TableHead>
+              <TableBody>
+                {permissions.map((p) => (
+                  <TableRow key={p}>
+                    <TableCell sx={{ fontFamily: 'monospace' }}>{p}</TableCell>
+                    {roles.map((r) => (
+                      <TableCell key={r.id} align="center">
+                        <Checkbox
+                          size="small"
+                          checked={r.permissions.includes(p)}
+                          disabled={r.system}
+                          onChange={() => handleToggle(r, p)}
+                        />
+                      </TableCell>
+                    ))}
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          </TableContainer>
+        </Paper>
+      </Container>
+    </Box>
+  );
+};
+
+export default Roles;
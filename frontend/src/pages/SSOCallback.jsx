@@ -0,0 +1,43 @@
+import { useEffect, useContext, useRef, useState } from 'react';
+import { Box, CircularProgress, Alert, Link as MuiLink } from '@mui/material';
+import { useNavigate, useSearchParams, Link as RouterLink } from 'react-router-dom';
+import { AuthContext } from '../context/AuthContext';
+
+// Página de retorno do provedor institucional: envia code e state ao
+// backend e segue para a área da coordenação ou do aluno.
+const SSOCallback = () => {
+  const [params] = useSearchParams();
+  const [error, setError] = useState('');
+  const { loginWithSSO } = useContext(AuthContext);
+  const navigate = useNavigate();
+  // O state só vale uma vez; evita reenviar no modo estrito do React.
+  const sent = useRef(false);
+
+  useEffect(() => {
+    if (sent.current) return;
+    sent.current = true;
+
+    if (params.get('error')) {
+      setError(params.get('error_description') || 'Login institucional cancelado.');
+      return;
+    }
+    loginWithSSO(params.get('code'), params.get('state'))
+      .then((user) => navigate(user.role === 'student' ? '/aluno' : '/home', { replace: true }))
+      .catch((err) => setError(err.response?.data?.error || 'Não foi possível concluir o login institucional.'));
+  }, [params, loginWithSSO, navigate]);
+
+  return (
+    <Box sx={{ height: '100vh', display: 'flex', alignItems: 'center', justifyContent: 'center', flexDirection: 'column' }}>
+      {error ? (
+        <>
+          <Alert severity="error" sx={{ mb: 2 }}>{error}</Alert>
+          <MuiLink component={RouterLink} to="/">Voltar ao login</MuiLink>
+        </>
+      ) : (
+        <CircularProgress />
+      )}
+    </Box>
+  );
+};
+
+export default SSOCallback;
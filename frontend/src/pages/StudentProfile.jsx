@@ -32,7 +32,7 @@ const StudentProfile = () => {
   if (loading) return <LinearProgress />;
   if (!data) return <Typography sx={{p:4}}>Aluno não encontrado.</Typography>;
 
-  const { student, history } = data;
+  const { student, history, projection } = data;
 
   const chartData = history.map(rec => ({
     name: rec.semester.code,
@@ -80,6 +80,37 @@ const StudentProfile = () => {
             </Grid>
         </Paper>
 
+        {projection && (
+            <Paper sx={{ p: 3, mb: 4 }}>
+                <Typography variant="h6" gutterBottom color="primary">
+                    Projeção de Formatura
+                </Typography>
+                <Grid container spacing={2} alignItems="center">
+                    <Grid item xs={12} md={3}>
+                        <Typography variant="caption" color="textSecondary">Semestre previsto</Typography>
+                        <Typography variant="h5" fontWeight="bold">
+                            {projection.expected_semester || 'Sem previsão'}
+                        </Typography>
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                        <Typography variant="caption" color="textSecondary">Ritmo (h/semestre)</Typography>
+                        <Typography variant="h5">{projection.pace}</Typography>
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                        <Typography variant="caption" color="textSecondary">CH restante</Typography>
+                        <Typography variant="h5">{projection.remaining_hours} h</Typography>
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                        <Typography variant="caption" color="textSecondary">Prazo máximo</Typography>
+                        <Typography variant="h5">{projection.deadline_semester || 'Não configurado'}</Typography>
+                        {projection.will_exceed && (
+                            <Chip label="Excederá o prazo máximo" color="error" size="small" sx={{ mt: 1 }} />
+                        )}
+                    </Grid>
+                </Grid>
+            </Paper>
+        )}
+
         <Grid container spacing={3}>
             <Grid item xs={12} md={8}>
                 <Paper sx={{ p: 3, height: 400 }}>
@@ -1,10 +1,11 @@
 import React, { useEffect, useState, useContext, useRef } from 'react';
 import {
   Box, Container, Typography, Paper, Table, TableBody, TableCell,
-  TableContainer, TableHead, TableRow, IconButton, Chip, Switch, Tooltip,
+  TableContainer, TableHead, TableRow, IconButton, Tooltip,
   Grid, TextField, MenuItem, Button
 } from '@mui/material';
-import DeleteIcon from '@mui/icons-material/Delete';
+import BlockIcon from '@mui/icons-material/Block';
+import RestoreIcon from '@mui/icons-material/Restore';
 import SearchIcon from '@mui/icons-material/Search';
 import ClearIcon from '@mui/icons-material/Clear';
 import Header from '../components/Header';
@@ -20,6 +21,11 @@ const UsersList = () => {
   const emailRef = useRef(null);
 
   const [role, setRole] = useState('');
+  const [accountStatus, setAccountStatus] = useState('');
+  // Cursos disponíveis para vincular aos usuários e papéis configurados
+  // (papéis com courses.all enxergam todos os cursos).
+  const [courses, setCourses] = useState([]);
+  const [roles, setRoles] = useState([]);
 
   const fetchUsers = async () => {
     try {
@@ -29,6 +35,7 @@ const UsersList = () => {
       if (emailRef.current?.value) params.append('email', emailRef.current.value);
 
       if (role) params.append('role', role);
+      if (accountStatus) params.append('status', accountStatus);
 
       const response = await api.get(`/users?${params.toString()}`);
       setUsers(response.data);
@@ -39,6 +46,12 @@ const UsersList = () => {
 
   useEffect(() => {
     fetchUsers();
+    api.get('/reports/courses')
+      .then((res) => setCourses(res.data))
+      .catch(() => {});
+    api.get('/roles')
+      .then((res) => setRoles(res.data))
+      .catch(() => {});
   }, []);
 
   const handleClear = () => {
@@ -46,21 +59,24 @@ const UsersList = () => {
     if (emailRef.current) emailRef.current.value = '';
 
     setRole('');
+    setAccountStatus('');
   };
 
-  const handleDelete = async (id) => {
-    if (!window.confirm("Tem certeza que deseja remover este usuário?")) return;
+  // Usuários não são apagados: a desativação bloqueia o acesso e mantém o
+  // nome no histórico de ações e na auditoria.
+  const handleActive = async (targetUser) => {
+    const deactivate = !targetUser.deactivated_at;
+    if (deactivate && !window.confirm("Desativar este usuário? As sessões abertas serão encerradas.")) return;
     try {
-      await api.delete(`/users/${id}`);
-      toast.success("Usuário removido.");
+      await api.put(`/users/${targetUser.ID}/${deactivate ? 'deactivate' : 'reactivate'}`);
+      toast.success(deactivate ? "Usuário desativado." : "Usuário reativado.");
       fetchUsers();
     } catch (error) {
-      toast.error(error.response?.data?.error || "Erro ao deletar.");
+      toast.error(error.response?.data?.error || "Erro ao alterar situação do usuário.");
     }
   };
 
-  const handleToggleAdmin = async (targetUser) => {
-    const newRole = targetUser.role === 'admin' ? 'user' : 'admin';
+  const handleRole = async (targetUser, newRole) => {
     try {
       await api.put(`/users/${targetUser.ID}`, { role: newRole });
       toast.success(`Permissão alterada com sucesso.`);
@@ -70,6 +86,18 @@ const UsersList = () => {
     }
   };
 
+  const handleCourses = async (targetUser, courseIds) => {
+    try {
+      await api.put(`/users/${targetUser.ID}/courses`, { course_ids: courseIds });
+      toast.success('Cursos atualizados.');
+      fetchUsers();
+    } catch (error) {
+      toast.error(error.response?.data?.error || 'Erro ao atualizar cursos.');
+    }
+  };
+
+  const allCourses = (roleName) => roles.find((r) => r.name === roleName)?.permissions.includes('courses.all');
+
   const isOwnProfile = (targetUserId) => {
     if (!currentUser) return false;
     const currentId = currentUser.id || currentUser.ID;
@@ -96,7 +124,7 @@ const UsersList = () => {
             <Grid item xs={12} sm={4}>
                 <TextField fullWidth label="Nome" inputRef={nameRef} size="small" />
             </Grid>
-            <Grid item xs={12} sm={4}>
+            <Grid item xs={12} sm={3}>
                 <TextField fullWidth label="Email" inputRef={emailRef} size="small" />
             </Grid>
             <Grid item xs={12} sm={2}>
@@ -109,11 +137,26 @@ const UsersList = () => {
                     size="small"
                 >
                     <MenuItem value="">Todas</MenuItem>
-                    <MenuItem value="admin">Administrador</MenuItem>
-                    <MenuItem value="user">Usuário</MenuItem>
+                    {roles.map((r) => (
+                      <MenuItem key={r.id} value={r.name}>{r.description || r.name}</MenuItem>
+                    ))}
+                </TextField>
+            </Grid>
+            <Grid item xs={12} sm={1.5}>
+                <TextField
+                    select
+                    fullWidth
+                    label="Situação"
+                    value={accountStatus}
+                    onChange={(e) => setAccountStatus(e.target.value)}
+                    size="small"
+                >
+                    <MenuItem value="">Todas</MenuItem>
+                    <MenuItem value="active">Ativos</MenuItem>
+                    <MenuItem value="inactive">Inativos</MenuItem>
                 </TextField>
             </Grid>
-            <Grid item xs={12} sm={2} sx={{ display: 'flex', gap: 1 }}>
+            <Grid item xs={12} sm={1.5} sx={{ display: 'flex', gap: 1 }}>
                 <Button variant="contained" onClick={fetchUsers}><SearchIcon/></Button>
                 <Button variant="outlined" onClick={handleClear}><ClearIcon/></Button>
             </Grid>
@@ -129,6 +172,7 @@ const UsersList = () => {
                   <TableCell>Nome</TableCell>
                   <TableCell>Email</TableCell>
                   <TableCell>Permissão</TableCell>
+                  <TableCell>Cursos</TableCell>
                   <TableCell align="right">Ações</TableCell>
                 </TableRow>
               </TableHead>
@@ -138,29 +182,66 @@ const UsersList = () => {
                   return (
                   <TableRow key={u.ID}>
                     <TableCell>{u.ID}</TableCell>
-                    <TableCell>{u.name}</TableCell>
-                    <TableCell>{u.email}</TableCell>
                     <TableCell>
-                      <Chip
-                        label={u.role === 'admin' ? "Administrador" : "Usuário"}
-                        color={u.role === 'admin' ? "primary" : "default"}
-                        size="small"
-                      />
+                      {u.name}
+                      {u.invitation_pending && (
+                        <Typography variant="caption" color="warning.main" display="block">Convite pendente</Typography>
+                      )}
+                      {u.deactivated_at && (
+                        <Typography variant="caption" color="error" display="block">Inativo</Typography>
+                      )}
                     </TableCell>
-                    <TableCell align="right">
-                      <Tooltip title={status.disabled ? status.text : "Alterar cargo"}>
+                    <TableCell>{u.email}</TableCell>
+                    <TableCell>
+                      <Tooltip title={status.disabled ? status.text : "Alterar papel"}>
                         <span>
-                          <Switch
-                            checked={u.role === 'admin'}
-                            onChange={() => handleToggleAdmin(u)}
+                          <TextField
+                            select
+                            size="small"
+                            value={u.role}
+                            onChange={(e) => handleRole(u, e.target.value)}
                             disabled={status.disabled}
-                          />
+                          >
+                            {roles.map((r) => (
+                              <MenuItem key={r.id} value={r.name}>{r.name}</MenuItem>
+                            ))}
+                          </TextField>
                         </span>
                       </Tooltip>
-                      <Tooltip title={status.disabled ? status.text : "Excluir usuário"}>
+                    </TableCell>
+                    <TableCell sx={{ minWidth: 220 }}>
+                      {allCourses(u.role) ? (
+                        <Typography variant="body2" color="text.secondary">Todos</Typography>
+                      ) : (
+                        <TextField
+                          select
+                          size="small"
+                          fullWidth
+                          value={(u.courses || []).map((c) => c.ID)}
+                          onChange={(e) => handleCourses(u, e.target.value)}
+                          SelectProps={{
+                            multiple: true,
+                            displayEmpty: true,
+                            renderValue: (ids) => (ids.length
+                              ? courses.filter((c) => ids.includes(c.ID)).map((c) => c.name).join(', ')
+                              : 'Nenhum'),
+                          }}
+                        >
+                          {courses.map((c) => (
+                            <MenuItem key={c.ID} value={c.ID}>{c.name}</MenuItem>
+                          ))}
+                        </TextField>
+                      )}
+                    </TableCell>
+                    <TableCell align="right">
+                      <Tooltip title={status.disabled ? status.text : (u.deactivated_at ? "Reativar usuário" : "Desativar usuário")}>
                         <span>
-                          <IconButton color="error" onClick={() => handleDelete(u.ID)} disabled={status.disabled}>
-                            <DeleteIcon />
+                          <IconButton
+                            color={u.deactivated_at ? "success" : "error"}
+                            onClick={() => handleActive(u)}
+                            disabled={status.disabled}
+                          >
+                            {u.deactivated_at ? <RestoreIcon /> : <BlockIcon />}
                           </IconButton>
                         </span>
                       </Tooltip>
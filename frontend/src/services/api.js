@@ -1,5 +1,5 @@
 import axios from 'axios';
-const backendUrl = import.meta.env.VITE_API_URL || 'http://localhost:8080';
+export const backendUrl = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
 const api = axios.create({
   baseURL: `${backendUrl}/api/v1`,
@@ -12,4 +12,54 @@ api.interceptors.request.use((config) => {
   return config;
 });
 
-export default api;
\ No newline at end of file
+// O token de acesso dura poucos minutos: em um 401, renova a sessão com o
+// refresh token uma única vez e repete a requisição. Renovações simultâneas
+// compartilham a mesma promessa, pois o refresh token gira a cada uso.
+let refreshing = null;
+
+const refreshSession = () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) {
+    return Promise.reject(new Error('sem refresh token'));
+  }
+  return axios
+    .post(`${backendUrl}/api/v1/refresh`, { refresh_token: refreshToken })
+    .then((res) => {
+      localStorage.setItem('token', res.data.token);
+      localStorage.setItem('refresh_token', res.data.refresh_token);
+      return res.data.token;
+    })
+    .catch((err) => {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+      localStorage.removeItem('user');
+      throw err;
+    });
+};
+
+// renewSession renova a sessão compartilhando a renovação em andamento.
+export const renewSession = () => {
+  refreshing = refreshing || refreshSession().finally(() => { refreshing = null; });
+  return refreshing;
+};
+
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config;
+    if (error.response?.status !== 401 || !original || original._retried) {
+      return Promise.reject(error);
+    }
+    original._retried = true;
+
+    try {
+      const token = await renewSession();
+      original.headers.Authorization = `Bearer ${token}`;
+      return api(original);
+    } catch {
+      return Promise.reject(error);
+    }
+  },
+);
+
+export default api;
@@ -0,0 +1,88 @@
+import { backendUrl, renewSession } from './api';
+
+// Leitura autenticada de Server-Sent Events. O EventSource do navegador
+// não envia o cabeçalho Authorization, então o stream é lido via fetch.
+// A conexão é refeita quando cai (com espera crescente, retomando pelo
+// Last-Event-ID) e quando o backend avisa com "expired" que o token venceu
+// ou a sessão terminou: renova a sessão e reconecta. Se a renovação
+// falhar (logout, sessão revogada), o stream para.
+
+const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+const parseEvent = (block) => {
+  const evt = { event: 'message', id: '', data: '' };
+  block.split('\n').forEach((line) => {
+    if (!line || line.startsWith(':')) return;
+    const idx = line.indexOf(':');
+    const field = idx < 0 ? line : line.slice(0, idx);
+    const value = idx < 0 ? '' : line.slice(idx + 1).replace(/^ /, '');
+    if (field === 'event') evt.event = value;
+    else if (field === 'id') evt.id = value;
+    else if (field === 'data') evt.data += value;
+  });
+  if (!evt.data) return null;
+  try {
+    evt.data = JSON.parse(evt.data);
+  } catch {
+    return null;
+  }
+  return evt;
+};
+
+// subscribe abre o stream em path (relativo a /api/v1) e chama
+// onEvent(evento, dados) a cada evento. Devolve a função que o encerra.
+export const subscribe = (path, onEvent) => {
+  const controller = new AbortController();
+  let stopped = false;
+  let lastEventId = '';
+
+  // connect lê o stream até ele terminar; devolve true se terminou por
+  // "expired".
+  const connect = async (retried) => {
+    const headers = { Authorization: `Bearer ${localStorage.getItem('token')}` };
+    if (lastEventId) headers['Last-Event-ID'] = lastEventId;
+    const res = await fetch(`${backendUrl}/api/v1${path}`, { headers, signal: controller.signal });
+    if (res.status === 401 && !retried) {
+      await renewSession();
+      return connect(true);
+    }
+    if (!res.ok || !res.body) throw new Error(`stream: HTTP ${res.status}`);
+
+    const reader = res.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = '';
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) return false;
+      buffer += value.replace(/\r\n/g, '\n');
+      let sep;
+      while ((sep = buffer.indexOf('\n\n')) >= 0) {
+        const evt = parseEvent(buffer.slice(0, sep));
+        buffer = buffer.slice(sep + 2);
+        if (!evt) continue;
+        if (evt.id) lastEventId = evt.id;
+        if (evt.event === 'expired') return true;
+        onEvent(evt.event, evt.data);
+      }
+    }
+  };
+
+  const run = async () => {
+    let delay = 1000;
+    while (!stopped && localStorage.getItem('token')) {
+      try {
+        if (await connect(false)) await renewSession();
+        delay = 1000;
+      } catch {
+        if (stopped || !localStorage.getItem('token')) return;
+        await sleep(delay);
+        delay = Math.min(delay * 2, 30000);
+      }
+    }
+  };
+  run();
+
+  return () => {
+    stopped = true;
+    controller.abort();
+  };
+};